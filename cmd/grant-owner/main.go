@@ -0,0 +1,42 @@
+// Command grant-owner turns an existing account into an owner account, which
+// signup cannot create, and gives it a workspace to add its channels to:
+//
+//	grant-owner -email someone@example.com
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+	"github.com/abhishek-sengar/ytmanager/internal/db"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/postgres"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+)
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "optional YAML config file")
+	email := flag.String("email", "", "email of the account to make an owner")
+	flag.Parse()
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configFile, ".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := db.Connect(cfg.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	user, err := service.GrantOwner(context.Background(), postgres.NewStore(db.DB), *email)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s (%s) is now an owner; they need to log in again", user.Name, user.Email)
+}
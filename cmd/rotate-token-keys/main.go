@@ -0,0 +1,48 @@
+// Command rotate-token-keys re-encrypts the YouTube OAuth tokens of every
+// connected account under TOKEN_KEY_ID, and encrypts any still stored in
+// plaintext. To rotate keys, add the new key to TOKEN_KEYS and point
+// TOKEN_KEY_ID at it on every server, run this command, then drop the old
+// key from TOKEN_KEYS.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+	"github.com/abhishek-sengar/ytmanager/internal/db"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/postgres"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+)
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "optional YAML config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile, ".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	keys, err := service.NewTokenKeyring(cfg.TokenKeys, cfg.TokenKeyID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Connect(cfg.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rotated, err := service.RotateTokenKeys(ctx, postgres.NewStore(db.DB), keys)
+	log.Printf("Moved %d YouTube accounts to token key %q", rotated, keys.CurrentKeyID())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,86 +1,116 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/abhishek-sengar/ytmanager/internal/api"
+	"github.com/abhishek-sengar/ytmanager/internal/config"
 	"github.com/abhishek-sengar/ytmanager/internal/db"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/postgres"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
+// shutdownTimeout bounds how long in-flight requests and jobs get to finish
+const shutdownTimeout = 30 * time.Second
+
 func main() {
-	// Load environment variables
-	err := godotenv.Load()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "optional YAML config file")
+	flag.Parse()
+
+	// Load settings from the environment, .env and the config file
+	cfg, err := config.Load(*configFile, ".env")
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal(err)
 	}
 
 	// Connect to database
-	err = db.Connect()
+	err = db.Connect(cfg.Database)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer db.Close()
 
+	// Connect to video storage (local, s3 or gcs)
+	store, err := service.NewStorage(context.Background(), cfg.Storage)
+	if err != nil {
+		log.Fatal("Failed to initialise storage:", err)
+	}
+
+	// Tokens stored before they were encrypted must not stay in plaintext;
+	// fail closed rather than serve with them
+	keys, err := service.NewTokenKeyring(cfg.TokenKeys, cfg.TokenKeyID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	repos := postgres.NewStore(db.DB)
+	sealed, err := service.SealPlaintextTokens(context.Background(), repos, keys)
+	if err != nil {
+		log.Fatal("Failed to encrypt stored YouTube tokens:", err)
+	}
+	if sealed > 0 {
+		log.Printf("Encrypted the tokens of %d YouTube accounts", sealed)
+	}
+
+	// Services and handlers on top of Postgres
+	h, err := api.NewHandler(cfg, repos, store)
+	if err != nil {
+		log.Fatal("Failed to initialise services:", err)
+	}
+
+	// Background jobs run until the process is asked to stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		h.RunWorkers(ctx)
+	}()
+
 	// Setup Gin router
 	router := gin.Default()
 
-	// Allow requests from your React frontend
+	// Allow requests from the React frontend
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"},
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Workspace-ID"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
-	// Root endpoint for health check
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "YouTube Manager API is running",
-		})
-	})
-
-	// Ping endpoint for testing
-	router.GET("/ping", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "pong"})
-	})
-
-	// Auth routes (login/signup)
-	router.POST("/signup", api.Signup)
-	router.POST("/login", api.Login)
-
-	// Public YouTube auth route (needed for OAuth flow)
-	router.GET("/api/youtube/auth", api.YoutubeAuth)
-	router.GET("/api/youtube/callback", api.YoutubeCallback)
-
-	// Protected routes
-	protected := router.Group("/")
-	protected.Use(api.AuthMiddleware())
-
-	// Project related routes
-	protected.POST("/projects", api.CreateProject)
-	protected.GET("/projects", api.GetUserProjects) // Generalized endpoint for owner/editor
-	protected.GET("/projects/:id", api.GetProjectDetailsByID)
-	protected.POST("/projects/:id/notes", api.AddNote)
-	protected.POST("/projects/:id/approve", api.ApproveProject)
-	protected.POST("/projects/:id/reject", api.RejectProject)
-	protected.GET("/projects/recent", api.GetRecentProjects)
-
-	// Sidebar data for both owners and editors
-	protected.GET("/sidebar-data", api.GetSidebarData)
-
-	// Protected YouTube integration routes
-	protected.GET("/api/youtube/unattached-channels", api.GetUnattachedChannels)
-	protected.POST("/api/youtube/add-channels", api.AddChannelsToDashboard)
-
-	protected.POST("/api/video/upload", api.UploadVideoToGCS)
+	h.Routes(router)
 
 	// Start the server
-	port := os.Getenv("PORT")
-	router.Run(":" + port)
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed:", err)
+		}
+	}()
+
+	// On SIGINT/SIGTERM stop taking requests, let the ones in flight and
+	// the background jobs finish, then exit
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		log.Println("Background jobs did not stop in time")
+	}
 }
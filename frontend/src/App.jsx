@@ -10,6 +10,9 @@ import ChannelSelection from "./pages/ChannelSelection";
 import CreateProject from "./pages/CreateProject";
 import ProfileSettings from "./pages/ProfileSettings";
 import Home from "./pages/Home";
+import VerifyEmail from "./pages/VerifyEmail";
+import ForgotPassword from "./pages/ForgotPassword";
+import ResetPassword from "./pages/ResetPassword";
 import { Toaster } from "react-hot-toast";
 
 function PrivateRoute({ children }) {
@@ -27,6 +30,9 @@ function App() {
           <Route path="/" element={<Home />} />
           <Route path="/login" element={<Login />} />
           <Route path="/signup" element={<Signup />} />
+          <Route path="/verify-email" element={<VerifyEmail />} />
+          <Route path="/forgot-password" element={<ForgotPassword />} />
+          <Route path="/reset-password" element={<ResetPassword />} />
 
           {/* Protected routes */}
           <Route
@@ -44,13 +44,18 @@ export default function Sidebar({
     setAddChannelDialogOpen(true);
   };
 
-  const handleAddYouTubeAccount = () => {
+  const handleAddYouTubeAccount = async () => {
     const token = localStorage.getItem("token");
     if (!token) {
       window.location.href = "/login";
       return;
     }
-    window.location.href = `http://localhost:8080/api/youtube/auth?state=${encodeURIComponent(token)}`;
+    try {
+      const res = await api.post("/api/youtube/auth");
+      window.location.href = res.data.auth_url;
+    } catch (err) {
+      toast.error(err.response?.data?.error || "Failed to start YouTube authorization");
+    }
   };
 
   const AddChannelDialog = () => {
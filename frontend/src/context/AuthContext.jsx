@@ -1,6 +1,7 @@
 // src/context/AuthContext.jsx
 import { createContext, useContext, useState, useEffect } from "react";
 import { jwtDecode } from "jwt-decode";
+import api from "../services/api";
 
 const AuthContext = createContext();
 
@@ -23,8 +24,11 @@ export const AuthProvider = ({ children }) => {
     }
   }, []);
 
-  const login = (newToken) => {
+  const login = (newToken, refreshToken) => {
     localStorage.setItem("token", newToken);
+    if (refreshToken) {
+      localStorage.setItem("refreshToken", refreshToken);
+    }
     setToken(newToken);
     try {
       const decoded = jwtDecode(newToken);
@@ -36,7 +40,14 @@ export const AuthProvider = ({ children }) => {
   };
 
   const logout = () => {
+    const refreshToken = localStorage.getItem("refreshToken");
+    if (refreshToken) {
+      // End the session on the server too; ignore failures
+      api.post("/logout", { refresh_token: refreshToken }).catch(() => {});
+    }
     localStorage.removeItem("token");
+    localStorage.removeItem("refreshToken");
+    localStorage.removeItem("workspace_id");
     setToken(null);
     setRole(null);
     setUserName("");
@@ -71,9 +71,13 @@ export default function Dashboard() {
   };
 
   // Add this handler for OAuth
-  const handleAddYouTubeAccount = () => {
-    const token = localStorage.getItem("token");
-    window.location.href = `http://localhost:8080/api/youtube/auth?state=${encodeURIComponent(token)}`;
+  const handleAddYouTubeAccount = async () => {
+    try {
+      const res = await api.post("/api/youtube/auth");
+      window.location.href = res.data.auth_url;
+    } catch (err) {
+      alert(err.response?.data?.error || "Failed to start YouTube authorization");
+    }
   };
 
   // Group videos for display
@@ -0,0 +1,72 @@
+// src/pages/ForgotPassword.jsx
+import { useState } from "react";
+import {
+  Container,
+  Box,
+  Typography,
+  TextField,
+  Button,
+  Link,
+  Paper,
+} from "@mui/material";
+import api from "../services/api";
+
+export default function ForgotPassword() {
+  const [email, setEmail] = useState("");
+  const [message, setMessage] = useState({ type: "", text: "" });
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    try {
+      const res = await api.post("/password/forgot", { email });
+      setMessage({ type: "success", text: res.data.message });
+    } catch (err) {
+      setMessage({
+        type: "error",
+        text: err.response?.data?.error || "Failed to send reset email",
+      });
+    }
+  };
+
+  return (
+    <Container maxWidth="sm">
+      <Paper elevation={3} sx={{ p: 4, mt: 8 }}>
+        <Typography variant="h4" align="center" gutterBottom>
+          Forgot password
+        </Typography>
+
+        <Box component="form" onSubmit={handleSubmit} noValidate>
+          <TextField
+            fullWidth
+            margin="normal"
+            required
+            label="Email"
+            type="email"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+          />
+
+          {message.text && (
+            <Typography
+              color={message.type === "error" ? "error" : "success.main"}
+              variant="body2"
+              mt={1}
+            >
+              {message.text}
+            </Typography>
+          )}
+
+          <Button type="submit" fullWidth variant="contained" sx={{ mt: 3 }}>
+            Send reset link
+          </Button>
+
+          <Typography align="center" variant="body2" mt={2}>
+            <Link href="/login" underline="hover">
+              Back to login
+            </Link>
+          </Typography>
+        </Box>
+      </Paper>
+    </Container>
+  );
+}
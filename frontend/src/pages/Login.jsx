@@ -13,14 +13,16 @@ import {
 } from "@mui/material";
 import GoogleIcon from "@mui/icons-material/Google";
 import FacebookIcon from "@mui/icons-material/Facebook";
-import { useNavigate } from "react-router-dom";
+import { useLocation, useNavigate } from "react-router-dom";
 import { useAuth } from "../context/AuthContext";
 import api from "../services/api";
 
 export default function Login() {
   const [form, setForm] = useState({ email: "", password: "" });
   const [error, setError] = useState("");
+  const [notice, setNotice] = useState("");
   const navigate = useNavigate();
+  const location = useLocation();
   const { login } = useAuth();
 
   const handleChange = (e) =>
@@ -31,13 +33,23 @@ export default function Login() {
     setError("");
     try {
       const res = await api.post("/login", form);
-      login(res.data.token);
+      login(res.data.token, res.data.refresh_token);
       navigate("/dashboard");
     } catch (err) {
       setError(err.response?.data?.error || "Login failed");
     }
   };
 
+  const resendVerification = async () => {
+    setError("");
+    try {
+      const res = await api.post("/verify-email/resend", { email: form.email });
+      setNotice(res.data.message);
+    } catch (err) {
+      setError(err.response?.data?.error || "Failed to send verification email");
+    }
+  };
+
   return (
     <Container maxWidth="sm">
       <Paper elevation={3} sx={{ p: 4, mt: 8 }}>
@@ -67,12 +79,33 @@ export default function Login() {
             onChange={handleChange}
           />
 
+          {location.state?.verify && !notice && (
+            <Typography variant="body2" mt={1}>
+              Check your email for a link to verify your address.
+            </Typography>
+          )}
+          {notice && (
+            <Typography color="success.main" variant="body2" mt={1}>
+              {notice}
+            </Typography>
+          )}
           {error && (
             <Typography color="error" variant="body2" mt={1}>
-              {error}
+              {error}{" "}
+              {error.includes("verify your email") && (
+                <Link component="button" type="button" onClick={resendVerification}>
+                  Resend link
+                </Link>
+              )}
             </Typography>
           )}
 
+          <Typography align="right" variant="body2" mt={1}>
+            <Link href="/forgot-password" underline="hover">
+              Forgot password?
+            </Link>
+          </Typography>
+
           <Button type="submit" fullWidth variant="contained" sx={{ mt: 3 }}>
             Login
           </Button>
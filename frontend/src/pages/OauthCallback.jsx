@@ -1,4 +1,4 @@
-import { useEffect, useState } from 'react';
+import { useEffect, useRef, useState } from 'react';
 import { useNavigate, useSearchParams } from 'react-router-dom';
 import {
   Dialog,
@@ -17,7 +17,7 @@ import {
   Typography,
 } from '@mui/material';
 import { toast } from 'sonner';
-import axios from 'axios';
+import api from '../services/api';
 
 export default function OauthCallback() {
   const [searchParams] = useSearchParams();
@@ -26,33 +26,33 @@ export default function OauthCallback() {
   const [selectedChannels, setSelectedChannels] = useState([]);
   const [isLoading, setIsLoading] = useState(false);
   const [open, setOpen] = useState(true);
-  const [youtubeAccountId, setYoutubeAccountId] = useState('');
+  const fetched = useRef(false);
 
   useEffect(() => {
     const status = searchParams.get('status');
-    const channelsParam = searchParams.get('channels');
-    const email = searchParams.get('email');
-    const userId = searchParams.get('user_id');
+    const connectionId = searchParams.get('connection_id');
 
-    if (status !== 'success' || !channelsParam || !email || !userId) {
+    if (status !== 'success' || !connectionId) {
       toast.error('Invalid callback parameters');
       navigate('/');
       return;
     }
 
-    try {
-      const decodedChannels = JSON.parse(decodeURIComponent(channelsParam));
-      setChannels(decodedChannels.map(ch => ({
-        ...ch,
-        email: email,
-        youtube_account_id: userId
-      })));
-      setSelectedChannels(decodedChannels.map(ch => ch.id));
-    } catch (error) {
-      console.error('Error parsing channels:', error);
-      toast.error('Failed to parse channels data');
-      navigate('/');
-    }
+    // A connection can only be fetched once; StrictMode runs effects twice
+    if (fetched.current) return;
+    fetched.current = true;
+
+    api.get(`/api/youtube/connections/${encodeURIComponent(connectionId)}`)
+      .then((res) => {
+        const found = res.data.channels || [];
+        setChannels(found);
+        setSelectedChannels(found.map(ch => ch.id));
+      })
+      .catch((error) => {
+        console.error('Error fetching channels:', error);
+        toast.error(error.response?.data?.error || 'Failed to load channels');
+        navigate('/');
+      });
   }, [searchParams, navigate]);
 
   const handleChannelToggle = (channelId) => {
@@ -74,12 +74,8 @@ export default function OauthCallback() {
       const selectedChannelData = channels
         .filter(ch => selectedChannels.includes(ch.id));
 
-      await axios.post('http://localhost:8080/api/youtube/add-channels', {
+      await api.post('/api/youtube/add-channels', {
         channels: selectedChannelData
-      }, {
-        headers: {
-          'Authorization': `Bearer ${localStorage.getItem('token')}`
-        }
       });
       toast.success('Channels added successfully');
       setOpen(false);
@@ -65,6 +65,43 @@ export default function ProjectDetail() {
         </div>
       )}
 
+      {project.metadata && (
+        <div className="border rounded p-3 mb-4 text-sm text-gray-700">
+          <p className="text-sm text-gray-600 mb-2 font-medium">YouTube details</p>
+          {project.metadata.thumbnail_url && (
+            <img
+              src={project.metadata.thumbnail_url}
+              alt="Thumbnail"
+              className="w-48 rounded mb-2"
+            />
+          )}
+          <p>
+            <strong>Tags:</strong>{" "}
+            {project.metadata.tags.length ? project.metadata.tags.join(", ") : "None"}
+          </p>
+          <p>
+            <strong>Category:</strong> {project.metadata.category_id}
+          </p>
+          {project.metadata.default_language && (
+            <p>
+              <strong>Language:</strong> {project.metadata.default_language}
+            </p>
+          )}
+          <p>
+            <strong>Made for kids:</strong> {project.metadata.made_for_kids ? "Yes" : "No"}
+          </p>
+          <p>
+            <strong>License:</strong>{" "}
+            {project.metadata.license === "creativeCommon" ? "Creative Commons" : "Standard YouTube"}
+          </p>
+          {project.metadata.playlist_ids.length > 0 && (
+            <p>
+              <strong>Playlists:</strong> {project.metadata.playlist_ids.length}
+            </p>
+          )}
+        </div>
+      )}
+
       {isOwner && project.status !== "" && (
         <div className="flex gap-4 mt-4">
           <button
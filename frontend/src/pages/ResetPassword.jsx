@@ -0,0 +1,83 @@
+// src/pages/ResetPassword.jsx
+import { useState } from "react";
+import {
+  Container,
+  Box,
+  Typography,
+  TextField,
+  Button,
+  Paper,
+} from "@mui/material";
+import { useNavigate, useSearchParams } from "react-router-dom";
+import api from "../services/api";
+
+export default function ResetPassword() {
+  const [params] = useSearchParams();
+  const [form, setForm] = useState({ password: "", confirmPassword: "" });
+  const [error, setError] = useState("");
+  const navigate = useNavigate();
+
+  const handleChange = (e) =>
+    setForm({ ...form, [e.target.name]: e.target.value });
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError("");
+    if (form.password !== form.confirmPassword) {
+      setError("Passwords do not match");
+      return;
+    }
+    try {
+      await api.post("/password/reset", {
+        token: params.get("token") || "",
+        password: form.password,
+      });
+      navigate("/login");
+    } catch (err) {
+      setError(err.response?.data?.error || "Failed to reset password");
+    }
+  };
+
+  return (
+    <Container maxWidth="sm">
+      <Paper elevation={3} sx={{ p: 4, mt: 8 }}>
+        <Typography variant="h4" align="center" gutterBottom>
+          Choose a new password
+        </Typography>
+
+        <Box component="form" onSubmit={handleSubmit} noValidate>
+          <TextField
+            fullWidth
+            margin="normal"
+            required
+            label="New password"
+            name="password"
+            type="password"
+            value={form.password}
+            onChange={handleChange}
+          />
+          <TextField
+            fullWidth
+            margin="normal"
+            required
+            label="Confirm password"
+            name="confirmPassword"
+            type="password"
+            value={form.confirmPassword}
+            onChange={handleChange}
+          />
+
+          {error && (
+            <Typography color="error" variant="body2" mt={1}>
+              {error}
+            </Typography>
+          )}
+
+          <Button type="submit" fullWidth variant="contained" sx={{ mt: 3 }}>
+            Reset password
+          </Button>
+        </Box>
+      </Paper>
+    </Container>
+  );
+}
@@ -8,8 +8,6 @@ import {
   Button,
   Link,
   Paper,
-  ToggleButtonGroup,
-  ToggleButton,
   Divider,
   Stack,
 } from "@mui/material";
@@ -23,7 +21,6 @@ export default function Signup() {
     name: "",
     email: "",
     password: "",
-    role: "editor",
   });
   const [error, setError] = useState("");
   const navigate = useNavigate();
@@ -32,18 +29,12 @@ export default function Signup() {
     setForm({ ...form, [e.target.name]: e.target.value });
   };
 
-  const handleRoleChange = (_, newRole) => {
-    if (newRole !== null) {
-      setForm({ ...form, role: newRole });
-    }
-  };
-
   const handleSubmit = async (e) => {
     e.preventDefault();
     setError("");
     try {
       await api.post("/signup", form);
-      navigate("/login");
+      navigate("/login", { state: { verify: true } });
     } catch (err) {
       setError(err.response?.data?.error || "Signup failed");
     }
@@ -87,20 +78,9 @@ export default function Signup() {
             onChange={handleChange}
           />
 
-          <Typography variant="subtitle1" mt={2}>
-            I am signing up as:
+          <Typography variant="body2" color="text.secondary" mt={2}>
+            New accounts join as editors. Ask us to upgrade you to an owner account to manage your own channels.
           </Typography>
-          <ToggleButtonGroup
-            color="primary"
-            exclusive
-            fullWidth
-            value={form.role}
-            onChange={handleRoleChange}
-            sx={{ mt: 1 }}
-          >
-            <ToggleButton value="editor">Editor</ToggleButton>
-            <ToggleButton value="owner">Owner</ToggleButton>
-          </ToggleButtonGroup>
 
           {error && (
             <Typography color="error" variant="body2" mt={2}>
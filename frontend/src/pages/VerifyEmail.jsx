@@ -0,0 +1,47 @@
+// src/pages/VerifyEmail.jsx
+import { useEffect, useRef, useState } from "react";
+import { Container, Paper, Typography, Link } from "@mui/material";
+import { useSearchParams } from "react-router-dom";
+import api from "../services/api";
+
+export default function VerifyEmail() {
+  const [params] = useSearchParams();
+  const [status, setStatus] = useState({ type: "", text: "Verifying…" });
+  const sent = useRef(false);
+
+  useEffect(() => {
+    // Links work once; StrictMode would otherwise redeem it twice
+    if (sent.current) return;
+    sent.current = true;
+    api
+      .post("/verify-email", { token: params.get("token") || "" })
+      .then(() => setStatus({ type: "success", text: "Your email is verified." }))
+      .catch((err) =>
+        setStatus({
+          type: "error",
+          text: err.response?.data?.error || "Verification failed",
+        })
+      );
+  }, [params]);
+
+  return (
+    <Container maxWidth="sm">
+      <Paper elevation={3} sx={{ p: 4, mt: 8 }}>
+        <Typography variant="h4" align="center" gutterBottom>
+          Verify email
+        </Typography>
+        <Typography
+          align="center"
+          color={status.type === "error" ? "error" : "textPrimary"}
+        >
+          {status.text}
+        </Typography>
+        <Typography align="center" variant="body2" mt={2}>
+          <Link href="/login" underline="hover">
+            Go to login
+          </Link>
+        </Typography>
+      </Paper>
+    </Container>
+  );
+}
@@ -1,8 +1,8 @@
 import axios from "axios";
 
-const api = axios.create({
-  baseURL: "http://localhost:8080", // or your backend base URL
-});
+const baseURL = "http://localhost:8080"; // or your backend base URL
+
+const api = axios.create({ baseURL });
 
 // Set the Authorization header for every request if token exists
 api.interceptors.request.use((config) => {
@@ -10,7 +10,56 @@ api.interceptors.request.use((config) => {
   if (token) {
     config.headers.Authorization = `Bearer ${token}`;
   }
+  // Listings are scoped to the workspace picked in the UI, if any
+  const workspaceId = localStorage.getItem("workspace_id");
+  if (workspaceId) {
+    config.headers["X-Workspace-ID"] = workspaceId;
+  }
   return config;
 });
 
+// Access tokens are short-lived: on a 401, trade the refresh token for a new
+// pair once and retry. Concurrent requests share the same refresh.
+let refreshing = null;
+
+export const refreshTokens = () => {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem("refreshToken");
+    refreshing = (refreshToken
+      ? axios.post(`${baseURL}/token/refresh`, { refresh_token: refreshToken })
+      : Promise.reject(new Error("No refresh token"))
+    )
+      .then((res) => {
+        localStorage.setItem("token", res.data.token);
+        localStorage.setItem("refreshToken", res.data.refresh_token);
+        return res.data.token;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
+api.interceptors.response.use(
+  (res) => res,
+  async (error) => {
+    const original = error.config;
+    if (error.response?.status !== 401 || original._retried || !localStorage.getItem("refreshToken")) {
+      return Promise.reject(error);
+    }
+    original._retried = true;
+    try {
+      const token = await refreshTokens();
+      original.headers.Authorization = `Bearer ${token}`;
+      return api(original);
+    } catch {
+      localStorage.removeItem("token");
+      localStorage.removeItem("refreshToken");
+      window.location.href = "/login";
+      return Promise.reject(error);
+    }
+  }
+);
+
 export default api;
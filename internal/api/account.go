@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenRequest is the body for redeeming an emailed link
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// EmailRequest is the body for asking for an emailed link
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest is the body for /password/reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token"    binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// UpdateProfileRequest is the body for PUT /profile
+type UpdateProfileRequest struct {
+	Name  string `json:"name"  binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ChangePasswordRequest is the body for PUT /profile/password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword"     binding:"required,min=6"`
+}
+
+// VerifyEmail redeems the link sent after signup or an email change
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.auth.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		respondError(c, err, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerification mails a new verification link. It answers the same
+// whether or not the address has an account.
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.auth.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		respondError(c, err, "Failed to send verification email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account needs verifying, a new link is on its way"})
+}
+
+// ForgotPassword mails a password reset link. It answers the same whether
+// or not the address has an account.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.auth.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		respondError(c, err, "Failed to send reset email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account uses this email, a reset link is on its way"})
+}
+
+// ResetPassword redeems a reset link and logs the user out everywhere
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.auth.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		respondError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset; log in with the new password"})
+}
+
+// GetProfile returns the logged in user's account
+func (h *Handler) GetProfile(c *gin.Context) {
+	profile, err := h.auth.Profile(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err, "Failed to load profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile changes the logged in user's name and email
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.auth.UpdateProfile(c.Request.Context(), c.GetString("userID"), req.Name, req.Email)
+	if err != nil {
+		respondError(c, err, "Failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ChangePassword sets a new password; other sessions are logged out
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.auth.ChangePassword(c.Request.Context(), c.GetString("userID"), c.GetString("sessionID"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		respondError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+}
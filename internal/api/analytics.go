@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetChannelAnalytics returns a channel's daily statistics between ?from and
+// ?to (YYYY-MM-DD, default the last 30 days) with their deltas and its
+// fastest growing videos
+func (h *Handler) GetChannelAnalytics(c *gin.Context) {
+	trend, err := h.stats.ChannelTrend(c.Request.Context(), c.GetString("userID"), c.Param("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch analytics")
+		return
+	}
+	c.JSON(http.StatusOK, trend)
+}
+
+// GetProjectAnalytics returns a published project's daily statistics
+// between ?from and ?to with their deltas
+func (h *Handler) GetProjectAnalytics(c *gin.Context) {
+	trend, err := h.stats.ProjectTrend(c.Request.Context(), c.Param("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch analytics")
+		return
+	}
+	c.JSON(http.StatusOK, trend)
+}
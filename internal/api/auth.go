@@ -2,15 +2,9 @@ package api
 
 import (
 	"net/http"
-	"os"
-	"time"
 
-	"github.com/abhishek-sengar/ytmanager/internal/db"
-	_ "github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // SignupRequest defines the expected request payload
@@ -18,11 +12,12 @@ type SignupRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required"` // "editor" or "owner"
+	Role     string `json:"role"`         // optional, "editor"; owners cannot sign up
+	Invite   string `json:"invite_token"` // signing up from an editor invite
 }
 
 // Signup handler
-func Signup(c *gin.Context) {
+func (h *Handler) Signup(c *gin.Context) {
 	var req SignupRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -30,35 +25,19 @@ func Signup(c *gin.Context) {
 		return
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	_, err := h.auth.Signup(c.Request.Context(), service.SignupInput{
+		Name:        req.Name,
+		Email:       req.Email,
+		Password:    req.Password,
+		Role:        req.Role,
+		InviteToken: req.Invite,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		respondError(c, err, "Failed to create user")
 		return
 	}
 
-	// Insert user into database
-	query := `
-		INSERT INTO users (id, name, email, password_hash, role, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-
-	_, err = db.DB.Exec(
-		query,
-		uuid.New().String(),
-		req.Name,
-		req.Email,
-		string(hashedPassword),
-		req.Role,
-		time.Now(),
-	)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user: " + err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Signup successful"})
+	c.JSON(http.StatusOK, gin.H{"message": "Signup successful; check your email to verify your address"})
 }
 
 // LoginRequest is the expected payload for /login
@@ -67,61 +46,70 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse is returned on successful auth
-type LoginResponse struct {
-	Token string `json:"token"`
+// RefreshRequest is the body for /token/refresh and /logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // Login handler
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	// Lookup user
-	var (
-		id           string
-		passwordHash string
-		role         string
-		name         string
-	)
-	err := db.DB.QueryRow(
-		`SELECT id, password_hash, role, name FROM users WHERE email = $1`,
-		req.Email,
-	).Scan(&id, &passwordHash, &role, &name)
+
+	tokens, err := h.auth.Login(c.Request.Context(), req.Email, req.Password, c.Request.UserAgent())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email "})
+		respondError(c, err, "Failed to log in")
 		return
 	}
 
-	// Compare passwords
-	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RefreshToken rotates the refresh token and returns a new access token
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Create JWT
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT secret not configured"})
+	tokens, err := h.auth.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		respondError(c, err, "Failed to refresh token")
 		return
 	}
 
-	claims := jwt.MapClaims{
-		"sub":   id,
-		"email": req.Email,
-		"name":  name,
-		"role":  role,
-		"exp":   time.Now().Add(72 * time.Hour).Unix(),
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout ends the session of the given refresh token. It does not need an
+// access token so an expired client can still log out.
+func (h *Handler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err := h.auth.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		respondError(c, err, "Failed to log out")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// RevokeEditorSessions lets a channel manager log one of the channel's
+// editors out everywhere
+func (h *Handler) RevokeEditorSessions(c *gin.Context) {
+	n, err := h.auth.RevokeEditorSessions(c.Request.Context(), c.GetString("userID"), c.Param("editorId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not generate token"})
+		respondError(c, err, "Failed to revoke sessions")
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{Token: tokenString})
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked", "revoked": n})
 }
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireProjectPermission guards routes with a :id project parameter. The
+// caller's role on the project must grant perm (see service.Roles). Callers
+// with no role on the project get 404, those whose role lacks perm get 403.
+func (h *Handler) RequireProjectPermission(perm service.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		access, err := h.projects.Access(c.Request.Context(), c.Param("id"), userID)
+		if err != nil {
+			respondError(c, err, "Failed to fetch project")
+			c.Abort()
+			return
+		}
+
+		if !access.Can(perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to do this on this project"})
+			c.Abort()
+			return
+		}
+
+		c.Set("projectAccess", access)
+		c.Next()
+	}
+}
+
+// RequireChannelPermission guards routes with a :id channel parameter the
+// same way RequireProjectPermission guards project routes
+func (h *Handler) RequireChannelPermission(perm service.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if err := h.channels.CheckPermission(c.Request.Context(), c.Param("id"), userID, perm); err != nil {
+			respondError(c, err, "Failed to fetch channel")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// projectAccess returns what RequireProjectPermission stored for the request
+func projectAccess(c *gin.Context) *service.ProjectAccess {
+	if v, ok := c.Get("projectAccess"); ok {
+		return v.(*service.ProjectAccess)
+	}
+	return nil
+}
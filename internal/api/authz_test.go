@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// authzFixture is a channel with a project, which has one version, and one
+// user in each role
+type authzFixture struct {
+	*testAPI
+	channel *models.Channel
+	project *models.Project
+	users   map[string]*models.User // by role, "" for someone with no role
+	tokens  map[string]string
+}
+
+func newAuthzFixture(t *testing.T, status service.ProjectStatus) *authzFixture {
+	a := newTestAPI(t)
+	f := &authzFixture{testAPI: a, users: map[string]*models.User{}, tokens: map[string]string{}}
+	for _, role := range []string{service.RoleOwner, service.RoleManager, service.RoleReviewer, service.RoleEditor, service.RoleViewer, ""} {
+		f.users[role], f.tokens[role] = a.user("User-" + role)
+	}
+	f.channel = a.channel(f.users[service.RoleOwner])
+	for _, role := range []string{service.RoleManager, service.RoleReviewer, service.RoleEditor, service.RoleViewer} {
+		a.assign(f.channel, f.users[role], role)
+	}
+	f.project = a.project(f.channel, f.users[service.RoleEditor], status)
+	err := a.store.Projects().AddVersion(context.Background(), &models.ProjectVersion{
+		ID:         uuid.New().String(),
+		ProjectID:  f.project.ID,
+		VideoPath:  "videos/" + f.project.ID + "/v1.mp4",
+		UploaderID: f.users[service.RoleEditor].ID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// note adds a note on the project written by the user in role
+func (f *authzFixture) note(role string) *models.Note {
+	f.t.Helper()
+	now := time.Now()
+	n := &models.Note{
+		ID:        uuid.New().String(),
+		ProjectID: f.project.ID,
+		UserID:    f.users[role].ID,
+		Content:   "Trim the intro",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := f.store.Notes().Create(context.Background(), n); err != nil {
+		f.t.Fatal(err)
+	}
+	return n
+}
+
+// TestRoutePermissions checks every route behind RequireProjectPermission or
+// RequireChannelPermission: without a token, as someone with no role on the
+// channel, in a role missing the permission and in a role that has it
+func TestRoutePermissions(t *testing.T) {
+	tests := []struct {
+		method, path string // :id is the project or channel, :noteId a note by the allowed user
+		channel      bool   // :id is the channel
+		status       service.ProjectStatus
+		body         any
+		allowed      string // role that may call the route
+		denied       string // role lacking the permission, empty if every role has it
+		// status the allowed role gets, default 200. Routes that call YouTube
+		// get as far as finding the channel is not connected to it.
+		want int
+	}{
+		{method: "GET", path: "/projects/:id", allowed: service.RoleViewer},
+		{method: "GET", path: "/projects/:id/notes", allowed: service.RoleViewer},
+		{method: "POST", path: "/projects/:id/notes", body: gin.H{"content": "Looks good", "timestamp": 12}, allowed: service.RoleReviewer, denied: service.RoleViewer},
+		{method: "PUT", path: "/projects/:id/notes/:noteId", body: gin.H{"content": "Trim more"}, allowed: service.RoleReviewer, denied: service.RoleViewer},
+		{method: "DELETE", path: "/projects/:id/notes/:noteId", allowed: service.RoleReviewer, denied: service.RoleViewer},
+		{method: "POST", path: "/projects/:id/notes/:noteId/resolve", body: gin.H{"resolved": true}, allowed: service.RoleEditor, denied: service.RoleViewer},
+		{method: "POST", path: "/projects/:id/versions", body: formFile{"video", "cut.mp4", "video/mp4", []byte("not really a video")}, allowed: service.RoleEditor, denied: service.RoleManager},
+		{method: "GET", path: "/projects/:id/metadata", allowed: service.RoleViewer},
+		{method: "PUT", path: "/projects/:id/metadata", body: gin.H{"tags": []string{"vlog"}}, allowed: service.RoleEditor, denied: service.RoleReviewer},
+		{method: "PUT", path: "/projects/:id/thumbnail", body: formFile{"thumbnail", "thumb.png", "image/png", []byte("\x89PNG\r\n\x1a\n")}, allowed: service.RoleManager, denied: service.RoleReviewer},
+		{method: "GET", path: "/projects/:id/playlists", allowed: service.RoleManager, denied: service.RoleReviewer, want: http.StatusConflict},
+		{method: "POST", path: "/projects/:id/approve", status: service.StatusInReview, allowed: service.RoleReviewer, denied: service.RoleEditor},
+		{method: "POST", path: "/projects/:id/reject", status: service.StatusInReview, body: gin.H{"reason": "Too long"}, allowed: service.RoleReviewer, denied: service.RoleEditor},
+		{method: "POST", path: "/projects/:id/publish", status: service.StatusApproved, allowed: service.RoleManager, denied: service.RoleReviewer, want: http.StatusConflict},
+		{method: "POST", path: "/projects/:id/schedule", status: service.StatusApproved, body: gin.H{"publish_at": time.Now().Add(time.Hour)}, allowed: service.RoleManager, denied: service.RoleReviewer},
+		{method: "DELETE", path: "/projects/:id/schedule", status: service.StatusScheduled, allowed: service.RoleManager, denied: service.RoleReviewer},
+		{method: "GET", path: "/projects/:id/youtube", allowed: service.RoleViewer},
+		{method: "PUT", path: "/projects/:id/youtube", status: service.StatusPublished, body: gin.H{}, allowed: service.RoleManager, denied: service.RoleEditor, want: http.StatusConflict},
+		{method: "POST", path: "/projects/:id/youtube/pull", status: service.StatusPublished, allowed: service.RoleManager, denied: service.RoleEditor, want: http.StatusConflict},
+		{method: "GET", path: "/projects/:id/analytics", allowed: service.RoleViewer},
+		{method: "POST", path: "/projects/:id/status", body: gin.H{"status": string(service.StatusSubmitted)}, allowed: service.RoleEditor},
+		{method: "GET", path: "/projects/:id/history", allowed: service.RoleViewer},
+
+		{method: "GET", path: "/channels/:id/members", channel: true, allowed: service.RoleViewer},
+		{method: "PUT", path: "/channels/:id/members/:userId", channel: true, body: gin.H{"role": service.RoleReviewer}, allowed: service.RoleManager, denied: service.RoleReviewer},
+		{method: "DELETE", path: "/channels/:id/editors/:userId", channel: true, allowed: service.RoleManager, denied: service.RoleEditor},
+		{method: "POST", path: "/channels/:id/sync", channel: true, allowed: service.RoleManager, denied: service.RoleReviewer, want: http.StatusConflict},
+		{method: "POST", path: "/channels/:id/import", channel: true, allowed: service.RoleManager, denied: service.RoleReviewer, want: http.StatusConflict},
+		{method: "GET", path: "/channels/:id/analytics", channel: true, allowed: service.RoleViewer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			status := tt.status
+			if status == "" {
+				status = service.StatusDraft
+			}
+			f := newAuthzFixture(t, status)
+			id := f.project.ID
+			if tt.channel {
+				id = f.channel.ID
+			}
+			path := strings.NewReplacer(
+				":id", id,
+				":noteId", f.note(tt.allowed).ID,
+				":userId", f.users[service.RoleEditor].ID,
+			).Replace(tt.path)
+
+			check := func(who string, token string, want int) {
+				t.Helper()
+				if w := f.do(tt.method, path, token, tt.body); w.Code != want {
+					t.Errorf("%s: status = %d, want %d: %s", who, w.Code, want, w.Body)
+				}
+			}
+			check("no token", "", http.StatusUnauthorized)
+			check("no role", f.tokens[""], http.StatusNotFound)
+			if tt.denied != "" {
+				check(tt.denied, f.tokens[tt.denied], http.StatusForbidden)
+			}
+			want := tt.want
+			if want == 0 {
+				want = http.StatusOK
+			}
+			check(tt.allowed, f.tokens[tt.allowed], want)
+		})
+	}
+}
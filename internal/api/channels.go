@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ChannelResponse is a channel with the statistics of its last sync
+type ChannelResponse struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	IconURL          string     `json:"iconUrl"`
+	Email            string     `json:"email"`
+	YouTubeAccountID string     `json:"youtube_account_id"`
+	SubscriberCount  int64      `json:"subscriber_count"`
+	ViewCount        int64      `json:"view_count"`
+	VideoCount       int64      `json:"video_count"`
+	LastSyncedAt     *time.Time `json:"last_synced_at,omitempty"`
+	SyncError        string     `json:"sync_error,omitempty"`
+}
+
+func newChannelResponse(ch *models.Channel) ChannelResponse {
+	return ChannelResponse{
+		ID:               ch.ID,
+		Name:             ch.Name,
+		IconURL:          ch.IconURL,
+		Email:            ch.Email,
+		YouTubeAccountID: ch.YouTubeAccountID,
+		SubscriberCount:  ch.SubscriberCount,
+		ViewCount:        ch.ViewCount,
+		VideoCount:       ch.VideoCount,
+		LastSyncedAt:     ch.LastSyncedAt,
+		SyncError:        ch.SyncError,
+	}
+}
+
+// SyncChannel refreshes one of the owner's channels from YouTube right away
+func (h *Handler) SyncChannel(c *gin.Context) {
+	ch, err := h.sync.SyncChannel(c.Request.Context(), c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to sync channel")
+		return
+	}
+
+	c.JSON(http.StatusOK, newChannelResponse(ch))
+}
+
+// ImportChannel adds the videos already live on one of the owner's channels
+// as read-only published projects
+func (h *Handler) ImportChannel(c *gin.Context) {
+	result, err := h.channels.ImportUploads(c.Request.Context(), c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to import channel")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
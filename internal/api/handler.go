@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// Handler holds the services the HTTP handlers delegate to
+type Handler struct {
+	auth       *service.AuthService
+	projects   *service.ProjectService
+	notes      *service.NoteService
+	channels   *service.ChannelService
+	invites    *service.InviteService
+	workspaces *service.WorkspaceService
+	sync       *service.ChannelSyncer
+	stats      *service.AnalyticsService
+	jobs       *service.JobQueue
+	videos     service.Storage
+	oauth      *oauth2.Config
+	cfg        *config.Config
+}
+
+// NewHandler wires the services on top of store and the video storage backend
+func NewHandler(cfg *config.Config, store repository.Store, videos service.Storage) (*Handler, error) {
+	keys, err := service.NewTokenKeyring(cfg.TokenKeys, cfg.TokenKeyID)
+	if err != nil {
+		return nil, err
+	}
+	oauth := getGoogleOauthConfig(cfg.Google)
+	tokens := service.NewTokenManager(store, oauth, keys)
+	projects := service.NewProjectService(store, videos, tokens, cfg.YouTubeUploadBaseURL)
+	jobs := service.NewJobQueue(store)
+	projects.RegisterJobs(jobs)
+	stats := service.NewAnalyticsService(store, tokens)
+	stats.RegisterJobs(jobs)
+	mailer, err := service.NewMailer(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		auth:       service.NewAuthService(store, cfg.JWTSecret, mailer, cfg.FrontendURL),
+		projects:   projects,
+		notes:      service.NewNoteService(store),
+		channels:   service.NewChannelService(store, tokens, oauth),
+		invites:    service.NewInviteService(store, cfg.FrontendURL),
+		workspaces: service.NewWorkspaceService(store),
+		sync:       service.NewChannelSyncer(store, tokens),
+		stats:      stats,
+		jobs:       jobs,
+		videos:     videos,
+		oauth:      oauth,
+		cfg:        cfg,
+	}, nil
+}
+
+// RunWorkers runs the background jobs until ctx is cancelled, and returns
+// once they have all stopped
+func (h *Handler) RunWorkers(ctx context.Context) {
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	if h.cfg.ChannelSyncInterval > 0 {
+		run(func() { h.sync.Run(ctx, h.cfg.ChannelSyncInterval) })
+	}
+	if h.cfg.VideoSyncInterval > 0 {
+		run(func() { h.projects.RunVideoReconcile(ctx, h.cfg.VideoSyncInterval) })
+	}
+	if h.cfg.AnalyticsInterval > 0 {
+		run(func() { h.stats.Run(ctx, h.cfg.AnalyticsInterval) })
+	}
+	if h.cfg.JobWorkers > 0 {
+		run(func() { h.jobs.Run(ctx, h.cfg.JobWorkers, h.cfg.JobPollInterval) })
+	}
+	wg.Wait()
+}
+
+// respondError maps service errors onto HTTP responses. Unexpected errors
+// are reported as 500 with action describing what failed.
+func respondError(c *gin.Context, err error, action string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrUpstream):
+		status = http.StatusBadGateway
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, gin.H{"error": action + ": " + err.Error()})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
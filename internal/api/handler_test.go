@@ -0,0 +1,397 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse"
+
+// testAPI is the whole API running on the in-memory store
+type testAPI struct {
+	t      *testing.T
+	store  *memory.Store
+	router *gin.Engine
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		JWTSecret:   "test-jwt-secret",
+		TokenKeys:   map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 32))},
+		TokenKeyID:  "k1",
+		FrontendURL: "http://frontend.test",
+		Mail:        config.MailConfig{Backend: service.MailMemory},
+	}
+	videos, err := service.NewLocalStorage(t.TempDir(), "http://api.test", "test-signing-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.NewStore()
+	h, err := NewHandler(cfg, store, videos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	h.Routes(router)
+	return &testAPI{t: t, store: store, router: router}
+}
+
+// user creates a verified account and returns it with an access token
+func (a *testAPI) user(name string) (*models.User, string) {
+	a.t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	now := time.Now()
+	u := &models.User{
+		ID:              uuid.New().String(),
+		Name:            name,
+		Email:           strings.ToLower(name) + "@example.com",
+		PasswordHash:    string(hash),
+		Role:            service.RoleOwner,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+	}
+	if err := a.store.Users().Create(context.Background(), u); err != nil {
+		a.t.Fatal(err)
+	}
+	return u, a.login(u).AccessToken
+}
+
+// login starts a new session for u
+func (a *testAPI) login(u *models.User) service.Tokens {
+	a.t.Helper()
+	var tokens service.Tokens
+	w := a.do(http.MethodPost, "/login", "", gin.H{"email": u.Email, "password": testPassword})
+	a.decode(w, http.StatusOK, &tokens)
+	return tokens
+}
+
+// refresh trades refreshToken for new tokens, expecting status
+func (a *testAPI) refresh(refreshToken string, status int) service.Tokens {
+	a.t.Helper()
+	var tokens service.Tokens
+	w := a.do(http.MethodPost, "/token/refresh", "", gin.H{"refresh_token": refreshToken})
+	if status == http.StatusOK {
+		a.decode(w, status, &tokens)
+	} else {
+		a.decode(w, status, nil)
+	}
+	return tokens
+}
+
+// channel creates a channel owned by owner
+func (a *testAPI) channel(owner *models.User) *models.Channel {
+	a.t.Helper()
+	ch := &models.Channel{
+		ID:          uuid.New().String(),
+		OwnerID:     owner.ID,
+		YtChannelID: "UC" + uuid.New().String(),
+		Name:        owner.Name + "'s channel",
+		CreatedAt:   time.Now(),
+	}
+	if err := a.store.Channels().Create(context.Background(), ch); err != nil {
+		a.t.Fatal(err)
+	}
+	return ch
+}
+
+// assign gives the user role on the channel
+func (a *testAPI) assign(ch *models.Channel, u *models.User, role string) {
+	a.t.Helper()
+	ctx := context.Background()
+	if _, err := a.store.Channels().AddEditor(ctx, ch.ID, u.ID); err != nil {
+		a.t.Fatal(err)
+	}
+	if err := a.store.Channels().SetEditorRole(ctx, ch.ID, u.ID, role); err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+// project creates a project on ch in status
+func (a *testAPI) project(ch *models.Channel, editor *models.User, status service.ProjectStatus) *models.Project {
+	a.t.Helper()
+	now := time.Now()
+	p := &models.Project{
+		ID:        uuid.New().String(),
+		Title:     "Episode 1",
+		Status:    string(status),
+		EditorID:  editor.ID,
+		OwnerID:   ch.OwnerID,
+		ChannelID: ch.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := a.store.Projects().Create(context.Background(), p); err != nil {
+		a.t.Fatal(err)
+	}
+	return p
+}
+
+// formFile is a request body uploading one file as multipart/form-data
+type formFile struct {
+	field, filename, contentType string
+	data                         []byte
+}
+
+// do sends a request with body encoded as JSON, or as a form for a
+// formFile, authorized by token if set
+func (a *testAPI) do(method, path, token string, body any) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var buf bytes.Buffer
+	contentType := "application/json"
+	switch body := body.(type) {
+	case nil:
+	case formFile:
+		mw := multipart.NewWriter(&buf)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+body.field+`"; filename="`+body.filename+`"`)
+		header.Set("Content-Type", body.contentType)
+		part, err := mw.CreatePart(header)
+		if err == nil {
+			_, err = part.Write(body.data)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		contentType = mw.FormDataContentType()
+	default:
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// decode checks the response status and decodes its body into v
+func (a *testAPI) decode(w *httptest.ResponseRecorder, status int, v any) {
+	a.t.Helper()
+	if w.Code != status {
+		a.t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			a.t.Fatalf("decode %s: %v", w.Body, err)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	a := newTestAPI(t)
+	u, token := a.user("Alice")
+	if token == "" {
+		t.Fatal("login returned no access token")
+	}
+
+	w := a.do(http.MethodPost, "/login", "", gin.H{"email": u.Email, "password": "wrong"})
+	a.decode(w, http.StatusUnauthorized, nil)
+
+	w = a.do(http.MethodPost, "/login", "", gin.H{"email": "nobody@example.com", "password": testPassword})
+	a.decode(w, http.StatusUnauthorized, nil)
+
+	w = a.do(http.MethodPost, "/login", "", gin.H{"email": "not an email"})
+	a.decode(w, http.StatusBadRequest, nil)
+}
+
+func TestProfile(t *testing.T) {
+	a := newTestAPI(t)
+	u, token := a.user("Alice")
+
+	a.decode(a.do(http.MethodGet, "/profile", "", nil), http.StatusUnauthorized, nil)
+	a.decode(a.do(http.MethodGet, "/profile", "not-a-jwt", nil), http.StatusUnauthorized, nil)
+
+	var profile struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	}
+	a.decode(a.do(http.MethodGet, "/profile", token, nil), http.StatusOK, &profile)
+	if profile.ID != u.ID || profile.Email != u.Email {
+		t.Errorf("profile = %+v, want %s <%s>", profile, u.ID, u.Email)
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	a := newTestAPI(t)
+	u, _ := a.user("Alice")
+
+	tokens := a.login(u)
+	a.decode(a.do(http.MethodGet, "/profile", tokens.AccessToken, nil), http.StatusOK, nil)
+
+	w := a.do(http.MethodPost, "/logout", "", gin.H{"refresh_token": tokens.RefreshToken})
+	a.decode(w, http.StatusOK, nil)
+	a.decode(a.do(http.MethodGet, "/profile", tokens.AccessToken, nil), http.StatusUnauthorized, nil)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	a := newTestAPI(t)
+	u, _ := a.user("Alice")
+	first := a.login(u)
+
+	second := a.refresh(first.RefreshToken, http.StatusOK)
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh token was not rotated: %q", second.RefreshToken)
+	}
+	third := a.refresh(second.RefreshToken, http.StatusOK)
+	a.decode(a.do(http.MethodGet, "/profile", third.AccessToken, nil), http.StatusOK, nil)
+
+	a.refresh("made-up", http.StatusUnauthorized)
+	a.decode(a.do(http.MethodPost, "/token/refresh", "", gin.H{}), http.StatusBadRequest, nil)
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	a := newTestAPI(t)
+	u, _ := a.user("Alice")
+	first := a.login(u)
+	other := a.login(u)
+	second := a.refresh(first.RefreshToken, http.StatusOK)
+
+	// A replayed refresh token means it leaked; the whole session ends, so
+	// neither the thief nor the client holding the newest tokens gets in
+	a.refresh(first.RefreshToken, http.StatusUnauthorized)
+	a.refresh(second.RefreshToken, http.StatusUnauthorized)
+	a.decode(a.do(http.MethodGet, "/profile", second.AccessToken, nil), http.StatusUnauthorized, nil)
+
+	// The user's other sessions are not affected
+	a.decode(a.do(http.MethodGet, "/profile", other.AccessToken, nil), http.StatusOK, nil)
+	a.refresh(other.RefreshToken, http.StatusOK)
+}
+
+func TestRevokeEditorSessions(t *testing.T) {
+	a := newTestAPI(t)
+	owner, _ := a.user("Owner")
+	manager, managerToken := a.user("Manager")
+	reviewer, reviewerToken := a.user("Reviewer")
+	editor, _ := a.user("Editor")
+	_, strangerToken := a.user("Stranger")
+	ch := a.channel(owner)
+	a.assign(ch, manager, service.RoleManager)
+	a.assign(ch, reviewer, service.RoleReviewer)
+	a.assign(ch, editor, service.RoleEditor)
+	laptop, phone := a.login(editor), a.login(editor)
+	path := "/editors/" + editor.ID + "/sessions"
+
+	a.decode(a.do(http.MethodDelete, path, strangerToken, nil), http.StatusNotFound, nil)
+	a.decode(a.do(http.MethodDelete, path, reviewerToken, nil), http.StatusForbidden, nil)
+	a.decode(a.do(http.MethodGet, "/profile", laptop.AccessToken, nil), http.StatusOK, nil)
+
+	var got struct {
+		Revoked int `json:"revoked"`
+	}
+	a.decode(a.do(http.MethodDelete, path, managerToken, nil), http.StatusOK, &got)
+	// The session from a.user and the two above
+	if got.Revoked != 3 {
+		t.Errorf("revoked %d sessions, want 3", got.Revoked)
+	}
+	for _, tokens := range []service.Tokens{laptop, phone} {
+		a.decode(a.do(http.MethodGet, "/profile", tokens.AccessToken, nil), http.StatusUnauthorized, nil)
+		a.refresh(tokens.RefreshToken, http.StatusUnauthorized)
+	}
+	a.decode(a.do(http.MethodGet, "/profile", managerToken, nil), http.StatusOK, nil)
+}
+
+func TestProjectDetails(t *testing.T) {
+	a := newTestAPI(t)
+	owner, ownerToken := a.user("Owner")
+	editor, editorToken := a.user("Editor")
+	_, strangerToken := a.user("Stranger")
+	ch := a.channel(owner)
+	a.assign(ch, editor, service.RoleEditor)
+	p := a.project(ch, editor, service.StatusDraft)
+
+	for _, token := range []string{ownerToken, editorToken} {
+		var got struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		}
+		a.decode(a.do(http.MethodGet, "/projects/"+p.ID, token, nil), http.StatusOK, &got)
+		if got.ID != p.ID || got.Title != p.Title {
+			t.Errorf("project = %+v, want %s %q", got, p.ID, p.Title)
+		}
+	}
+
+	// Projects the caller has no part in look like they do not exist
+	a.decode(a.do(http.MethodGet, "/projects/"+p.ID, strangerToken, nil), http.StatusNotFound, nil)
+	a.decode(a.do(http.MethodGet, "/projects/"+uuid.New().String(), ownerToken, nil), http.StatusNotFound, nil)
+	a.decode(a.do(http.MethodGet, "/projects/not-a-uuid", ownerToken, nil), http.StatusNotFound, nil)
+}
+
+func TestNotes(t *testing.T) {
+	a := newTestAPI(t)
+	owner, ownerToken := a.user("Owner")
+	editor, editorToken := a.user("Editor")
+	ch := a.channel(owner)
+	a.assign(ch, editor, service.RoleEditor)
+	p := a.project(ch, editor, service.StatusInReview)
+
+	var note struct {
+		ID      string `json:"id"`
+		Content string `json:"content"`
+	}
+	w := a.do(http.MethodPost, "/projects/"+p.ID+"/notes", ownerToken, gin.H{"content": "Trim the intro", "timestamp": 3})
+	a.decode(w, http.StatusOK, &note)
+	if note.ID == "" || note.Content != "Trim the intro" {
+		t.Fatalf("note = %+v", note)
+	}
+
+	w = a.do(http.MethodPost, "/projects/"+p.ID+"/notes", editorToken, gin.H{"content": "Done", "parent_id": note.ID})
+	a.decode(w, http.StatusOK, nil)
+
+	w = a.do(http.MethodPost, "/projects/"+p.ID+"/notes", ownerToken, gin.H{"timestamp": 3})
+	a.decode(w, http.StatusBadRequest, nil)
+
+	var notes []json.RawMessage
+	a.decode(a.do(http.MethodGet, "/projects/"+p.ID+"/notes", editorToken, nil), http.StatusOK, &notes)
+	if len(notes) == 0 {
+		t.Error("listed no notes")
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	a := newTestAPI(t)
+	_, token := a.user("Alice")
+	_, otherToken := a.user("Bob")
+
+	var ws struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	a.decode(a.do(http.MethodPost, "/workspaces", token, gin.H{"name": "Studio"}), http.StatusCreated, &ws)
+	if ws.ID == "" || ws.Name != "Studio" {
+		t.Fatalf("workspace = %+v", ws)
+	}
+	a.decode(a.do(http.MethodPost, "/workspaces", token, gin.H{}), http.StatusBadRequest, nil)
+
+	a.decode(a.do(http.MethodGet, "/workspaces/"+ws.ID+"/members", token, nil), http.StatusOK, nil)
+	a.decode(a.do(http.MethodGet, "/workspaces/"+ws.ID+"/members", otherToken, nil), http.StatusNotFound, nil)
+}
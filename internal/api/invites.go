@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInviteRequest is the body for POST /invites
+type CreateInviteRequest struct {
+	Email      string   `json:"email" binding:"required,email"`
+	ChannelIDs []string `json:"channel_ids" binding:"required,min=1"`
+}
+
+// SetChannelRoleRequest is the body for PUT /channels/:id/members/:userId
+type SetChannelRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// AcceptInviteRequest is the body for POST /invites/accept
+type AcceptInviteRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CreateInvite lets a user invite an editor, by email, to channels they manage.
+// The editor does not need an account yet; the token can be used at signup.
+func (h *Handler) CreateInvite(c *gin.Context) {
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invite, err := h.invites.Create(c.Request.Context(), c.GetString("userID"), req.Email, req.ChannelIDs)
+	if err != nil {
+		respondError(c, err, "Failed to create invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// ListInvites returns the owner's invites that are neither accepted, revoked nor expired
+func (h *Handler) ListInvites(c *gin.Context) {
+	invites, err := h.invites.List(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite cancels a pending invite
+func (h *Handler) RevokeInvite(c *gin.Context) {
+	if err := h.invites.Revoke(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		respondError(c, err, "Failed to revoke invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// GetInvite shows an invite to the person holding its token, before they
+// sign up or log in to accept it.
+func (h *Handler) GetInvite(c *gin.Context) {
+	invite, err := h.invites.Preview(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// AcceptInvite attaches the logged-in editor to the invite's channels
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	var req AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.invites.Accept(c.Request.Context(), c.GetString("userID"), c.GetString("userRole"), c.GetString("userEmail"), req.Token)
+	if err != nil {
+		respondError(c, err, "Failed to accept invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite accepted"})
+}
+
+// RemoveEditor detaches an editor from a channel the caller manages
+func (h *Handler) RemoveEditor(c *gin.Context) {
+	if err := h.invites.RemoveEditor(c.Request.Context(), c.GetString("userID"), c.Param("id"), c.Param("editorId")); err != nil {
+		respondError(c, err, "Failed to remove editor")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Editor removed from channel"})
+}
+
+// ListRoles describes the roles channel members can have and what each allows
+func (h *Handler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, service.Roles())
+}
+
+// ListChannelMembers returns the channel's owner and assigned members with their roles
+func (h *Handler) ListChannelMembers(c *gin.Context) {
+	members, err := h.invites.Members(c.Request.Context(), c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SetChannelMemberRole changes the role of someone assigned to the channel
+func (h *Handler) SetChannelMemberRole(c *gin.Context) {
+	var req SetChannelRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.invites.SetRole(c.Request.Context(), c.GetString("userID"), c.Param("id"), c.Param("userId"), req.Role)
+	if err != nil {
+		respondError(c, err, "Failed to change role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated"})
+}
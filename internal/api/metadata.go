@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataRequest is the body for PUT /projects/:id/metadata. It replaces
+// the project's publish metadata; omitted fields get YouTube's defaults.
+type MetadataRequest struct {
+	Tags            []string `json:"tags"`
+	CategoryID      string   `json:"category_id"` // default 22, People & Blogs
+	DefaultLanguage string   `json:"default_language"`
+	MadeForKids     bool     `json:"made_for_kids"`
+	License         string   `json:"license"` // youtube (default) or creativeCommon
+	PlaylistIDs     []string `json:"playlist_ids"`
+}
+
+// GetProjectMetadata returns what the project will be published with
+func (h *Handler) GetProjectMetadata(c *gin.Context) {
+	metadata, err := h.projects.Metadata(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch metadata")
+		return
+	}
+	c.JSON(http.StatusOK, metadata)
+}
+
+// UpdateProjectMetadata lets the owner or editor fill in the publish
+// metadata while the project is under review
+func (h *Handler) UpdateProjectMetadata(c *gin.Context) {
+	var req MetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	metadata, err := h.projects.UpdateMetadata(c.Request.Context(), projectAccess(c), c.GetString("userID"), service.MetadataInput{
+		Tags:            req.Tags,
+		CategoryID:      req.CategoryID,
+		DefaultLanguage: req.DefaultLanguage,
+		MadeForKids:     req.MadeForKids,
+		License:         req.License,
+		PlaylistIDs:     req.PlaylistIDs,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to update metadata")
+		return
+	}
+	c.JSON(http.StatusOK, metadata)
+}
+
+// UploadThumbnail stores the "thumbnail" image of a multipart form as the
+// project's custom thumbnail
+func (h *Handler) UploadThumbnail(c *gin.Context) {
+	file, header, err := c.Request.FormFile("thumbnail")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No thumbnail provided"})
+		return
+	}
+	defer file.Close()
+
+	metadata, err := h.projects.SetThumbnail(c.Request.Context(), projectAccess(c), c.GetString("userID"),
+		file, header.Filename, header.Size, header.Header.Get("Content-Type"))
+	if err != nil {
+		respondError(c, err, "Failed to upload thumbnail")
+		return
+	}
+	c.JSON(http.StatusOK, metadata)
+}
+
+// GetProjectPlaylists lists the playlists of the project's channel to pick from
+func (h *Handler) GetProjectPlaylists(c *gin.Context) {
+	playlists, err := h.projects.Playlists(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch playlists")
+		return
+	}
+	c.JSON(http.StatusOK, playlists)
+}
@@ -2,14 +2,15 @@ package api
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware checks the bearer token and puts the caller's identity in the context
+func (h *Handler) AuthMiddleware() gin.HandlerFunc {
+	jwtSecret := []byte(h.cfg.JWTSecret)
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,18 +28,11 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := tokenParts[1]
-		jwtSecret := os.Getenv("JWT_SECRET")
-		if jwtSecret == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT secret not configured"})
-			c.Abort()
-			return
-		}
-
 		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, jwt.ErrSignatureInvalid
 			}
-			return []byte(jwtSecret), nil
+			return jwtSecret, nil
 		})
 
 		if err != nil || !token.Valid {
@@ -54,7 +48,27 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Refuse tokens whose session was logged out or revoked
+		sessionID, _ := claims["sid"].(string)
+		if sessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+		active, err := h.auth.SessionActive(c.Request.Context(), sessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session: " + err.Error()})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked"})
+			c.Abort()
+			return
+		}
+
 		// Pass user info to context
+		c.Set("sessionID", sessionID)
 		c.Set("userID", claims["sub"])
 		c.Set("userEmail", claims["email"])
 		c.Set("userRole", claims["role"])
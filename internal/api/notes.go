@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AddNoteRequest represents the body to add a note
+type AddNoteRequest struct {
+	Timestamp    *int   `json:"timestamp" binding:"omitempty,min=0"`     // in seconds
+	EndTimestamp *int   `json:"end_timestamp" binding:"omitempty,min=0"` // optional end of a time range
+	Content      string `json:"content" binding:"required"`              // comment text
+	ParentID     string `json:"parent_id"`                               // reply to this note
+}
+
+// UpdateNoteRequest represents the body to edit a note
+type UpdateNoteRequest struct {
+	Timestamp    *int   `json:"timestamp" binding:"omitempty,min=0"`
+	EndTimestamp *int   `json:"end_timestamp" binding:"omitempty,min=0"` // left alone when omitted
+	ClearEnd     bool   `json:"clear_end"`                               // drop the end of the time range
+	Content      string `json:"content" binding:"required"`
+}
+
+// ResolveNoteRequest marks a note as fixed or reopens it
+type ResolveNoteRequest struct {
+	Resolved bool `json:"resolved"`
+}
+
+// GetNotes lists a project's notes ordered by timestamp, with replies nested
+// under the note they answer.
+func (h *Handler) GetNotes(c *gin.Context) {
+	notes, err := h.notes.List(c.Request.Context(), projectAccess(c).ProjectID)
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// AddNote lets the owner leave a note on a project, and the owner or editor
+// reply to an existing note.
+func (h *Handler) AddNote(c *gin.Context) {
+	var req AddNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := h.notes.Add(c.Request.Context(), projectAccess(c), c.GetString("userID"), service.AddNoteInput{
+		Timestamp:    req.Timestamp,
+		EndTimestamp: req.EndTimestamp,
+		Content:      req.Content,
+		ParentID:     req.ParentID,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to add note")
+		return
+	}
+	note.AuthorName = c.GetString("userName")
+
+	c.JSON(http.StatusOK, note)
+}
+
+// UpdateNote lets the author edit a note's text or time range
+func (h *Handler) UpdateNote(c *gin.Context) {
+	var req UpdateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := h.notes.Update(c.Request.Context(), projectAccess(c), c.GetString("userID"), c.Param("noteId"), service.UpdateNoteInput{
+		Timestamp:    req.Timestamp,
+		EndTimestamp: req.EndTimestamp,
+		ClearEnd:     req.ClearEnd,
+		Content:      req.Content,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to update note")
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote lets the author delete a note together with its replies
+func (h *Handler) DeleteNote(c *gin.Context) {
+	if err := h.notes.Delete(c.Request.Context(), projectAccess(c), c.GetString("userID"), c.Param("noteId")); err != nil {
+		respondError(c, err, "Failed to delete note")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted successfully"})
+}
+
+// ResolveNote marks a top-level note as resolved or unresolved, e.g. when the
+// editor has fixed what the note asked for.
+func (h *Handler) ResolveNote(c *gin.Context) {
+	var req ResolveNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.notes.Resolve(c.Request.Context(), projectAccess(c), c.GetString("userID"), c.Param("noteId"), req.Resolved); err != nil {
+		respondError(c, err, "Failed to update note")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note updated successfully", "resolved": req.Resolved})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PublishRequest is the optional body for POST /projects/:id/publish
+type PublishRequest struct {
+	Privacy string `json:"privacy"` // "private", "unlisted", "public"; defaults to private
+}
+
+// PublishProject uploads an approved project's video to its channel on YouTube.
+// Only callers allowed to publish may call it (see RequireProjectPermission).
+// Calling it again after a failure resumes the upload.
+func (h *Handler) PublishProject(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req PublishRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	project, err := h.projects.Publish(c.Request.Context(), c.Param("id"), userID, service.PublishInput{Privacy: req.Privacy})
+	if err != nil {
+		if project != nil && errors.Is(err, service.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "youtube_video_id": project.YouTubeID})
+			return
+		}
+		respondError(c, err, "Failed to publish project")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Video published to YouTube successfully",
+		"youtube_video_id": project.YouTubeID,
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Routes registers every endpoint of the API on r
+func (h *Handler) Routes(r gin.IRouter) {
+	// Root endpoint for health check
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status":  "ok",
+			"message": "YouTube Manager API is running",
+		})
+	})
+
+	// Ping endpoint for testing
+	r.GET("/ping", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "pong"})
+	})
+
+	// Auth routes (login/signup)
+	r.POST("/signup", h.Signup)
+	r.POST("/login", h.Login)
+	r.POST("/token/refresh", h.RefreshToken)
+	r.POST("/logout", h.Logout)
+	r.GET("/invites/:token", h.GetInvite)
+
+	// Emailed account links
+	r.POST("/verify-email", h.VerifyEmail)
+	r.POST("/verify-email/resend", h.ResendVerification)
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+
+	// Google redirects here; the state ties the callback to its owner
+	r.GET("/api/youtube/callback", h.YoutubeCallback)
+
+	// Signed downloads for the local storage backend
+	r.GET("/files/*key", h.ServeLocalFile)
+
+	// Protected routes
+	protected := r.Group("/")
+	protected.Use(h.AuthMiddleware())
+
+	// The logged in user's own account
+	protected.GET("/profile", h.GetProfile)
+	protected.PUT("/profile", h.UpdateProfile)
+	protected.PUT("/profile/password", h.ChangePassword)
+
+	// Project related routes
+	// Listings are scoped to the workspace picked with X-Workspace-ID
+	inWorkspace := h.ActiveWorkspace()
+	protected.POST("/projects", h.CreateProject)
+	protected.GET("/projects", inWorkspace, h.GetUserProjects) // Generalized endpoint for owner/editor
+	protected.GET("/projects/recent", inWorkspace, h.GetRecentProjects)
+
+	// Routes on a single project declare the permission they need; the
+	// caller's role on the project's channel decides (see service.Roles)
+	view := h.RequireProjectPermission(service.PermProjectView)
+	upload := h.RequireProjectPermission(service.PermProjectUpload)
+	metadata := h.RequireProjectPermission(service.PermProjectMetadata)
+	approve := h.RequireProjectPermission(service.PermProjectApprove)
+	publish := h.RequireProjectPermission(service.PermProjectPublish)
+	notes := h.RequireProjectPermission(service.PermNoteWrite)
+	protected.GET("/projects/:id", view, h.GetProjectDetailsByID)
+	protected.GET("/projects/:id/notes", view, h.GetNotes)
+	protected.POST("/projects/:id/notes", notes, h.AddNote)
+	protected.PUT("/projects/:id/notes/:noteId", notes, h.UpdateNote)
+	protected.DELETE("/projects/:id/notes/:noteId", notes, h.DeleteNote)
+	protected.POST("/projects/:id/notes/:noteId/resolve", notes, h.ResolveNote)
+	protected.POST("/projects/:id/versions", upload, h.UploadProjectVersion)
+	protected.GET("/projects/:id/metadata", view, h.GetProjectMetadata)
+	protected.PUT("/projects/:id/metadata", metadata, h.UpdateProjectMetadata)
+	protected.PUT("/projects/:id/thumbnail", metadata, h.UploadThumbnail)
+	protected.GET("/projects/:id/playlists", metadata, h.GetProjectPlaylists)
+	protected.POST("/projects/:id/approve", approve, h.ApproveProject)
+	protected.POST("/projects/:id/reject", approve, h.RejectProject)
+	protected.POST("/projects/:id/publish", publish, h.PublishProject)
+	protected.POST("/projects/:id/schedule", publish, h.ScheduleProject)
+	protected.DELETE("/projects/:id/schedule", publish, h.CancelSchedule)
+	protected.GET("/projects/:id/youtube", view, h.GetVideoSync)
+	protected.PUT("/projects/:id/youtube", publish, h.PushVideo)
+	protected.POST("/projects/:id/youtube/pull", publish, h.PullVideo)
+	protected.GET("/projects/:id/analytics", view, h.GetProjectAnalytics)
+	// Each status change needs its own permission, checked by the service
+	protected.POST("/projects/:id/status", view, h.ChangeProjectStatus)
+	protected.GET("/projects/:id/history", view, h.GetProjectHistory)
+
+	// Editor invitations and channel assignment
+	protected.POST("/invites", h.CreateInvite)
+	protected.GET("/invites", h.ListInvites)
+	protected.DELETE("/invites/:id", h.RevokeInvite)
+	protected.POST("/invites/accept", h.AcceptInvite)
+	manageChannel := h.RequireChannelPermission(service.PermChannelManage)
+	viewChannel := h.RequireChannelPermission(service.PermProjectView)
+	protected.GET("/roles", h.ListRoles)
+	protected.GET("/channels/:id/members", viewChannel, h.ListChannelMembers)
+	protected.PUT("/channels/:id/members/:userId", manageChannel, h.SetChannelMemberRole)
+	protected.DELETE("/channels/:id/editors/:editorId", manageChannel, h.RemoveEditor)
+	protected.POST("/channels/:id/sync", manageChannel, h.SyncChannel)
+	protected.POST("/channels/:id/import", manageChannel, h.ImportChannel)
+	protected.GET("/channels/:id/analytics", viewChannel, h.GetChannelAnalytics)
+	protected.DELETE("/editors/:editorId/sessions", h.RevokeEditorSessions)
+
+	// Sidebar data for both owners and editors
+	protected.GET("/sidebar-data", inWorkspace, h.GetSidebarData)
+
+	// Workspaces and their members
+	protected.GET("/workspaces", h.ListWorkspaces)
+	protected.POST("/workspaces", h.CreateWorkspace)
+	protected.GET("/workspaces/:id/members", h.ListWorkspaceMembers)
+	protected.POST("/workspaces/:id/members", h.AddWorkspaceMember)
+	protected.PUT("/workspaces/:id/members/:userId", h.SetWorkspaceMemberRole)
+	protected.DELETE("/workspaces/:id/members/:userId", h.RemoveWorkspaceMember)
+
+	// Protected YouTube integration routes
+	protected.POST("/api/youtube/auth", h.YoutubeAuth)
+	protected.GET("/api/youtube/connections/:id", h.GetYoutubeConnection)
+	protected.GET("/api/youtube/accounts", h.ListYoutubeAccounts)
+	protected.DELETE("/api/youtube/accounts/:id", h.DisconnectYoutubeAccount)
+	protected.GET("/api/youtube/unattached-channels", h.GetUnattachedChannels)
+	protected.POST("/api/youtube/add-channels", inWorkspace, h.AddChannelsToDashboard)
+
+	protected.POST("/api/video/upload", h.UploadVideo)
+}
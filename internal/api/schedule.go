@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleRequest is the body for POST /projects/:id/schedule
+type ScheduleRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"` // RFC 3339
+}
+
+// ScheduleProject has an approved project go live on YouTube at publish_at,
+// or moves the time of one that is scheduled already. The video is uploaded
+// in the background.
+func (h *Handler) ScheduleProject(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.projects.Schedule(c.Request.Context(), c.Param("id"), userID, req.PublishAt)
+	if err != nil {
+		respondError(c, err, "Failed to schedule project")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Project scheduled",
+		"status":     project.Status,
+		"publish_at": project.PublishAt,
+	})
+}
+
+// CancelSchedule takes a scheduled project back to approved
+func (h *Handler) CancelSchedule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if err := h.projects.CancelSchedule(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondError(c, err, "Failed to cancel schedule")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule cancelled"})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeStatusRequest is the body for POST /projects/:id/status
+type ChangeStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// ChangeProjectStatus moves a project to any state the caller is allowed to
+// move it to, e.g. submitting a draft or archiving a project.
+func (h *Handler) ChangeProjectStatus(c *gin.Context) {
+	var req ChangeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	to, err := h.projects.ChangeStatus(c.Request.Context(), c.Param("id"), c.GetString("userID"), req.Status, req.Reason)
+	if err != nil {
+		respondError(c, err, "Failed to update project status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project status updated", "status": to})
+}
+
+// GetProjectHistory returns the status change log of a project, oldest first
+func (h *Handler) GetProjectHistory(c *gin.Context) {
+	events, err := h.projects.History(c.Request.Context(), projectAccess(c).ProjectID)
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
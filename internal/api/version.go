@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadProjectVersion lets the project's editor upload a new revision of the video.
+// The project is resubmitted so the owner can review the new version.
+func (h *Handler) UploadProjectVersion(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("video")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No video file provided"})
+		return
+	}
+	defer file.Close()
+
+	version, err := h.projects.UploadVersion(c.Request.Context(), c.Param("id"), userID,
+		file, header.Filename, header.Size, header.Header.Get("Content-Type"))
+	if err != nil {
+		respondError(c, err, "Failed to upload version")
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PushVideoRequest is the body for PUT /projects/:id/youtube. Omitted
+// fields stay as they are.
+type PushVideoRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+	Force       bool      `json:"force"` // overwrite changes made on YouTube
+}
+
+// GetVideoSync returns the project's dashboard values next to the copy of
+// its video last synced with YouTube, including any conflict
+func (h *Handler) GetVideoSync(c *gin.Context) {
+	sync, err := h.projects.VideoSync(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch video")
+		return
+	}
+	c.JSON(http.StatusOK, sync)
+}
+
+// PushVideo edits a published video here and on YouTube. Changes that clash
+// with edits made on YouTube are reported with 409 unless forced.
+func (h *Handler) PushVideo(c *gin.Context) {
+	var req PushVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sync, err := h.projects.PushVideo(c.Request.Context(), projectAccess(c), service.VideoEdit{
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Force:       req.Force,
+	})
+	if err != nil {
+		if sync != nil && errors.Is(err, service.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflict": sync.Conflict})
+			return
+		}
+		respondError(c, err, "Failed to update video")
+		return
+	}
+	c.JSON(http.StatusOK, sync)
+}
+
+// PullVideo takes over the video's title, description and tags from YouTube
+func (h *Handler) PullVideo(c *gin.Context) {
+	sync, err := h.projects.PullVideo(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch video")
+		return
+	}
+	c.JSON(http.StatusOK, sync)
+}
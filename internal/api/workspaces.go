@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWorkspaceRequest is the body for POST /workspaces
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// AddWorkspaceMemberRequest is the body for POST /workspaces/:id/members
+type AddWorkspaceMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// SetWorkspaceRoleRequest is the body for PUT /workspaces/:id/members/:userId
+type SetWorkspaceRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ActiveWorkspace resolves the workspace the caller is working in from the
+// X-Workspace-ID header, falling back to the first one they joined.
+// Workspaces they are not a member of get 404.
+func (h *Handler) ActiveWorkspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m, err := h.workspaces.Active(c.Request.Context(), c.GetString("userID"), c.GetHeader("X-Workspace-ID"))
+		if err != nil {
+			respondError(c, err, "Failed to fetch workspace")
+			c.Abort()
+			return
+		}
+
+		c.Set("workspace", m)
+		c.Next()
+	}
+}
+
+// activeWorkspace returns what ActiveWorkspace stored for the request; nil
+// when the caller belongs to no workspace
+func activeWorkspace(c *gin.Context) *models.WorkspaceMember {
+	if v, ok := c.Get("workspace"); ok {
+		return v.(*models.WorkspaceMember)
+	}
+	return nil
+}
+
+// ListWorkspaces returns the caller's workspaces with their role in each
+func (h *Handler) ListWorkspaces(c *gin.Context) {
+	workspaces, err := h.workspaces.List(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// CreateWorkspace starts a workspace administered by the caller
+func (h *Handler) CreateWorkspace(c *gin.Context) {
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.workspaces.Create(c.Request.Context(), c.GetString("userID"), req.Name)
+	if err != nil {
+		respondError(c, err, "Failed to create workspace")
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// ListWorkspaceMembers returns the workspace's members to any of them
+func (h *Handler) ListWorkspaceMembers(c *gin.Context) {
+	members, err := h.workspaces.Members(c.Request.Context(), c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err, "Query failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddWorkspaceMember lets an admin add an existing user to the workspace
+func (h *Handler) AddWorkspaceMember(c *gin.Context) {
+	var req AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.workspaces.AddMember(c.Request.Context(), c.Param("id"), c.GetString("userID"), req.Email, req.Role)
+	if err != nil {
+		respondError(c, err, "Failed to add member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// SetWorkspaceMemberRole lets an admin change a member's role
+func (h *Handler) SetWorkspaceMemberRole(c *gin.Context) {
+	var req SetWorkspaceRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.workspaces.SetRole(c.Request.Context(), c.Param("id"), c.GetString("userID"), c.Param("userId"), req.Role)
+	if err != nil {
+		respondError(c, err, "Failed to change role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated"})
+}
+
+// RemoveWorkspaceMember lets an admin remove a member, or a member leave
+func (h *Handler) RemoveWorkspaceMember(c *gin.Context) {
+	err := h.workspaces.RemoveMember(c.Request.Context(), c.Param("id"), c.GetString("userID"), c.Param("userId"))
+	if err != nil {
+		respondError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
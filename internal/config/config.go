@@ -1 +1,358 @@
+// Package config loads the server's settings. Values come from, in order of
+// precedence, the process environment, an optional .env file, an optional
+// YAML file and the defaults below.
 package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting the server needs
+type Config struct {
+	Port      string `yaml:"port"`
+	JWTSecret string `yaml:"jwt_secret"`
+
+	// TokenKeys are the base64-encoded 32-byte keys that YouTube OAuth tokens
+	// are encrypted with, by key ID. Keys being rotated out stay listed until
+	// cmd/rotate-token-keys has moved every account to TokenKeyID.
+	TokenKeys map[string]string `yaml:"token_keys"`
+	// TokenKeyID is the key new tokens are encrypted with (default the only
+	// key in TokenKeys)
+	TokenKeyID string `yaml:"token_key_id"`
+
+	// FrontendURL is the base URL of the web app, used in links we hand out
+	FrontendURL string `yaml:"frontend_url"`
+	// CORSOrigins may call the API from a browser (default FrontendURL)
+	CORSOrigins []string `yaml:"cors_origins"`
+	// OAuthRedirectURL is the frontend page the YouTube OAuth callback sends
+	// the owner back to (default FrontendURL + "/oauth-callback")
+	OAuthRedirectURL string `yaml:"oauth_redirect_url"`
+	// YouTubeUploadBaseURL overrides the resumable upload endpoint, for tests
+	YouTubeUploadBaseURL string `yaml:"youtube_upload_base_url"`
+	// ChannelSyncInterval is how often channel metadata is refreshed from
+	// YouTube (default 6h; a negative value disables the background sync)
+	ChannelSyncInterval time.Duration `yaml:"channel_sync_interval"`
+	// VideoSyncInterval is how often published videos are compared with
+	// YouTube (default 1h; a negative value disables it)
+	VideoSyncInterval time.Duration `yaml:"video_sync_interval"`
+	// AnalyticsInterval is how often channel and video statistics are
+	// snapshotted (default 24h; a negative value disables it)
+	AnalyticsInterval time.Duration `yaml:"analytics_interval"`
+	// JobWorkers is how many background jobs run at once (default 2; a
+	// negative value runs none, for servers that only serve requests)
+	JobWorkers int `yaml:"job_workers"`
+	// JobPollInterval is how often idle workers look for due jobs (default 5s)
+	JobPollInterval time.Duration `yaml:"job_poll_interval"`
+
+	Database DatabaseConfig `yaml:"database"`
+	Google   GoogleConfig   `yaml:"google"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Mail     MailConfig     `yaml:"mail"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+// DSN is the lib/pq connection string for the database
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode)
+}
+
+// GoogleConfig is the OAuth client used to connect YouTube accounts
+type GoogleConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// RedirectURL is our /api/youtube/callback as registered with Google
+	RedirectURL string `yaml:"redirect_url"`
+}
+
+// StorageConfig selects and configures the video storage backend
+type StorageConfig struct {
+	Backend string `yaml:"backend"` // local, s3 or gcs
+
+	LocalDir      string `yaml:"local_dir"`
+	PublicURL     string `yaml:"public_url"`
+	SigningSecret string `yaml:"signing_secret"` // required for local, must differ from JWTSecret
+
+	S3Endpoint  string `yaml:"s3_endpoint"`
+	S3Region    string `yaml:"s3_region"`
+	S3Bucket    string `yaml:"s3_bucket"`
+	S3AccessKey string `yaml:"s3_access_key"`
+	S3SecretKey string `yaml:"s3_secret_key"`
+
+	GCSBucket          string `yaml:"gcs_bucket"`
+	GCSCredentialsFile string `yaml:"gcs_credentials_file"`
+}
+
+// MailConfig selects how verification and password reset emails are sent
+type MailConfig struct {
+	// Backend is smtp, or memory to keep mail in the process without
+	// delivering it (development only). It must be set explicitly.
+	Backend string `yaml:"backend"`
+	From    string `yaml:"from"`
+
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     string `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
+
+// Load reads the YAML file at yamlPath and the .env file at envPath, either
+// of which may be empty or missing, applies environment overrides and
+// defaults and validates the result.
+func Load(yamlPath, envPath string) (*Config, error) {
+	cfg := &Config{}
+
+	if yamlPath != "" {
+		data, err := os.ReadFile(yamlPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: reading %s: %w", yamlPath, err)
+		}
+		if err == nil {
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("config: parsing %s: %w", yamlPath, err)
+			}
+		}
+	}
+
+	dotenv := map[string]string{}
+	if envPath != "" {
+		values, err := godotenv.Read(envPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: reading %s: %w", envPath, err)
+		}
+		if err == nil {
+			dotenv = values
+		}
+	}
+	lookup := func(key string) (string, bool) {
+		if v, ok := os.LookupEnv(key); ok {
+			return v, true
+		}
+		v, ok := dotenv[key]
+		return v, ok
+	}
+
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+	cfg.applyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides fields with the environment variables that are set
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	vars := map[string]*string{
+		"PORT":                    &c.Port,
+		"JWT_SECRET":              &c.JWTSecret,
+		"TOKEN_KEY_ID":            &c.TokenKeyID,
+		"FRONTEND_URL":            &c.FrontendURL,
+		"OAUTH_REDIRECT_URL":      &c.OAuthRedirectURL,
+		"YOUTUBE_UPLOAD_BASE_URL": &c.YouTubeUploadBaseURL,
+
+		"DB_HOST":     &c.Database.Host,
+		"DB_PORT":     &c.Database.Port,
+		"DB_USER":     &c.Database.User,
+		"DB_PASSWORD": &c.Database.Password,
+		"DB_NAME":     &c.Database.Name,
+		"DB_SSLMODE":  &c.Database.SSLMode,
+
+		"GOOGLE_CLIENT_ID":     &c.Google.ClientID,
+		"GOOGLE_CLIENT_SECRET": &c.Google.ClientSecret,
+		"GOOGLE_REDIRECT_URL":  &c.Google.RedirectURL,
+
+		"STORAGE_BACKEND":                &c.Storage.Backend,
+		"STORAGE_LOCAL_DIR":              &c.Storage.LocalDir,
+		"STORAGE_PUBLIC_URL":             &c.Storage.PublicURL,
+		"STORAGE_SIGNING_SECRET":         &c.Storage.SigningSecret,
+		"S3_ENDPOINT":                    &c.Storage.S3Endpoint,
+		"S3_REGION":                      &c.Storage.S3Region,
+		"S3_BUCKET":                      &c.Storage.S3Bucket,
+		"S3_ACCESS_KEY":                  &c.Storage.S3AccessKey,
+		"S3_SECRET_KEY":                  &c.Storage.S3SecretKey,
+		"GCS_BUCKET_NAME":                &c.Storage.GCSBucket,
+		"GOOGLE_APPLICATION_CREDENTIALS": &c.Storage.GCSCredentialsFile,
+
+		"MAIL_BACKEND":  &c.Mail.Backend,
+		"MAIL_FROM":     &c.Mail.From,
+		"SMTP_HOST":     &c.Mail.SMTPHost,
+		"SMTP_PORT":     &c.Mail.SMTPPort,
+		"SMTP_USERNAME": &c.Mail.SMTPUsername,
+		"SMTP_PASSWORD": &c.Mail.SMTPPassword,
+	}
+	for key, field := range vars {
+		if v, ok := lookup(key); ok && v != "" {
+			*field = v
+		}
+	}
+
+	if v, ok := lookup("CORS_ORIGINS"); ok && v != "" {
+		c.CORSOrigins = nil
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.CORSOrigins = append(c.CORSOrigins, origin)
+			}
+		}
+	}
+
+	// TOKEN_KEYS is a comma-separated list of id:base64key pairs
+	if v, ok := lookup("TOKEN_KEYS"); ok && v != "" {
+		c.TokenKeys = map[string]string{}
+		for _, pair := range strings.Split(v, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			id, key, found := strings.Cut(pair, ":")
+			if !found || id == "" || key == "" {
+				return fmt.Errorf("config: TOKEN_KEYS: %q is not id:key", pair)
+			}
+			c.TokenKeys[id] = key
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"CHANNEL_SYNC_INTERVAL": &c.ChannelSyncInterval,
+		"VIDEO_SYNC_INTERVAL":   &c.VideoSyncInterval,
+		"ANALYTICS_INTERVAL":    &c.AnalyticsInterval,
+		"JOB_POLL_INTERVAL":     &c.JobPollInterval,
+	}
+	for key, field := range durations {
+		if v, ok := lookup(key); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			*field = d
+		}
+	}
+
+	if v, ok := lookup("JOB_WORKERS"); ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("config: JOB_WORKERS: %w", err)
+		}
+		c.JobWorkers = n
+	}
+	return nil
+}
+
+func (c *Config) applyDefaults() {
+	setDefault(&c.Port, "8080")
+	setDefault(&c.FrontendURL, "http://localhost:5173")
+	c.FrontendURL = strings.TrimRight(c.FrontendURL, "/")
+	setDefault(&c.OAuthRedirectURL, c.FrontendURL+"/oauth-callback")
+	if len(c.CORSOrigins) == 0 {
+		c.CORSOrigins = []string{c.FrontendURL}
+	}
+	if c.TokenKeyID == "" && len(c.TokenKeys) == 1 {
+		for id := range c.TokenKeys {
+			c.TokenKeyID = id
+		}
+	}
+	if c.ChannelSyncInterval == 0 {
+		c.ChannelSyncInterval = 6 * time.Hour
+	}
+	if c.VideoSyncInterval == 0 {
+		c.VideoSyncInterval = time.Hour
+	}
+	if c.AnalyticsInterval == 0 {
+		c.AnalyticsInterval = 24 * time.Hour
+	}
+	if c.JobWorkers == 0 {
+		c.JobWorkers = 2
+	}
+	if c.JobPollInterval <= 0 {
+		c.JobPollInterval = 5 * time.Second
+	}
+
+	setDefault(&c.Database.Port, "5432")
+	setDefault(&c.Database.SSLMode, "disable")
+
+	setDefault(&c.Storage.Backend, "gcs")
+
+	setDefault(&c.Mail.SMTPPort, "587")
+}
+
+func setDefault(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// Validate reports every required setting that is missing
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(value, key string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	require(c.JWTSecret, "JWT_SECRET")
+	if len(c.TokenKeys) == 0 {
+		errs = append(errs, errors.New("TOKEN_KEYS is required"))
+	} else if _, ok := c.TokenKeys[c.TokenKeyID]; !ok {
+		errs = append(errs, fmt.Errorf("TOKEN_KEY_ID must name one of TOKEN_KEYS, got %q", c.TokenKeyID))
+	}
+	require(c.Database.Host, "DB_HOST")
+	require(c.Database.User, "DB_USER")
+	require(c.Database.Name, "DB_NAME")
+	require(c.Google.ClientID, "GOOGLE_CLIENT_ID")
+	require(c.Google.ClientSecret, "GOOGLE_CLIENT_SECRET")
+	require(c.Google.RedirectURL, "GOOGLE_REDIRECT_URL")
+
+	switch c.Storage.Backend {
+	case "local":
+		// A leaked key must not forge both file URLs and access tokens
+		require(c.Storage.SigningSecret, "STORAGE_SIGNING_SECRET")
+		if c.Storage.SigningSecret != "" && c.Storage.SigningSecret == c.JWTSecret {
+			errs = append(errs, errors.New("STORAGE_SIGNING_SECRET must differ from JWT_SECRET"))
+		}
+	case "s3":
+		require(c.Storage.S3Endpoint, "S3_ENDPOINT")
+		require(c.Storage.S3Bucket, "S3_BUCKET")
+		require(c.Storage.S3AccessKey, "S3_ACCESS_KEY")
+		require(c.Storage.S3SecretKey, "S3_SECRET_KEY")
+	case "gcs":
+		require(c.Storage.GCSBucket, "GCS_BUCKET_NAME")
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_BACKEND must be local, s3 or gcs, got %q", c.Storage.Backend))
+	}
+
+	// No default: without a working mailer nobody can verify their email
+	switch c.Mail.Backend {
+	case "":
+		require(c.Mail.Backend, "MAIL_BACKEND")
+	case "memory":
+		// nothing is delivered; for tests and development only
+	case "smtp":
+		require(c.Mail.SMTPHost, "SMTP_HOST")
+		require(c.Mail.From, "MAIL_FROM")
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_BACKEND must be smtp or memory, got %q", c.Mail.Backend))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
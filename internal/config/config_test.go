@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+// validConfig passes Validate with the local storage backend
+func validConfig() *Config {
+	return &Config{
+		JWTSecret:  "jwt-secret",
+		TokenKeys:  map[string]string{"k1": testKey},
+		TokenKeyID: "k1",
+		Database:   DatabaseConfig{Host: "db", User: "app", Name: "ytmanager"},
+		Google:     GoogleConfig{ClientID: "id", ClientSecret: "secret", RedirectURL: "http://api.test/api/youtube/callback"},
+		Storage:    StorageConfig{Backend: "local", LocalDir: "uploads", SigningSecret: "signing-secret"},
+		Mail:       MailConfig{Backend: "memory"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string // empty if the config is valid
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{name: "no token keys", change: func(c *Config) { c.TokenKeys = nil }, wantErr: "TOKEN_KEYS is required"},
+		{name: "unknown token key id", change: func(c *Config) { c.TokenKeyID = "k2" }, wantErr: "TOKEN_KEY_ID must name one of TOKEN_KEYS"},
+		{name: "no signing secret", change: func(c *Config) { c.Storage.SigningSecret = "" }, wantErr: "STORAGE_SIGNING_SECRET is required"},
+		{name: "signing secret is the JWT secret", change: func(c *Config) { c.Storage.SigningSecret = c.JWTSecret }, wantErr: "must differ from JWT_SECRET"},
+		{name: "s3 without bucket", change: func(c *Config) {
+			c.Storage = StorageConfig{Backend: "s3", S3Endpoint: "https://s3.test", S3AccessKey: "a", S3SecretKey: "s"}
+		}, wantErr: "S3_BUCKET is required"},
+		{name: "gcs without bucket", change: func(c *Config) { c.Storage = StorageConfig{Backend: "gcs"} }, wantErr: "GCS_BUCKET_NAME is required"},
+		{name: "unknown storage", change: func(c *Config) { c.Storage.Backend = "ftp" }, wantErr: `STORAGE_BACKEND must be local, s3 or gcs, got "ftp"`},
+		{name: "no mail backend", change: func(c *Config) { c.Mail.Backend = "" }, wantErr: "MAIL_BACKEND is required"},
+		{name: "smtp without host", change: func(c *Config) { c.Mail = MailConfig{Backend: "smtp", From: "app@example.com"} }, wantErr: "SMTP_HOST is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.change(c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// clearEnv hides the variables Load reads that the test does not set
+func clearEnv(t *testing.T) {
+	for _, key := range []string{
+		"PORT", "JWT_SECRET", "TOKEN_KEYS", "TOKEN_KEY_ID", "FRONTEND_URL", "CORS_ORIGINS",
+		"DB_HOST", "DB_USER", "DB_NAME", "GOOGLE_CLIENT_ID", "GOOGLE_CLIENT_SECRET", "GOOGLE_REDIRECT_URL",
+		"STORAGE_BACKEND", "STORAGE_SIGNING_SECRET", "GCS_BUCKET_NAME", "MAIL_BACKEND", "JOB_WORKERS",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+// testYAML is a valid configuration with the single token key k1
+const testYAML = `
+jwt_secret: jwt-secret
+token_keys:
+  k1: AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+database: {host: db, user: app, name: ytmanager}
+google: {client_id: id, client_secret: secret, redirect_url: http://api.test/api/youtube/callback}
+storage: {backend: local, signing_secret: signing-secret}
+mail: {backend: memory}
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, c *Config)
+	}{
+		{name: "defaults", check: func(t *testing.T, c *Config) {
+			if c.TokenKeyID != "k1" || c.Port != "8080" || c.JobWorkers != 2 {
+				t.Errorf("token key %q, port %q, %d workers", c.TokenKeyID, c.Port, c.JobWorkers)
+			}
+		}},
+		{name: "environment wins", env: map[string]string{
+			"TOKEN_KEYS":   "k1:" + testKey + ", k2:" + testKey,
+			"TOKEN_KEY_ID": "k2",
+			"CORS_ORIGINS": "http://a.test, http://b.test",
+		}, check: func(t *testing.T, c *Config) {
+			if len(c.TokenKeys) != 2 || c.TokenKeyID != "k2" {
+				t.Errorf("token keys %v, current %q", c.TokenKeys, c.TokenKeyID)
+			}
+			if strings.Join(c.CORSOrigins, " ") != "http://a.test http://b.test" {
+				t.Errorf("CORS origins %q", c.CORSOrigins)
+			}
+		}},
+		{name: "two keys without an id", env: map[string]string{"TOKEN_KEYS": "k1:" + testKey + ",k2:" + testKey}, wantErr: `TOKEN_KEY_ID must name one of TOKEN_KEYS, got ""`},
+		{name: "malformed token keys", env: map[string]string{"TOKEN_KEYS": "k1"}, wantErr: `TOKEN_KEYS: "k1" is not id:key`},
+		{name: "signing secret reused", env: map[string]string{"STORAGE_SIGNING_SECRET": "jwt-secret"}, wantErr: "must differ from JWT_SECRET"},
+		{name: "gcs without bucket", env: map[string]string{"STORAGE_BACKEND": "gcs"}, wantErr: "GCS_BUCKET_NAME is required"},
+		{name: "bad worker count", env: map[string]string{"JOB_WORKERS": "many"}, wantErr: "JOB_WORKERS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(testYAML), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := Load(path, filepath.Join(t.TempDir(), "missing.env"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
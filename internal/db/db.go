@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
 
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
@@ -12,19 +13,10 @@ import (
 
 var DB *sql.DB
 
-func Connect() error {
+func Connect(cfg config.DatabaseConfig) error {
 	var err error
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
-
-	DB, err = sql.Open("postgres", dsn)
+	DB, err = sql.Open("postgres", cfg.DSN())
 	if err != nil {
 		return fmt.Errorf("cannot open database: %w", err)
 	}
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE projects
+    ADD COLUMN youtube_video_id TEXT,
+    ADD COLUMN upload_session_url TEXT,
+    ADD COLUMN published_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS upload_session_url,
+    DROP COLUMN IF EXISTS youtube_video_id;
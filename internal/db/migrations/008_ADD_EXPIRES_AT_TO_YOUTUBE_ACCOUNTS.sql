@@ -0,0 +1,6 @@
+-- +goose Up
+-- Existing rows keep a NULL expiry and are refreshed on first use
+ALTER TABLE youtube_accounts ADD COLUMN expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE youtube_accounts DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE project_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    video_path TEXT NOT NULL,
+    uploader_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum TEXT NOT NULL DEFAULT '', -- hex SHA-256 of the video
+    created_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (project_id, version)
+);
+
+ALTER TABLE projects
+    ADD COLUMN approved_version_id UUID REFERENCES project_versions(id) ON DELETE SET NULL;
+
+-- Existing projects become version 1 of themselves; size and checksum are unknown
+INSERT INTO project_versions (project_id, version, video_path, uploader_id, created_at)
+SELECT id, 1, video_path, editor_id, created_at FROM projects;
+
+UPDATE projects p
+SET approved_version_id = v.id
+FROM project_versions v
+WHERE v.project_id = p.id AND p.status = 'approved';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS approved_version_id;
+DROP TABLE IF EXISTS project_versions;
@@ -0,0 +1,29 @@
+-- +goose Up
+UPDATE projects SET status = 'submitted' WHERE status = 'pending';
+UPDATE projects SET status = 'changes_requested' WHERE status = 'rejected';
+
+ALTER TABLE projects ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE projects ADD CONSTRAINT projects_status_check CHECK (status IN (
+    'draft', 'submitted', 'in_review', 'changes_requested',
+    'approved', 'scheduled', 'published', 'archived'
+));
+
+CREATE TABLE project_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL for system actions
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX project_events_project_id_idx ON project_events (project_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_events;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ALTER COLUMN status SET DEFAULT 'pending';
+UPDATE projects SET status = 'pending' WHERE status IN ('draft', 'submitted', 'in_review');
+UPDATE projects SET status = 'rejected' WHERE status = 'changes_requested';
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE notes
+    ADD COLUMN parent_id UUID REFERENCES notes(id) ON DELETE CASCADE,
+    ADD COLUMN end_timestamp INT, -- in seconds; NULL for a single point in time
+    ADD COLUMN resolved BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN resolved_at TIMESTAMPTZ,
+    ADD COLUMN updated_at TIMESTAMPTZ DEFAULT now(),
+    ADD CONSTRAINT notes_range_check CHECK (end_timestamp IS NULL OR end_timestamp >= timestamp);
+
+CREATE INDEX notes_project_id_idx ON notes (project_id, timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS notes_project_id_idx;
+ALTER TABLE notes
+    DROP CONSTRAINT IF EXISTS notes_range_check,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS resolved_at,
+    DROP COLUMN IF EXISTS resolved_by,
+    DROP COLUMN IF EXISTS resolved,
+    DROP COLUMN IF EXISTS end_timestamp,
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE editor_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE, -- SHA-256 of the token sent to the editor
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE editor_invite_channels (
+    invite_id UUID NOT NULL REFERENCES editor_invites(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    PRIMARY KEY (invite_id, channel_id)
+);
+
+-- Audit log of invites and editor assignments
+CREATE TABLE channel_membership_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(30) NOT NULL, -- invited, invite_revoked, invite_accepted, editor_removed
+    invite_id UUID REFERENCES editor_invites(id) ON DELETE SET NULL,
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    channel_id UUID REFERENCES channels(id) ON DELETE SET NULL,
+    email TEXT,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS channel_membership_events;
+DROP TABLE IF EXISTS editor_invite_channels;
+DROP TABLE IF EXISTS editor_invites;
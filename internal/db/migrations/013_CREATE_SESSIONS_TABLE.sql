@@ -0,0 +1,19 @@
+-- +goose Up
+-- One row per login. The refresh token rotates on every use; the previous
+-- hash is kept so a replayed token can be detected.
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash TEXT NOT NULL UNIQUE,
+    previous_token_hash TEXT UNIQUE,
+    user_agent TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id) WHERE revoked_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
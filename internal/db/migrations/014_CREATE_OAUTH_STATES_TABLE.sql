@@ -0,0 +1,15 @@
+-- +goose Up
+-- Pending YouTube connections. The state sent to Google is a random nonce;
+-- only its hash is stored, together with the PKCE verifier for the exchange.
+CREATE TABLE oauth_states (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    state_hash TEXT NOT NULL UNIQUE,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS oauth_states;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Channels found when an owner connects a Google account, kept until the
+-- frontend picks them up once instead of passing them in the redirect URL.
+CREATE TABLE youtube_connections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    channels JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS youtube_connections;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE channels
+    ADD COLUMN subscriber_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN view_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN video_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN last_synced_at TIMESTAMPTZ,
+    ADD COLUMN sync_error TEXT;
+
+-- +goose Down
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS sync_error,
+    DROP COLUMN IF EXISTS last_synced_at,
+    DROP COLUMN IF EXISTS video_count,
+    DROP COLUMN IF EXISTS view_count,
+    DROP COLUMN IF EXISTS subscriber_count;
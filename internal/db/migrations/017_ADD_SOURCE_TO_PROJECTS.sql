@@ -0,0 +1,15 @@
+-- +goose Up
+-- Videos imported from a channel's uploads have no editor and no file here
+ALTER TABLE projects
+    ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'app', -- app or youtube
+    ALTER COLUMN editor_id DROP NOT NULL;
+
+CREATE UNIQUE INDEX projects_channel_youtube_video_idx
+    ON projects (channel_id, youtube_video_id) WHERE youtube_video_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS projects_channel_youtube_video_idx;
+DELETE FROM projects WHERE editor_id IS NULL;
+ALTER TABLE projects
+    ALTER COLUMN editor_id SET NOT NULL,
+    DROP COLUMN IF EXISTS source;
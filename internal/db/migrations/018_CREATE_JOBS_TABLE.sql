@@ -0,0 +1,29 @@
+-- +goose Up
+-- Background work claimed by workers with SELECT ... FOR UPDATE SKIP LOCKED
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(50) NOT NULL,
+    key TEXT, -- what the job is about, e.g. a project, so it can be cancelled or rescheduled
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'running', 'succeeded', 'cancelled', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_at TIMESTAMPTZ,
+    locked_by TEXT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX jobs_queued_run_at_idx ON jobs (run_at) WHERE status = 'queued';
+-- At most one pending job per key
+CREATE UNIQUE INDEX jobs_active_key_idx ON jobs (key) WHERE status IN ('queued', 'running');
+
+-- When a scheduled project goes live on YouTube
+ALTER TABLE projects ADD COLUMN publish_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS publish_at;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- What a project is published to YouTube with besides its title and description
+CREATE TABLE project_metadata (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    category_id VARCHAR(10) NOT NULL DEFAULT '22', -- People & Blogs
+    default_language VARCHAR(35),
+    made_for_kids BOOLEAN NOT NULL DEFAULT false,
+    license VARCHAR(20) NOT NULL DEFAULT 'youtube'
+        CHECK (license IN ('youtube', 'creativeCommon')),
+    playlist_ids TEXT[] NOT NULL DEFAULT '{}',
+    thumbnail_path TEXT, -- custom thumbnail in video storage
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_metadata;
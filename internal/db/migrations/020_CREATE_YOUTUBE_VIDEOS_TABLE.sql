@@ -0,0 +1,18 @@
+-- +goose Up
+-- The last copy of each published video's metadata that YouTube and the
+-- dashboard agreed on, so changes on either side can be told apart
+CREATE TABLE youtube_videos (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    youtube_video_id VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    category_id VARCHAR(10),
+    privacy_status VARCHAR(20),
+    etag TEXT,
+    conflict JSONB, -- YouTube's values that clash with dashboard edits, NULL when in sync
+    synced_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS youtube_videos;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Daily snapshots of YouTube's counters; a later snapshot on the same day
+-- replaces the earlier one
+CREATE TABLE channel_stats (
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    captured_on DATE NOT NULL,
+    subscriber_count BIGINT NOT NULL DEFAULT 0,
+    view_count BIGINT NOT NULL DEFAULT 0,
+    video_count BIGINT NOT NULL DEFAULT 0,
+    captured_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (channel_id, captured_on)
+);
+
+CREATE TABLE video_stats (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    captured_on DATE NOT NULL,
+    youtube_video_id VARCHAR(20) NOT NULL,
+    view_count BIGINT NOT NULL DEFAULT 0,
+    like_count BIGINT NOT NULL DEFAULT 0,
+    comment_count BIGINT NOT NULL DEFAULT 0,
+    captured_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (project_id, captured_on)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS video_stats;
+DROP TABLE IF EXISTS channel_stats;
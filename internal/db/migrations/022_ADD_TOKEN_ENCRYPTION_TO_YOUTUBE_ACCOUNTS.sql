@@ -0,0 +1,11 @@
+-- +goose Up
+-- Existing rows keep a NULL key ID and plaintext tokens until the server
+-- seals them at startup (see service.SealPlaintextTokens)
+ALTER TABLE youtube_accounts
+    ADD COLUMN token_key_id TEXT,
+    ADD COLUMN token_data_key BYTEA;
+
+-- +goose Down
+ALTER TABLE youtube_accounts
+    DROP COLUMN IF EXISTS token_data_key,
+    DROP COLUMN IF EXISTS token_key_id;
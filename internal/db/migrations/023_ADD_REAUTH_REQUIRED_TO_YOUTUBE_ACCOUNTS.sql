@@ -0,0 +1,7 @@
+-- +goose Up
+-- Set when Google rejects the refresh token with invalid_grant, cleared
+-- when the owner reconnects the account
+ALTER TABLE youtube_accounts ADD COLUMN reauth_required_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE youtube_accounts DROP COLUMN IF EXISTS reauth_required_at;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- Organizations several owners can share, with the editors working for them
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX workspace_members_user_id_idx ON workspace_members (user_id);
+
+-- Channels, and through them projects, belong to one workspace
+ALTER TABLE channels ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+-- Every existing owner gets a workspace of their own holding their channels,
+-- administered by them and shared with the editors of those channels
+INSERT INTO workspaces (name, created_by, created_at)
+SELECT u.name || '''s workspace', u.id, now()
+FROM users u
+WHERE u.role = 'owner' OR EXISTS (SELECT 1 FROM channels c WHERE c.owner_id = u.id);
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT w.id, w.created_by, 'admin' FROM workspaces w;
+
+UPDATE channels c SET workspace_id = w.id
+FROM workspaces w
+WHERE w.created_by = c.owner_id;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT DISTINCT c.workspace_id, ec.editor_id, 'editor'
+FROM editors_channels ec
+JOIN channels c ON ec.channel_id = c.id
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE channels ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX channels_workspace_id_idx ON channels (workspace_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS channels_workspace_id_idx;
+ALTER TABLE channels DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Channel members other than the owner get a role granting a set of
+-- permissions (see service.rolePermissions). Existing assignments keep
+-- working as editors.
+ALTER TABLE editors_channels ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'editor'
+    CHECK (role IN ('manager', 'reviewer', 'editor', 'viewer'));
+
+-- Signup used to store whatever role the client sent; anything other than
+-- owner or editor was never usable and becomes an editor account
+UPDATE users SET role = 'editor' WHERE role NOT IN ('owner', 'editor');
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('owner', 'editor'));
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE editors_channels DROP COLUMN IF EXISTS role;
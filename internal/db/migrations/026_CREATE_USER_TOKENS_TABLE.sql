@@ -0,0 +1,25 @@
+-- +goose Up
+-- Accounts log in once their email address is verified. Accounts created
+-- before verification existed are trusted as they are.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+UPDATE users SET email_verified_at = COALESCE(created_at, now());
+
+-- Single-use links mailed to users: email verification and password reset.
+-- Only the token's hash is stored. email is the address a verification
+-- token was sent to, so changing it again invalidates the link.
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash TEXT NOT NULL UNIQUE,
+    email TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX user_tokens_user_id_idx ON user_tokens (user_id, purpose) WHERE used_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Set while a Publish call uploads the project, so a second one cannot
+-- upload it again; expires in case the server dies mid-upload
+ALTER TABLE projects ADD COLUMN publishing_until TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS publishing_until;
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ChannelStat is a channel's YouTube counters on one day
+type ChannelStat struct {
+	ChannelID       string    `db:"channel_id" json:"channel_id"`
+	CapturedOn      time.Time `db:"captured_on" json:"captured_on"` // UTC midnight
+	SubscriberCount int64     `db:"subscriber_count" json:"subscriber_count"`
+	ViewCount       int64     `db:"view_count" json:"view_count"`
+	VideoCount      int64     `db:"video_count" json:"video_count"`
+	CapturedAt      time.Time `db:"captured_at" json:"captured_at"`
+}
+
+// VideoStat is a published project's YouTube counters on one day
+type VideoStat struct {
+	ProjectID    string    `db:"project_id" json:"project_id"`
+	CapturedOn   time.Time `db:"captured_on" json:"captured_on"` // UTC midnight
+	YouTubeID    string    `db:"youtube_video_id" json:"youtube_video_id"`
+	ViewCount    int64     `db:"view_count" json:"view_count"`
+	LikeCount    int64     `db:"like_count" json:"like_count"`
+	CommentCount int64     `db:"comment_count" json:"comment_count"`
+	CapturedAt   time.Time `db:"captured_at" json:"captured_at"`
+}
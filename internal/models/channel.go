@@ -5,10 +5,18 @@ import "time"
 type Channel struct {
 	ID               string    `db:"id"`
 	OwnerID          string    `db:"owner_id"`
+	WorkspaceID      string    `db:"workspace_id"`
 	YouTubeAccountID string    `db:"youtube_account_id"`
 	YtChannelID      string    `db:"yt_channel_id"`
 	Name             string    `db:"name"`
 	IconURL          string    `db:"icon_url"`
 	Email            string    `db:"email"`
 	CreatedAt        time.Time `db:"created_at"`
+
+	// Refreshed from YouTube by the channel sync
+	SubscriberCount int64      `db:"subscriber_count"`
+	ViewCount       int64      `db:"view_count"`
+	VideoCount      int64      `db:"video_count"`
+	LastSyncedAt    *time.Time `db:"last_synced_at"`
+	SyncError       string     `db:"sync_error"` // why the last sync failed, empty if it worked
 }
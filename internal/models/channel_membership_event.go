@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ChannelMembershipEvent struct {
+	ID        string    `db:"id"`
+	ActorID   string    `db:"actor_id"`
+	Action    string    `db:"action"` // invited, invite_revoked, invite_accepted, editor_removed, role_changed
+	InviteID  string    `db:"invite_id"`
+	EditorID  string    `db:"editor_id"`
+	ChannelID string    `db:"channel_id"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
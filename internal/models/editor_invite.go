@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type EditorInvite struct {
+	ID         string     `db:"id" json:"id"`
+	OwnerID    string     `db:"owner_id" json:"owner_id"`
+	Email      string     `db:"email" json:"email"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	AcceptedBy *string    `db:"accepted_by" json:"accepted_by,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work in the jobs table
+type Job struct {
+	ID          string          `db:"id" json:"id"`
+	Kind        string          `db:"kind" json:"kind"`
+	Key         string          `db:"key" json:"key,omitempty"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	Status      string          `db:"status" json:"status"` // queued, running, succeeded, cancelled, dead
+	Attempts    int             `db:"attempts" json:"attempts"`
+	MaxAttempts int             `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time       `db:"run_at" json:"run_at"`
+	LockedAt    *time.Time      `db:"locked_at" json:"locked_at,omitempty"`
+	LockedBy    string          `db:"locked_by" json:"locked_by,omitempty"`
+	LastError   string          `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
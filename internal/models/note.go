@@ -3,9 +3,17 @@ package models
 import "time"
 
 type Note struct {
-	ID        string    `db:"id" json:"id"`
-	ProjectID string    `db:"project_id" json:"project_id"`
-	Timestamp int       `db:"timestamp" json:"timestamp"` // seconds
-	Content   string    `db:"content" json:"content"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID           string     `db:"id" json:"id"`
+	ProjectID    string     `db:"project_id" json:"project_id"`
+	UserID       string     `db:"user_id" json:"user_id"`
+	ParentID     *string    `db:"parent_id" json:"parent_id,omitempty"` // set on replies
+	Timestamp    int        `db:"timestamp" json:"timestamp"`           // seconds, start of the range
+	EndTimestamp *int       `db:"end_timestamp" json:"end_timestamp,omitempty"`
+	Content      string     `db:"content" json:"content"`
+	Resolved     bool       `db:"resolved" json:"resolved"`
+	ResolvedBy   *string    `db:"resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+	AuthorName   string     `db:"-" json:"author_name"` // users.name of UserID
 }
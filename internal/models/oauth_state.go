@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// OAuthState is a pending YouTube connection, waiting for Google's callback
+type OAuthState struct {
+	ID           string     `db:"id"`
+	UserID       string     `db:"user_id"`
+	StateHash    string     `db:"state_hash"`
+	CodeVerifier string     `db:"code_verifier"` // PKCE verifier sent with the code exchange
+	ExpiresAt    time.Time  `db:"expires_at"`
+	UsedAt       *time.Time `db:"used_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
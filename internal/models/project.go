@@ -3,14 +3,36 @@ package models
 import "time"
 
 type Project struct {
-	ID          string    `db:"id" json:"id"`
-	Title       string    `db:"title" json:"title"`
-	Description string    `db:"description" json:"description"`
-	VideoPath   string    `db:"video_path" json:"video_path"`
-	Status      string    `db:"status" json:"status"` // pending, approved, rejected
-	EditorID    string    `db:"editor_id" json:"editor_id"`
-	OwnerID     string    `db:"owner_id" json:"owner_id"`
-	ChannelID   string    `db:"channel_id" json:"channel_id"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID                string     `db:"id" json:"id"`
+	Title             string     `db:"title" json:"title"`
+	Description       string     `db:"description" json:"description"`
+	VideoPath         string     `db:"video_path" json:"video_path"`
+	Status            string     `db:"status" json:"status"`       // draft, submitted, in_review, ... (see service.ProjectStatus)
+	EditorID          string     `db:"editor_id" json:"editor_id"` // empty for imported videos
+	OwnerID           string     `db:"owner_id" json:"owner_id"`
+	ChannelID         string     `db:"channel_id" json:"channel_id"`
+	YouTubeID         string     `db:"youtube_video_id" json:"youtube_video_id"`
+	ApprovedVersionID string     `db:"approved_version_id" json:"approved_version_id"` // version the owner approved
+	UploadSessionURL  string     `db:"upload_session_url" json:"-"`                    // resumable YouTube upload in progress
+	PublishingUntil   *time.Time `db:"publishing_until" json:"-"`                      // a Publish call is uploading the video
+	PublishedAt       *time.Time `db:"published_at" json:"published_at,omitempty"`
+	PublishAt         *time.Time `db:"publish_at" json:"publish_at,omitempty"` // when a scheduled project goes live
+	Source            string     `db:"source" json:"source"`                   // app, or youtube for read-only imported videos
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// ProjectMetadata is what a project is published to YouTube with besides its
+// title and description
+type ProjectMetadata struct {
+	ProjectID       string    `db:"project_id" json:"project_id"`
+	Tags            []string  `db:"tags" json:"tags"`
+	CategoryID      string    `db:"category_id" json:"category_id"` // YouTube video category
+	DefaultLanguage string    `db:"default_language" json:"default_language,omitempty"`
+	MadeForKids     bool      `db:"made_for_kids" json:"made_for_kids"`
+	License         string    `db:"license" json:"license"` // youtube or creativeCommon
+	PlaylistIDs     []string  `db:"playlist_ids" json:"playlist_ids"`
+	ThumbnailPath   string    `db:"thumbnail_path" json:"-"`
+	UpdatedBy       string    `db:"updated_by" json:"updated_by,omitempty"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
 }
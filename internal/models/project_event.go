@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ProjectEvent struct {
+	ID         string    `db:"id" json:"id"`
+	ProjectID  string    `db:"project_id" json:"project_id"`
+	ActorID    string    `db:"actor_id" json:"actor_id,omitempty"` // empty when the system acted
+	ActorName  string    `db:"-" json:"actor_name,omitempty"`
+	FromStatus string    `db:"from_status" json:"from_status,omitempty"`
+	ToStatus   string    `db:"to_status" json:"to_status"`
+	Reason     string    `db:"reason" json:"reason,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
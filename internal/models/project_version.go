@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ProjectVersion struct {
+	ID         string    `db:"id" json:"id"`
+	ProjectID  string    `db:"project_id" json:"project_id"`
+	Version    int       `db:"version" json:"version"`
+	VideoPath  string    `db:"video_path" json:"video_path"`
+	UploaderID string    `db:"uploader_id" json:"uploader_id"`
+	SizeBytes  int64     `db:"size_bytes" json:"size_bytes"`
+	Checksum   string    `db:"checksum" json:"checksum"` // hex SHA-256
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Session is one login, kept alive by its rotating refresh token
+type Session struct {
+	ID                string     `db:"id" json:"id"`
+	UserID            string     `db:"user_id" json:"user_id"`
+	RefreshTokenHash  string     `db:"refresh_token_hash" json:"-"`
+	PreviousTokenHash string     `db:"previous_token_hash" json:"-"`
+	UserAgent         string     `db:"user_agent" json:"user_agent,omitempty"`
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`
+	LastUsedAt        *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt         *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
@@ -3,10 +3,11 @@ package models
 import "time"
 
 type User struct {
-	ID           string    `db:"id"`
-	Name         string    `db:"name"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	Role         string    `db:"role"`
-	CreatedAt    time.Time `db:"created_at"`
+	ID              string     `db:"id"`
+	Name            string     `db:"name"`
+	Email           string     `db:"email"`
+	PasswordHash    string     `db:"password_hash"`
+	Role            string     `db:"role"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"` // nil until the address is confirmed
+	CreatedAt       time.Time  `db:"created_at"`
 }
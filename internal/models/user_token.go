@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserToken is a single-use link mailed to a user
+type UserToken struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	Purpose   string     `db:"purpose"` // verify_email or reset_password
+	TokenHash string     `db:"token_hash"`
+	Email     string     `db:"email"` // address the token was sent to
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Workspace is an organization whose members share channels and projects
+type Workspace struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedBy string    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string    `db:"workspace_id" json:"workspace_id"`
+	UserID      string    `db:"user_id" json:"user_id"`
+	Role        string    `db:"role" json:"role"` // admin, owner, editor or viewer
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
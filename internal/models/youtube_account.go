@@ -2,12 +2,21 @@ package models
 
 import "time"
 
+// YouTubeAccount is a Google account an owner connected. Its tokens are
+// sealed by the service layer; TokenKeyID is empty for rows that still hold
+// plaintext tokens from before encryption.
 type YouTubeAccount struct {
-	ID           string    `db:"id"`
-	UserID       string    `db:"user_id"`
-	Email        string    `db:"email"`
-	AccessToken  string    `db:"access_token"`
-	RefreshToken string    `db:"refresh_token"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID           string     `db:"id"`
+	UserID       string     `db:"user_id"`
+	Email        string     `db:"email"`
+	AccessToken  string     `db:"access_token"`
+	RefreshToken string     `db:"refresh_token"`
+	TokenKeyID   string     `db:"token_key_id"`
+	TokenDataKey []byte     `db:"token_data_key"`
+	ExpiresAt    *time.Time `db:"expires_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+
+	// ReauthRequiredAt is when Google last rejected the refresh token
+	ReauthRequiredAt *time.Time `db:"reauth_required_at"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// YouTubeConnection holds the channels discovered by one OAuth callback until
+// the frontend fetches them
+type YouTubeConnection struct {
+	ID         string          `db:"id"`
+	UserID     string          `db:"user_id"`
+	Email      string          `db:"email"`
+	Channels   json.RawMessage `db:"channels"`
+	ExpiresAt  time.Time       `db:"expires_at"`
+	ConsumedAt *time.Time      `db:"consumed_at"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// YouTubeVideo is the last copy of a published project's snippet and status
+// that YouTube and the dashboard agreed on
+type YouTubeVideo struct {
+	ProjectID     string          `db:"project_id" json:"project_id"`
+	YouTubeID     string          `db:"youtube_video_id" json:"youtube_video_id"`
+	Title         string          `db:"title" json:"title"`
+	Description   string          `db:"description" json:"description"`
+	Tags          []string        `db:"tags" json:"tags"`
+	CategoryID    string          `db:"category_id" json:"category_id"`
+	PrivacyStatus string          `db:"privacy_status" json:"privacy_status"`
+	ETag          string          `db:"etag" json:"-"`
+	Conflict      json.RawMessage `db:"conflict" json:"conflict,omitempty"` // see service.VideoConflict
+	SyncedAt      time.Time       `db:"synced_at" json:"synced_at"`
+}
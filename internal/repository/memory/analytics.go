@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type channelDay struct {
+	channelID string
+	day       time.Time
+}
+
+type projectDay struct {
+	projectID string
+	day       time.Time
+}
+
+type analyticsRepo struct {
+	s *Store
+}
+
+func (r *analyticsRepo) SaveChannelStat(ctx context.Context, s *models.ChannelStat) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.channels[s.ChannelID]; !ok {
+			return repository.ErrNotFound
+		}
+		d.channelStats[channelDay{s.ChannelID, s.CapturedOn.UTC()}] = *s
+		return nil
+	})
+}
+
+func (r *analyticsRepo) SaveVideoStat(ctx context.Context, s *models.VideoStat) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.projects[s.ProjectID]; !ok {
+			return repository.ErrNotFound
+		}
+		d.videoStats[projectDay{s.ProjectID, s.CapturedOn.UTC()}] = *s
+		return nil
+	})
+}
+
+func inRange(day, from, to time.Time) bool {
+	return !day.Before(from) && !day.After(to)
+}
+
+func (r *analyticsRepo) ListChannelStats(ctx context.Context, channelID string, from, to time.Time) ([]models.ChannelStat, error) {
+	stats := []models.ChannelStat{}
+	err := r.s.locked(func(d *data) error {
+		for key, s := range d.channelStats {
+			if key.channelID == channelID && inRange(key.day, from, to) {
+				stats = append(stats, s)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(stats, func(a, b models.ChannelStat) int { return a.CapturedOn.Compare(b.CapturedOn) })
+	return stats, err
+}
+
+func (r *analyticsRepo) ListVideoStats(ctx context.Context, projectID string, from, to time.Time) ([]models.VideoStat, error) {
+	return r.listVideoStats(func(d *data, p string) bool { return p == projectID }, from, to)
+}
+
+func (r *analyticsRepo) ListChannelVideoStats(ctx context.Context, channelID string, from, to time.Time) ([]models.VideoStat, error) {
+	return r.listVideoStats(func(d *data, p string) bool { return d.projects[p].ChannelID == channelID }, from, to)
+}
+
+func (r *analyticsRepo) listVideoStats(match func(d *data, projectID string) bool, from, to time.Time) ([]models.VideoStat, error) {
+	stats := []models.VideoStat{}
+	err := r.s.locked(func(d *data) error {
+		for key, s := range d.videoStats {
+			if match(d, key.projectID) && inRange(key.day, from, to) {
+				stats = append(stats, s)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(stats, func(a, b models.VideoStat) int {
+		if c := a.CapturedOn.Compare(b.CapturedOn); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ProjectID, b.ProjectID)
+	})
+	return stats, err
+}
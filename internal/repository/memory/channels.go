@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type channelRepo struct {
+	s *Store
+}
+
+func sortChannels(channels []models.Channel) []models.Channel {
+	slices.SortFunc(channels, func(a, b models.Channel) int { return strings.Compare(a.Name, b.Name) })
+	return channels
+}
+
+func (r *channelRepo) Create(ctx context.Context, ch *models.Channel) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.channels {
+			if existing.OwnerID == ch.OwnerID && existing.YtChannelID == ch.YtChannelID {
+				return repository.ErrDuplicate
+			}
+		}
+		d.channels[ch.ID] = *ch
+		return nil
+	})
+}
+
+func (r *channelRepo) Get(ctx context.Context, id string) (*models.Channel, error) {
+	var ch *models.Channel
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.channels[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		ch = &found
+		return nil
+	})
+	return ch, err
+}
+
+func (r *channelRepo) ListByOwner(ctx context.Context, ownerID string) ([]models.Channel, error) {
+	channels := []models.Channel{}
+	err := r.s.locked(func(d *data) error {
+		for _, ch := range d.channels {
+			if ch.OwnerID == ownerID {
+				channels = append(channels, ch)
+			}
+		}
+		return nil
+	})
+	return sortChannels(channels), err
+}
+
+func (r *channelRepo) ListByWorkspace(ctx context.Context, workspaceID string) ([]models.Channel, error) {
+	channels := []models.Channel{}
+	err := r.s.locked(func(d *data) error {
+		for _, ch := range d.channels {
+			if ch.WorkspaceID == workspaceID {
+				channels = append(channels, ch)
+			}
+		}
+		return nil
+	})
+	return sortChannels(channels), err
+}
+
+func (r *channelRepo) ListAll(ctx context.Context) ([]models.Channel, error) {
+	channels := []models.Channel{}
+	err := r.s.locked(func(d *data) error {
+		for _, ch := range d.channels {
+			channels = append(channels, ch)
+		}
+		return nil
+	})
+	slices.SortFunc(channels, func(a, b models.Channel) int {
+		if c := strings.Compare(a.YouTubeAccountID, b.YouTubeAccountID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return channels, err
+}
+
+func (r *channelRepo) SaveSync(ctx context.Context, ch *models.Channel) error {
+	return r.s.locked(func(d *data) error {
+		existing, ok := d.channels[ch.ID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		existing.Name, existing.IconURL = ch.Name, ch.IconURL
+		existing.SubscriberCount, existing.ViewCount, existing.VideoCount = ch.SubscriberCount, ch.ViewCount, ch.VideoCount
+		existing.LastSyncedAt, existing.SyncError = ch.LastSyncedAt, ch.SyncError
+		d.channels[ch.ID] = existing
+		return nil
+	})
+}
+
+func (r *channelRepo) ListByEditor(ctx context.Context, editorID string) ([]models.Channel, error) {
+	channels := []models.Channel{}
+	err := r.s.locked(func(d *data) error {
+		for _, ch := range d.channels {
+			if d.editors[editorKey{ch.ID, editorID}] != "" {
+				channels = append(channels, ch)
+			}
+		}
+		return nil
+	})
+	return sortChannels(channels), err
+}
+
+func (r *channelRepo) DeleteByYtChannelID(ctx context.Context, ownerID, ytChannelID string) error {
+	return r.s.locked(func(d *data) error {
+		for id, ch := range d.channels {
+			if ch.OwnerID == ownerID && ch.YtChannelID == ytChannelID {
+				delete(d.channels, id)
+				for k := range d.editors {
+					if k.channelID == id {
+						delete(d.editors, k)
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *channelRepo) SetYouTubeAccount(ctx context.Context, id, accountID string) error {
+	return r.s.locked(func(d *data) error {
+		ch, ok := d.channels[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		ch.YouTubeAccountID = accountID
+		d.channels[id] = ch
+		return nil
+	})
+}
+
+func (r *channelRepo) DetachYouTubeAccount(ctx context.Context, accountID string) error {
+	return r.s.locked(func(d *data) error {
+		for id, ch := range d.channels {
+			if ch.YouTubeAccountID == accountID {
+				ch.YouTubeAccountID = ""
+				d.channels[id] = ch
+			}
+		}
+		return nil
+	})
+}
+
+func (r *channelRepo) EditorRole(ctx context.Context, channelID, editorID string) (string, error) {
+	var role string
+	err := r.s.locked(func(d *data) error {
+		role = d.editors[editorKey{channelID, editorID}]
+		if role == "" {
+			return repository.ErrNotFound
+		}
+		return nil
+	})
+	return role, err
+}
+
+func (r *channelRepo) AddEditor(ctx context.Context, channelID, editorID string) (bool, error) {
+	var added bool
+	err := r.s.locked(func(d *data) error {
+		k := editorKey{channelID, editorID}
+		if d.editors[k] == "" {
+			d.editors[k] = "editor"
+			added = true
+		}
+		return nil
+	})
+	return added, err
+}
+
+func (r *channelRepo) SetEditorRole(ctx context.Context, channelID, editorID, role string) error {
+	return r.s.locked(func(d *data) error {
+		k := editorKey{channelID, editorID}
+		if d.editors[k] == "" {
+			return repository.ErrNotFound
+		}
+		d.editors[k] = role
+		return nil
+	})
+}
+
+func (r *channelRepo) ListEditors(ctx context.Context, channelID string) ([]repository.ChannelEditor, error) {
+	editors := []repository.ChannelEditor{}
+	err := r.s.locked(func(d *data) error {
+		for k, role := range d.editors {
+			if k.channelID == channelID {
+				editors = append(editors, repository.ChannelEditor{User: d.users[k.editorID], Role: role})
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(editors, func(a, b repository.ChannelEditor) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Email, b.Email))
+	})
+	return editors, err
+}
+
+func (r *channelRepo) RemoveEditor(ctx context.Context, channelID, editorID string) error {
+	return r.s.locked(func(d *data) error {
+		k := editorKey{channelID, editorID}
+		if d.editors[k] == "" {
+			return repository.ErrNotFound
+		}
+		delete(d.editors, k)
+		return nil
+	})
+}
+
+func (r *channelRepo) ListEditorsOfOwner(ctx context.Context, ownerID string) ([]models.User, error) {
+	return r.partners(func(d *data, k editorKey) (string, bool) {
+		return k.editorID, d.channels[k.channelID].OwnerID == ownerID
+	})
+}
+
+func (r *channelRepo) ListOwnersOfEditor(ctx context.Context, editorID string) ([]models.User, error) {
+	return r.partners(func(d *data, k editorKey) (string, bool) {
+		return d.channels[k.channelID].OwnerID, k.editorID == editorID
+	})
+}
+
+// partners collects the distinct users pick selects from the editor assignments
+func (r *channelRepo) partners(pick func(d *data, k editorKey) (string, bool)) ([]models.User, error) {
+	users := []models.User{}
+	err := r.s.locked(func(d *data) error {
+		seen := make(map[string]bool)
+		for k := range d.editors {
+			id, ok := pick(d, k)
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			if u, ok := d.users[id]; ok {
+				users = append(users, u)
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func (r *channelRepo) RecordMembershipEvent(ctx context.Context, e *models.ChannelMembershipEvent) error {
+	return r.s.locked(func(d *data) error {
+		d.membershipEvents = append(d.membershipEvents, *e)
+		return nil
+	})
+}
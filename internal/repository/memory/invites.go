@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type inviteRepo struct {
+	s *Store
+}
+
+func pending(inv models.EditorInvite) bool {
+	return inv.AcceptedAt == nil && inv.RevokedAt == nil && inv.ExpiresAt.After(time.Now())
+}
+
+func (r *inviteRepo) details(d *data, inv models.EditorInvite) repository.InviteDetails {
+	det := repository.InviteDetails{EditorInvite: inv, OwnerName: d.users[inv.OwnerID].Name}
+	for _, id := range d.inviteChannels[inv.ID] {
+		det.Channels = append(det.Channels, d.channels[id])
+	}
+	sortChannels(det.Channels)
+	return det
+}
+
+func (r *inviteRepo) Create(ctx context.Context, inv *models.EditorInvite, channelIDs []string) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.invites {
+			if existing.TokenHash == inv.TokenHash {
+				return repository.ErrDuplicate
+			}
+		}
+		d.invites[inv.ID] = *inv
+		d.inviteChannels[inv.ID] = slices.Clone(channelIDs)
+		return nil
+	})
+}
+
+func (r *inviteRepo) ListPending(ctx context.Context, ownerID string) ([]repository.InviteDetails, error) {
+	invites := []repository.InviteDetails{}
+	err := r.s.locked(func(d *data) error {
+		for _, inv := range d.invites {
+			if inv.OwnerID == ownerID && pending(inv) {
+				invites = append(invites, r.details(d, inv))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(invites, func(a, b repository.InviteDetails) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return invites, err
+}
+
+func (r *inviteRepo) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*repository.InviteDetails, error) {
+	var det *repository.InviteDetails
+	err := r.s.locked(func(d *data) error {
+		for _, inv := range d.invites {
+			if inv.TokenHash == tokenHash && pending(inv) {
+				found := r.details(d, inv)
+				det = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return det, err
+}
+
+func (r *inviteRepo) Revoke(ctx context.Context, ownerID, id string) (*models.EditorInvite, error) {
+	var inv *models.EditorInvite
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.invites[id]
+		if !ok || found.OwnerID != ownerID || found.AcceptedAt != nil || found.RevokedAt != nil {
+			return repository.ErrNotFound
+		}
+		now := time.Now()
+		found.RevokedAt = &now
+		d.invites[id] = found
+		inv = &found
+		return nil
+	})
+	return inv, err
+}
+
+func (r *inviteRepo) MarkAccepted(ctx context.Context, id, userID string) error {
+	return r.s.locked(func(d *data) error {
+		inv, ok := d.invites[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		now := time.Now()
+		inv.AcceptedAt, inv.AcceptedBy = &now, &userID
+		d.invites[id] = inv
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type jobRepo struct {
+	s *Store
+}
+
+func activeJob(j models.Job) bool {
+	return j.Status == "queued" || j.Status == "running"
+}
+
+func (r *jobRepo) Enqueue(ctx context.Context, j *models.Job) error {
+	return r.s.locked(func(d *data) error {
+		if j.Key != "" {
+			for _, existing := range d.jobs {
+				if existing.Key == j.Key && activeJob(existing) {
+					return repository.ErrDuplicate
+				}
+			}
+		}
+		stored := *j
+		stored.Status, stored.Attempts, stored.UpdatedAt = "queued", 0, j.CreatedAt
+		d.jobs[j.ID] = stored
+		return nil
+	})
+}
+
+func (r *jobRepo) Claim(ctx context.Context, workerID string, staleBefore time.Time) (*models.Job, error) {
+	var claimed *models.Job
+	err := r.s.locked(func(d *data) error {
+		now := time.Now()
+		for _, j := range d.jobs {
+			due := (j.Status == "queued" && !j.RunAt.After(now)) ||
+				(j.Status == "running" && j.LockedAt != nil && j.LockedAt.Before(staleBefore))
+			if due && (claimed == nil || j.RunAt.Before(claimed.RunAt)) {
+				found := j
+				claimed = &found
+			}
+		}
+		if claimed == nil {
+			return repository.ErrNotFound
+		}
+		claimed.Status, claimed.Attempts = "running", claimed.Attempts+1
+		claimed.LockedAt, claimed.LockedBy, claimed.UpdatedAt = &now, workerID, now
+		d.jobs[claimed.ID] = *claimed
+		return nil
+	})
+	return claimed, err
+}
+
+// finish applies fn to the job with this ID if workerID is running it
+func (r *jobRepo) finish(id, workerID string, fn func(j *models.Job)) error {
+	return r.s.locked(func(d *data) error {
+		j, ok := d.jobs[id]
+		if !ok || j.Status != "running" || j.LockedBy != workerID {
+			return repository.ErrNotFound
+		}
+		fn(&j)
+		j.UpdatedAt = time.Now()
+		d.jobs[id] = j
+		return nil
+	})
+}
+
+func (r *jobRepo) Complete(ctx context.Context, id, workerID string) error {
+	return r.finish(id, workerID, func(j *models.Job) {
+		j.Status, j.LockedAt, j.LastError = "succeeded", nil, ""
+	})
+}
+
+func (r *jobRepo) Retry(ctx context.Context, id, workerID string, runAt time.Time, lastErr string) error {
+	return r.finish(id, workerID, func(j *models.Job) {
+		j.Status, j.RunAt, j.LockedAt, j.LastError = "queued", runAt, nil, lastErr
+	})
+}
+
+func (r *jobRepo) Bury(ctx context.Context, id, workerID string, lastErr string) error {
+	return r.finish(id, workerID, func(j *models.Job) {
+		j.Status, j.LockedAt, j.LastError = "dead", nil, lastErr
+	})
+}
+
+// byKey runs fn on the active job with this key, if there is one in one of statuses
+func (r *jobRepo) byKey(key string, statuses []string, fn func(j *models.Job)) (*models.Job, error) {
+	var found *models.Job
+	err := r.s.locked(func(d *data) error {
+		for id, j := range d.jobs {
+			if j.Key != key {
+				continue
+			}
+			for _, status := range statuses {
+				if j.Status == status {
+					fn(&j)
+					d.jobs[id] = j
+					found = &j
+					return nil
+				}
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return found, err
+}
+
+func (r *jobRepo) GetActiveByKey(ctx context.Context, key string) (*models.Job, error) {
+	return r.byKey(key, []string{"queued", "running"}, func(*models.Job) {})
+}
+
+func (r *jobRepo) CancelByKey(ctx context.Context, key string) error {
+	_, err := r.byKey(key, []string{"queued"}, func(j *models.Job) {
+		j.Status, j.UpdatedAt = "cancelled", time.Now()
+	})
+	return err
+}
+
+func (r *jobRepo) RescheduleByKey(ctx context.Context, key string, runAt time.Time) error {
+	_, err := r.byKey(key, []string{"queued"}, func(j *models.Job) {
+		j.RunAt, j.UpdatedAt = runAt, time.Now()
+	})
+	return err
+}
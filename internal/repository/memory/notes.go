@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type noteRepo struct {
+	s *Store
+}
+
+func (r *noteRepo) Create(ctx context.Context, n *models.Note) error {
+	return r.s.locked(func(d *data) error {
+		d.notes[n.ID] = *n
+		return nil
+	})
+}
+
+func (r *noteRepo) Get(ctx context.Context, projectID, id string) (*models.Note, error) {
+	var n *models.Note
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.notes[id]
+		if !ok || found.ProjectID != projectID {
+			return repository.ErrNotFound
+		}
+		found.AuthorName = d.users[found.UserID].Name
+		n = &found
+		return nil
+	})
+	return n, err
+}
+
+func (r *noteRepo) List(ctx context.Context, projectID string) ([]models.Note, error) {
+	notes := []models.Note{}
+	err := r.s.locked(func(d *data) error {
+		for _, n := range d.notes {
+			if n.ProjectID == projectID {
+				n.AuthorName = d.users[n.UserID].Name
+				notes = append(notes, n)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(notes, func(a, b models.Note) int {
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp - b.Timestamp
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return notes, err
+}
+
+func (r *noteRepo) Update(ctx context.Context, n *models.Note) error {
+	return r.s.locked(func(d *data) error {
+		existing, ok := d.notes[n.ID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		existing.Content, existing.Timestamp, existing.EndTimestamp = n.Content, n.Timestamp, n.EndTimestamp
+		existing.Resolved, existing.ResolvedBy, existing.ResolvedAt = n.Resolved, n.ResolvedBy, n.ResolvedAt
+		existing.UpdatedAt = n.UpdatedAt
+		d.notes[n.ID] = existing
+		return nil
+	})
+}
+
+func (r *noteRepo) MoveReplies(ctx context.Context, parentID string, timestamp int, endTimestamp *int) error {
+	return r.s.locked(func(d *data) error {
+		for id, n := range d.notes {
+			if n.ParentID != nil && *n.ParentID == parentID {
+				n.Timestamp, n.EndTimestamp = timestamp, endTimestamp
+				d.notes[id] = n
+			}
+		}
+		return nil
+	})
+}
+
+func (r *noteRepo) Delete(ctx context.Context, id string) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.notes[id]; !ok {
+			return repository.ErrNotFound
+		}
+		delete(d.notes, id)
+		for replyID, n := range d.notes {
+			if n.ParentID != nil && *n.ParentID == id {
+				delete(d.notes, replyID)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type oauthStateRepo struct {
+	s *Store
+}
+
+func (r *oauthStateRepo) Create(ctx context.Context, st *models.OAuthState) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.oauthStates {
+			if existing.StateHash == st.StateHash {
+				return repository.ErrDuplicate
+			}
+		}
+		d.oauthStates[st.ID] = *st
+		return nil
+	})
+}
+
+func (r *oauthStateRepo) Consume(ctx context.Context, stateHash string) (*models.OAuthState, error) {
+	var st *models.OAuthState
+	err := r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, found := range d.oauthStates {
+			if found.StateHash == stateHash && found.UsedAt == nil && found.ExpiresAt.After(now) {
+				found.UsedAt = &now
+				d.oauthStates[id] = found
+				st = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return st, err
+}
+
+func (r *oauthStateRepo) DeleteExpired(ctx context.Context) error {
+	return r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, st := range d.oauthStates {
+			if st.ExpiresAt.Before(now) {
+				delete(d.oauthStates, id)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type projectRepo struct {
+	s *Store
+}
+
+func (r *projectRepo) Create(ctx context.Context, p *models.Project) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.projects[p.ID]; ok {
+			return repository.ErrDuplicate
+		}
+		for _, existing := range d.projects {
+			if p.YouTubeID != "" && existing.ChannelID == p.ChannelID && existing.YouTubeID == p.YouTubeID {
+				return repository.ErrDuplicate
+			}
+		}
+		d.projects[p.ID] = *p
+		return nil
+	})
+}
+
+func (r *projectRepo) Get(ctx context.Context, id string) (*models.Project, error) {
+	var p *models.Project
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.projects[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		p = &found
+		return nil
+	})
+	return p, err
+}
+
+func (r *projectRepo) GetForUpdate(ctx context.Context, id string) (*models.Project, error) {
+	return r.Get(ctx, id)
+}
+
+func (r *projectRepo) Update(ctx context.Context, p *models.Project) error {
+	return r.s.locked(func(d *data) error {
+		existing, ok := d.projects[p.ID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		// Ownership and creation time never change
+		updated := *p
+		updated.EditorID, updated.OwnerID, updated.ChannelID = existing.EditorID, existing.OwnerID, existing.ChannelID
+		updated.CreatedAt = existing.CreatedAt
+		d.projects[p.ID] = updated
+		return nil
+	})
+}
+
+func (r *projectRepo) List(ctx context.Context, f repository.ProjectFilter) ([]models.Project, error) {
+	return r.list(func(d *data, p models.Project) bool { return matchProject(d, p, f) })
+}
+
+func (r *projectRepo) list(match func(d *data, p models.Project) bool) ([]models.Project, error) {
+	projects := []models.Project{}
+	err := r.s.locked(func(d *data) error {
+		for _, p := range d.projects {
+			if match(d, p) {
+				projects = append(projects, p)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(projects, func(a, b models.Project) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return projects, err
+}
+
+func (r *projectRepo) ListRecent(ctx context.Context, f repository.ProjectFilter) ([]repository.ProjectSummary, error) {
+	projects := []repository.ProjectSummary{}
+	err := r.s.locked(func(d *data) error {
+		for _, p := range d.projects {
+			if !matchProject(d, p, f) {
+				continue
+			}
+			projects = append(projects, repository.ProjectSummary{
+				Project:     p,
+				ChannelName: d.channels[p.ChannelID].Name,
+				OwnerName:   d.users[p.OwnerID].Name,
+				EditorName:  d.users[p.EditorID].Name,
+			})
+		}
+		return nil
+	})
+	slices.SortFunc(projects, func(a, b repository.ProjectSummary) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	if f.Limit > 0 && len(projects) > f.Limit {
+		projects = projects[:f.Limit]
+	}
+	return projects, err
+}
+
+func matchProject(d *data, p models.Project, f repository.ProjectFilter) bool {
+	return (f.OwnerID == "" || p.OwnerID == f.OwnerID) &&
+		(f.EditorID == "" || p.EditorID == f.EditorID) &&
+		(f.ChannelID == "" || p.ChannelID == f.ChannelID) &&
+		(f.MemberID == "" || p.OwnerID == f.MemberID || p.EditorID == f.MemberID ||
+			aboveEditor(d.editors[editorKey{p.ChannelID, f.MemberID}])) &&
+		(f.WorkspaceID == "" || d.channels[p.ChannelID].WorkspaceID == f.WorkspaceID)
+}
+
+// aboveEditor reports whether an assignment's role sees all of the channel's projects
+func aboveEditor(role string) bool {
+	return role != "" && role != "editor"
+}
+
+func (r *projectRepo) ListYouTubeIDs(ctx context.Context, channelID string) ([]string, error) {
+	ids := []string{}
+	err := r.s.locked(func(d *data) error {
+		for _, p := range d.projects {
+			if p.ChannelID == channelID && p.YouTubeID != "" {
+				ids = append(ids, p.YouTubeID)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (r *projectRepo) ListPublished(ctx context.Context) ([]models.Project, error) {
+	return r.list(func(d *data, p models.Project) bool { return p.YouTubeID != "" && p.Status == "published" })
+}
+
+func (r *projectRepo) GetMetadata(ctx context.Context, projectID string) (*models.ProjectMetadata, error) {
+	var m *models.ProjectMetadata
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.metadata[projectID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		found.Tags, found.PlaylistIDs = slices.Clone(found.Tags), slices.Clone(found.PlaylistIDs)
+		m = &found
+		return nil
+	})
+	return m, err
+}
+
+func (r *projectRepo) SaveMetadata(ctx context.Context, m *models.ProjectMetadata) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.projects[m.ProjectID]; !ok {
+			return repository.ErrNotFound
+		}
+		stored := *m
+		stored.Tags, stored.PlaylistIDs = slices.Clone(m.Tags), slices.Clone(m.PlaylistIDs)
+		d.metadata[m.ProjectID] = stored
+		return nil
+	})
+}
+
+func (r *projectRepo) AddVersion(ctx context.Context, v *models.ProjectVersion) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.projects[v.ProjectID]; !ok {
+			return repository.ErrNotFound
+		}
+		v.Version = 1
+		for _, existing := range d.versions {
+			if existing.ProjectID == v.ProjectID && existing.Version >= v.Version {
+				v.Version = existing.Version + 1
+			}
+		}
+		d.versions[v.ID] = *v
+		return nil
+	})
+}
+
+func (r *projectRepo) GetVersion(ctx context.Context, projectID, versionID string) (*models.ProjectVersion, error) {
+	versions, err := r.ListVersions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versionID == "" || versions[i].ID == versionID {
+			return &versions[i], nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *projectRepo) ListVersions(ctx context.Context, projectID string) ([]models.ProjectVersion, error) {
+	versions := []models.ProjectVersion{}
+	err := r.s.locked(func(d *data) error {
+		for _, v := range d.versions {
+			if v.ProjectID == projectID {
+				versions = append(versions, v)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(versions, func(a, b models.ProjectVersion) int { return a.Version - b.Version })
+	return versions, err
+}
+
+func (r *projectRepo) AddEvent(ctx context.Context, e *models.ProjectEvent) error {
+	return r.s.locked(func(d *data) error {
+		d.events = append(d.events, *e)
+		return nil
+	})
+}
+
+func (r *projectRepo) ListEvents(ctx context.Context, projectID string) ([]models.ProjectEvent, error) {
+	events := []models.ProjectEvent{}
+	err := r.s.locked(func(d *data) error {
+		for _, e := range d.events {
+			if e.ProjectID == projectID {
+				e.ActorName = d.users[e.ActorID].Name
+				events = append(events, e)
+			}
+		}
+		return nil
+	})
+	return events, err
+}
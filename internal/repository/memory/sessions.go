@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type sessionRepo struct {
+	s *Store
+}
+
+func (r *sessionRepo) Create(ctx context.Context, s *models.Session) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.sessions {
+			if existing.RefreshTokenHash == s.RefreshTokenHash {
+				return repository.ErrDuplicate
+			}
+		}
+		d.sessions[s.ID] = *s
+		return nil
+	})
+}
+
+func (r *sessionRepo) Get(ctx context.Context, id string) (*models.Session, error) {
+	var s *models.Session
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.sessions[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		s = &found
+		return nil
+	})
+	return s, err
+}
+
+func (r *sessionRepo) GetByTokenHashForUpdate(ctx context.Context, tokenHash string) (*models.Session, error) {
+	var s *models.Session
+	err := r.s.locked(func(d *data) error {
+		for _, found := range d.sessions {
+			if found.RefreshTokenHash == tokenHash || (found.PreviousTokenHash != "" && found.PreviousTokenHash == tokenHash) {
+				s = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return s, err
+}
+
+func (r *sessionRepo) Rotate(ctx context.Context, id, tokenHash string, expiresAt time.Time) error {
+	return r.s.locked(func(d *data) error {
+		s, ok := d.sessions[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		now := time.Now()
+		s.PreviousTokenHash, s.RefreshTokenHash = s.RefreshTokenHash, tokenHash
+		s.ExpiresAt, s.LastUsedAt = expiresAt, &now
+		d.sessions[id] = s
+		return nil
+	})
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, id string) error {
+	return r.s.locked(func(d *data) error {
+		s, ok := d.sessions[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		if s.RevokedAt == nil {
+			now := time.Now()
+			s.RevokedAt = &now
+			d.sessions[id] = s
+		}
+		return nil
+	})
+}
+
+func (r *sessionRepo) RevokeAllForUser(ctx context.Context, userID, exceptID string) ([]string, error) {
+	ids := []string{}
+	err := r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, s := range d.sessions {
+			if s.UserID == userID && id != exceptID && s.RevokedAt == nil {
+				s.RevokedAt = &now
+				d.sessions[id] = s
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
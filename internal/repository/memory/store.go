@@ -0,0 +1,156 @@
+// Package memory implements the repositories in memory, for tests and local
+// experiments. Transactions are emulated by snapshotting the data and
+// restoring it if the unit of work fails; they are not isolated from
+// concurrent callers.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type editorKey struct {
+	channelID string
+	editorID  string
+}
+
+type workspaceMemberKey struct {
+	workspaceID string
+	userID      string
+}
+
+type data struct {
+	users            map[string]models.User
+	channels         map[string]models.Channel
+	editors          map[editorKey]string // role by assignment
+	membershipEvents []models.ChannelMembershipEvent
+	projects         map[string]models.Project
+	versions         map[string]models.ProjectVersion
+	metadata         map[string]models.ProjectMetadata
+	events           []models.ProjectEvent
+	notes            map[string]models.Note
+	accounts         map[string]models.YouTubeAccount
+	invites          map[string]models.EditorInvite
+	inviteChannels   map[string][]string
+	sessions         map[string]models.Session
+	oauthStates      map[string]models.OAuthState
+	connections      map[string]models.YouTubeConnection
+	videos           map[string]models.YouTubeVideo
+	jobs             map[string]models.Job
+	channelStats     map[channelDay]models.ChannelStat
+	videoStats       map[projectDay]models.VideoStat
+	workspaces       map[string]models.Workspace
+	workspaceMembers map[workspaceMemberKey]models.WorkspaceMember
+	userTokens       map[string]models.UserToken
+}
+
+func (d *data) clone() *data {
+	return &data{
+		users:            maps.Clone(d.users),
+		channels:         maps.Clone(d.channels),
+		editors:          maps.Clone(d.editors),
+		membershipEvents: slices.Clone(d.membershipEvents),
+		projects:         maps.Clone(d.projects),
+		versions:         maps.Clone(d.versions),
+		metadata:         maps.Clone(d.metadata),
+		events:           slices.Clone(d.events),
+		notes:            maps.Clone(d.notes),
+		accounts:         maps.Clone(d.accounts),
+		invites:          maps.Clone(d.invites),
+		inviteChannels:   maps.Clone(d.inviteChannels),
+		sessions:         maps.Clone(d.sessions),
+		oauthStates:      maps.Clone(d.oauthStates),
+		connections:      maps.Clone(d.connections),
+		videos:           maps.Clone(d.videos),
+		jobs:             maps.Clone(d.jobs),
+		channelStats:     maps.Clone(d.channelStats),
+		videoStats:       maps.Clone(d.videoStats),
+		workspaces:       maps.Clone(d.workspaces),
+		workspaceMembers: maps.Clone(d.workspaceMembers),
+		userTokens:       maps.Clone(d.userTokens),
+	}
+}
+
+// Store is an in-memory repository.Store
+type Store struct {
+	mu   *sync.Mutex
+	d    **data
+	inTx bool
+}
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	d := &data{
+		users:            make(map[string]models.User),
+		channels:         make(map[string]models.Channel),
+		editors:          make(map[editorKey]string),
+		projects:         make(map[string]models.Project),
+		versions:         make(map[string]models.ProjectVersion),
+		metadata:         make(map[string]models.ProjectMetadata),
+		notes:            make(map[string]models.Note),
+		accounts:         make(map[string]models.YouTubeAccount),
+		invites:          make(map[string]models.EditorInvite),
+		inviteChannels:   make(map[string][]string),
+		sessions:         make(map[string]models.Session),
+		oauthStates:      make(map[string]models.OAuthState),
+		connections:      make(map[string]models.YouTubeConnection),
+		videos:           make(map[string]models.YouTubeVideo),
+		jobs:             make(map[string]models.Job),
+		channelStats:     make(map[channelDay]models.ChannelStat),
+		videoStats:       make(map[projectDay]models.VideoStat),
+		workspaces:       make(map[string]models.Workspace),
+		workspaceMembers: make(map[workspaceMemberKey]models.WorkspaceMember),
+		userTokens:       make(map[string]models.UserToken),
+	}
+	return &Store{mu: &sync.Mutex{}, d: &d}
+}
+
+func (s *Store) Users() repository.UserRepository                     { return &userRepo{s} }
+func (s *Store) Channels() repository.ChannelRepository               { return &channelRepo{s} }
+func (s *Store) Projects() repository.ProjectRepository               { return &projectRepo{s} }
+func (s *Store) Notes() repository.NoteRepository                     { return &noteRepo{s} }
+func (s *Store) YouTubeAccounts() repository.YouTubeAccountRepository { return &youtubeAccountRepo{s} }
+func (s *Store) Invites() repository.InviteRepository                 { return &inviteRepo{s} }
+func (s *Store) Sessions() repository.SessionRepository               { return &sessionRepo{s} }
+func (s *Store) OAuthStates() repository.OAuthStateRepository         { return &oauthStateRepo{s} }
+func (s *Store) YouTubeConnections() repository.YouTubeConnectionRepository {
+	return &youtubeConnectionRepo{s}
+}
+func (s *Store) Jobs() repository.JobRepository                   { return &jobRepo{s} }
+func (s *Store) Workspaces() repository.WorkspaceRepository       { return &workspaceRepo{s} }
+func (s *Store) UserTokens() repository.UserTokenRepository       { return &userTokenRepo{s} }
+func (s *Store) YouTubeVideos() repository.YouTubeVideoRepository { return &youtubeVideoRepo{s} }
+func (s *Store) Analytics() repository.AnalyticsRepository        { return &analyticsRepo{s} }
+
+// WithTx runs fn, restoring the data as it was if fn fails
+func (s *Store) WithTx(ctx context.Context, fn func(repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	snapshot := (*s.d).clone()
+	s.mu.Unlock()
+
+	if err := fn(&Store{mu: s.mu, d: s.d, inTx: true}); err != nil {
+		s.mu.Lock()
+		*s.d = snapshot
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// locked runs fn with the data locked
+func (s *Store) locked(fn func(d *data) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(*s.d)
+}
+
+var _ repository.Store = (*Store)(nil)
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type userTokenRepo struct {
+	s *Store
+}
+
+func (r *userTokenRepo) Create(ctx context.Context, t *models.UserToken) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.userTokens {
+			if existing.TokenHash == t.TokenHash {
+				return repository.ErrDuplicate
+			}
+		}
+		d.userTokens[t.ID] = *t
+		return nil
+	})
+}
+
+func (r *userTokenRepo) Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	var t *models.UserToken
+	err := r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, found := range d.userTokens {
+			if found.Purpose == purpose && found.TokenHash == tokenHash && found.UsedAt == nil && found.ExpiresAt.After(now) {
+				found.UsedAt = &now
+				d.userTokens[id] = found
+				t = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return t, err
+}
+
+func (r *userTokenRepo) RevokeForUser(ctx context.Context, userID, purpose string) error {
+	return r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, t := range d.userTokens {
+			if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+				t.UsedAt = &now
+				d.userTokens[id] = t
+			}
+		}
+		return nil
+	})
+}
+
+func (r *userTokenRepo) CountSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	n := 0
+	err := r.s.locked(func(d *data) error {
+		for _, t := range d.userTokens {
+			if t.UserID == userID && t.Purpose == purpose && t.CreatedAt.After(since) {
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *userTokenRepo) DeleteExpired(ctx context.Context) error {
+	return r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, t := range d.userTokens {
+			if t.ExpiresAt.Before(now) {
+				delete(d.userTokens, id)
+			}
+		}
+		return nil
+	})
+}
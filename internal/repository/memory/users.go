@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+func (r *userRepo) Create(ctx context.Context, u *models.User) error {
+	return r.s.locked(func(d *data) error {
+		for _, existing := range d.users {
+			if strings.EqualFold(existing.Email, u.Email) {
+				return repository.ErrDuplicate
+			}
+		}
+		d.users[u.ID] = *u
+		return nil
+	})
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	var u *models.User
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.users[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		u = &found
+		return nil
+	})
+	return u, err
+}
+
+func (r *userRepo) SetRole(ctx context.Context, id, role string) error {
+	return r.update(id, func(d *data, u *models.User) error {
+		u.Role = role
+		return nil
+	})
+}
+
+func (r *userRepo) UpdateProfile(ctx context.Context, id, name, email string) error {
+	return r.update(id, func(d *data, u *models.User) error {
+		for _, existing := range d.users {
+			if existing.ID != id && strings.EqualFold(existing.Email, email) {
+				return repository.ErrDuplicate
+			}
+		}
+		if u.Email != email {
+			u.EmailVerifiedAt = nil
+		}
+		u.Name, u.Email = name, email
+		return nil
+	})
+}
+
+func (r *userRepo) SetPassword(ctx context.Context, id, passwordHash string) error {
+	return r.update(id, func(d *data, u *models.User) error {
+		u.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+func (r *userRepo) MarkEmailVerified(ctx context.Context, id, email string) error {
+	return r.update(id, func(d *data, u *models.User) error {
+		if u.Email != email {
+			return repository.ErrNotFound
+		}
+		if u.EmailVerifiedAt == nil {
+			now := time.Now()
+			u.EmailVerifiedAt = &now
+		}
+		return nil
+	})
+}
+
+// update applies fn to the stored user, saving the change unless fn fails
+func (r *userRepo) update(id string, fn func(d *data, u *models.User) error) error {
+	return r.s.locked(func(d *data) error {
+		u, ok := d.users[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		if err := fn(d, &u); err != nil {
+			return err
+		}
+		d.users[id] = u
+		return nil
+	})
+}
+
+func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var u *models.User
+	err := r.s.locked(func(d *data) error {
+		for _, found := range d.users {
+			if found.Email == email {
+				u = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return u, err
+}
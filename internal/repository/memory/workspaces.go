@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type workspaceRepo struct {
+	s *Store
+}
+
+func (r *workspaceRepo) Create(ctx context.Context, w *models.Workspace) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.workspaces[w.ID]; ok {
+			return repository.ErrDuplicate
+		}
+		d.workspaces[w.ID] = *w
+		return nil
+	})
+}
+
+func (r *workspaceRepo) Get(ctx context.Context, id string) (*models.Workspace, error) {
+	var w *models.Workspace
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.workspaces[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		w = &found
+		return nil
+	})
+	return w, err
+}
+
+func (r *workspaceRepo) ListByUser(ctx context.Context, userID string) ([]repository.WorkspaceMembership, error) {
+	type joined struct {
+		repository.WorkspaceMembership
+		joinedAt time.Time
+	}
+	var found []joined
+	err := r.s.locked(func(d *data) error {
+		for k, m := range d.workspaceMembers {
+			if k.userID == userID {
+				found = append(found, joined{
+					WorkspaceMembership: repository.WorkspaceMembership{Workspace: d.workspaces[k.workspaceID], Role: m.Role},
+					joinedAt:            m.CreatedAt,
+				})
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(found, func(a, b joined) int {
+		if c := a.joinedAt.Compare(b.joinedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	workspaces := make([]repository.WorkspaceMembership, len(found))
+	for i, w := range found {
+		workspaces[i] = w.WorkspaceMembership
+	}
+	return workspaces, err
+}
+
+func (r *workspaceRepo) AddMember(ctx context.Context, m *models.WorkspaceMember) (bool, error) {
+	added := false
+	err := r.s.locked(func(d *data) error {
+		if _, ok := d.workspaces[m.WorkspaceID]; !ok {
+			return repository.ErrNotFound
+		}
+		key := workspaceMemberKey{m.WorkspaceID, m.UserID}
+		if _, ok := d.workspaceMembers[key]; ok {
+			return nil
+		}
+		d.workspaceMembers[key] = *m
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+func (r *workspaceRepo) GetMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	var m *models.WorkspaceMember
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.workspaceMembers[workspaceMemberKey{workspaceID, userID}]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		m = &found
+		return nil
+	})
+	return m, err
+}
+
+func (r *workspaceRepo) ListMembers(ctx context.Context, workspaceID string) ([]repository.WorkspaceMemberDetails, error) {
+	members := []repository.WorkspaceMemberDetails{}
+	err := r.s.locked(func(d *data) error {
+		for k, m := range d.workspaceMembers {
+			if k.workspaceID == workspaceID {
+				u := d.users[k.userID]
+				members = append(members, repository.WorkspaceMemberDetails{WorkspaceMember: m, Name: u.Name, Email: u.Email})
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(members, func(a, b repository.WorkspaceMemberDetails) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Email, b.Email))
+	})
+	return members, err
+}
+
+func (r *workspaceRepo) SetMemberRole(ctx context.Context, workspaceID, userID, role string) error {
+	return r.s.locked(func(d *data) error {
+		key := workspaceMemberKey{workspaceID, userID}
+		m, ok := d.workspaceMembers[key]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		m.Role = role
+		d.workspaceMembers[key] = m
+		return nil
+	})
+}
+
+func (r *workspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	return r.s.locked(func(d *data) error {
+		key := workspaceMemberKey{workspaceID, userID}
+		if _, ok := d.workspaceMembers[key]; !ok {
+			return repository.ErrNotFound
+		}
+		delete(d.workspaceMembers, key)
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+type youtubeAccountRepo struct {
+	s *Store
+}
+
+func (r *youtubeAccountRepo) Upsert(ctx context.Context, a *models.YouTubeAccount) error {
+	return r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, existing := range d.accounts {
+			if existing.UserID == a.UserID && existing.Email == a.Email {
+				existing.AccessToken, existing.RefreshToken = a.AccessToken, a.RefreshToken
+				existing.TokenKeyID, existing.TokenDataKey = a.TokenKeyID, slices.Clone(a.TokenDataKey)
+				existing.ExpiresAt, existing.UpdatedAt = a.ExpiresAt, now
+				existing.ReauthRequiredAt = nil
+				d.accounts[id] = existing
+				a.ID = id
+				return nil
+			}
+		}
+		a.ID = uuid.New().String()
+		a.CreatedAt, a.UpdatedAt = now, now
+		a.ReauthRequiredAt = nil
+		saved := *a
+		saved.TokenDataKey = slices.Clone(a.TokenDataKey)
+		d.accounts[a.ID] = saved
+		return nil
+	})
+}
+
+func (r *youtubeAccountRepo) Get(ctx context.Context, id string) (*models.YouTubeAccount, error) {
+	var a *models.YouTubeAccount
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.accounts[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		found.TokenDataKey = slices.Clone(found.TokenDataKey)
+		a = &found
+		return nil
+	})
+	return a, err
+}
+
+func (r *youtubeAccountRepo) GetByEmail(ctx context.Context, userID, email string) (*models.YouTubeAccount, error) {
+	var a *models.YouTubeAccount
+	err := r.s.locked(func(d *data) error {
+		for _, found := range d.accounts {
+			if found.UserID == userID && found.Email == email {
+				found.TokenDataKey = slices.Clone(found.TokenDataKey)
+				a = &found
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+	return a, err
+}
+
+func (r *youtubeAccountRepo) GetForUpdate(ctx context.Context, id string) (*models.YouTubeAccount, error) {
+	return r.Get(ctx, id)
+}
+
+func (r *youtubeAccountRepo) ListByUser(ctx context.Context, userID string) ([]models.YouTubeAccount, error) {
+	accounts := []models.YouTubeAccount{}
+	err := r.s.locked(func(d *data) error {
+		for _, a := range d.accounts {
+			if a.UserID == userID {
+				a.TokenDataKey = slices.Clone(a.TokenDataKey)
+				accounts = append(accounts, a)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(accounts, func(a, b models.YouTubeAccount) int { return strings.Compare(a.Email, b.Email) })
+	return accounts, err
+}
+
+func (r *youtubeAccountRepo) UpdateToken(ctx context.Context, a *models.YouTubeAccount) error {
+	return r.s.locked(func(d *data) error {
+		existing, ok := d.accounts[a.ID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		existing.AccessToken, existing.RefreshToken = a.AccessToken, a.RefreshToken
+		existing.TokenKeyID, existing.TokenDataKey = a.TokenKeyID, slices.Clone(a.TokenDataKey)
+		existing.ExpiresAt, existing.UpdatedAt = a.ExpiresAt, time.Now()
+		existing.ReauthRequiredAt = nil
+		d.accounts[a.ID] = existing
+		return nil
+	})
+}
+
+func (r *youtubeAccountRepo) MarkReauthRequired(ctx context.Context, id string) error {
+	return r.s.locked(func(d *data) error {
+		a, ok := d.accounts[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		now := time.Now()
+		a.ReauthRequiredAt, a.UpdatedAt = &now, now
+		d.accounts[id] = a
+		return nil
+	})
+}
+
+func (r *youtubeAccountRepo) Delete(ctx context.Context, id string) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.accounts[id]; !ok {
+			return repository.ErrNotFound
+		}
+		delete(d.accounts, id)
+		return nil
+	})
+}
+
+func (r *youtubeAccountRepo) ListIDsNotUsingKey(ctx context.Context, keyID string) ([]string, error) {
+	var accounts []models.YouTubeAccount
+	err := r.s.locked(func(d *data) error {
+		for _, a := range d.accounts {
+			if a.TokenKeyID != keyID {
+				accounts = append(accounts, a)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(accounts, func(a, b models.YouTubeAccount) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	ids := make([]string, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	return ids, err
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type youtubeConnectionRepo struct {
+	s *Store
+}
+
+func (r *youtubeConnectionRepo) Create(ctx context.Context, c *models.YouTubeConnection) error {
+	return r.s.locked(func(d *data) error {
+		d.connections[c.ID] = *c
+		return nil
+	})
+}
+
+func (r *youtubeConnectionRepo) Consume(ctx context.Context, id, userID string) (*models.YouTubeConnection, error) {
+	var c *models.YouTubeConnection
+	err := r.s.locked(func(d *data) error {
+		now := time.Now()
+		found, ok := d.connections[id]
+		if !ok || found.UserID != userID || found.ConsumedAt != nil || !found.ExpiresAt.After(now) {
+			return repository.ErrNotFound
+		}
+		found.ConsumedAt = &now
+		d.connections[id] = found
+		c = &found
+		return nil
+	})
+	return c, err
+}
+
+func (r *youtubeConnectionRepo) DeleteExpired(ctx context.Context) error {
+	return r.s.locked(func(d *data) error {
+		now := time.Now()
+		for id, c := range d.connections {
+			if c.ExpiresAt.Before(now) {
+				delete(d.connections, id)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type youtubeVideoRepo struct {
+	s *Store
+}
+
+func (r *youtubeVideoRepo) Get(ctx context.Context, projectID string) (*models.YouTubeVideo, error) {
+	var v *models.YouTubeVideo
+	err := r.s.locked(func(d *data) error {
+		found, ok := d.videos[projectID]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		found.Tags, found.Conflict = slices.Clone(found.Tags), slices.Clone(found.Conflict)
+		v = &found
+		return nil
+	})
+	return v, err
+}
+
+func (r *youtubeVideoRepo) Save(ctx context.Context, v *models.YouTubeVideo) error {
+	return r.s.locked(func(d *data) error {
+		if _, ok := d.projects[v.ProjectID]; !ok {
+			return repository.ErrNotFound
+		}
+		stored := *v
+		stored.Tags, stored.Conflict = slices.Clone(v.Tags), slices.Clone(v.Conflict)
+		d.videos[v.ProjectID] = stored
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type analyticsRepo struct {
+	q querier
+}
+
+func (r *analyticsRepo) SaveChannelStat(ctx context.Context, s *models.ChannelStat) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO channel_stats (channel_id, captured_on, subscriber_count, view_count, video_count, captured_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (channel_id, captured_on) DO UPDATE
+		SET subscriber_count = EXCLUDED.subscriber_count, view_count = EXCLUDED.view_count,
+		    video_count = EXCLUDED.video_count, captured_at = EXCLUDED.captured_at
+	`, s.ChannelID, s.CapturedOn, s.SubscriberCount, s.ViewCount, s.VideoCount, s.CapturedAt)
+	return mapError(err)
+}
+
+func (r *analyticsRepo) SaveVideoStat(ctx context.Context, s *models.VideoStat) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO video_stats (project_id, captured_on, youtube_video_id, view_count, like_count, comment_count, captured_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (project_id, captured_on) DO UPDATE
+		SET youtube_video_id = EXCLUDED.youtube_video_id, view_count = EXCLUDED.view_count,
+		    like_count = EXCLUDED.like_count, comment_count = EXCLUDED.comment_count, captured_at = EXCLUDED.captured_at
+	`, s.ProjectID, s.CapturedOn, s.YouTubeID, s.ViewCount, s.LikeCount, s.CommentCount, s.CapturedAt)
+	return mapError(err)
+}
+
+func (r *analyticsRepo) ListChannelStats(ctx context.Context, channelID string, from, to time.Time) ([]models.ChannelStat, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT channel_id, captured_on, subscriber_count, view_count, video_count, captured_at
+		FROM channel_stats
+		WHERE channel_id = $1::uuid AND captured_on BETWEEN $2 AND $3
+		ORDER BY captured_on
+	`, uuidArg(channelID), from, to)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	stats := []models.ChannelStat{}
+	for rows.Next() {
+		var s models.ChannelStat
+		if err := rows.Scan(&s.ChannelID, &s.CapturedOn, &s.SubscriberCount, &s.ViewCount, &s.VideoCount, &s.CapturedAt); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+const videoStatColumns = `
+	v.project_id, v.captured_on, v.youtube_video_id, v.view_count, v.like_count, v.comment_count, v.captured_at`
+
+func (r *analyticsRepo) ListVideoStats(ctx context.Context, projectID string, from, to time.Time) ([]models.VideoStat, error) {
+	return scanVideoStats(r.q.QueryContext(ctx, `
+		SELECT `+videoStatColumns+` FROM video_stats v
+		WHERE v.project_id = $1::uuid AND v.captured_on BETWEEN $2 AND $3
+		ORDER BY v.captured_on
+	`, uuidArg(projectID), from, to))
+}
+
+func (r *analyticsRepo) ListChannelVideoStats(ctx context.Context, channelID string, from, to time.Time) ([]models.VideoStat, error) {
+	return scanVideoStats(r.q.QueryContext(ctx, `
+		SELECT `+videoStatColumns+` FROM video_stats v
+		JOIN projects p ON p.id = v.project_id
+		WHERE p.channel_id = $1::uuid AND v.captured_on BETWEEN $2 AND $3
+		ORDER BY v.captured_on, v.project_id
+	`, uuidArg(channelID), from, to))
+}
+
+func scanVideoStats(rows *sql.Rows, err error) ([]models.VideoStat, error) {
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	stats := []models.VideoStat{}
+	for rows.Next() {
+		var s models.VideoStat
+		if err := rows.Scan(&s.ProjectID, &s.CapturedOn, &s.YouTubeID, &s.ViewCount, &s.LikeCount, &s.CommentCount, &s.CapturedAt); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type channelRepo struct {
+	q querier
+}
+
+const channelColumns = `
+	c.id, c.owner_id, c.workspace_id, COALESCE(c.youtube_account_id::text, ''), c.yt_channel_id, c.name,
+	COALESCE(c.icon_url, ''), COALESCE(c.email, ''), COALESCE(c.created_at, now()),
+	c.subscriber_count, c.view_count, c.video_count, c.last_synced_at, COALESCE(c.sync_error, '')`
+
+func scanChannels(rows *sql.Rows, err error) ([]models.Channel, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []models.Channel{}
+	for rows.Next() {
+		var ch models.Channel
+		if err := rows.Scan(
+			&ch.ID, &ch.OwnerID, &ch.WorkspaceID, &ch.YouTubeAccountID, &ch.YtChannelID, &ch.Name,
+			&ch.IconURL, &ch.Email, &ch.CreatedAt,
+			&ch.SubscriberCount, &ch.ViewCount, &ch.VideoCount, &ch.LastSyncedAt, &ch.SyncError,
+		); err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+	return channels, rows.Err()
+}
+
+func (r *channelRepo) Create(ctx context.Context, ch *models.Channel) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO channels (id, owner_id, workspace_id, youtube_account_id, yt_channel_id, name, icon_url, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, ch.ID, ch.OwnerID, ch.WorkspaceID, nullString(ch.YouTubeAccountID), ch.YtChannelID, ch.Name, ch.IconURL, ch.Email, ch.CreatedAt)
+	return mapError(err)
+}
+
+func (r *channelRepo) Get(ctx context.Context, id string) (*models.Channel, error) {
+	channels, err := scanChannels(r.q.QueryContext(ctx, `
+		SELECT `+channelColumns+` FROM channels c WHERE c.id = $1::uuid
+	`, uuidArg(id)))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if len(channels) == 0 {
+		return nil, mapError(sql.ErrNoRows)
+	}
+	return &channels[0], nil
+}
+
+func (r *channelRepo) ListByOwner(ctx context.Context, ownerID string) ([]models.Channel, error) {
+	return scanChannels(r.q.QueryContext(ctx, `
+		SELECT `+channelColumns+` FROM channels c WHERE c.owner_id = $1 ORDER BY c.name
+	`, ownerID))
+}
+
+func (r *channelRepo) ListByWorkspace(ctx context.Context, workspaceID string) ([]models.Channel, error) {
+	return scanChannels(r.q.QueryContext(ctx, `
+		SELECT `+channelColumns+` FROM channels c WHERE c.workspace_id = $1::uuid ORDER BY c.name
+	`, uuidArg(workspaceID)))
+}
+
+func (r *channelRepo) ListByEditor(ctx context.Context, editorID string) ([]models.Channel, error) {
+	return scanChannels(r.q.QueryContext(ctx, `
+		SELECT `+channelColumns+`
+		FROM editors_channels ec
+		JOIN channels c ON ec.channel_id = c.id
+		WHERE ec.editor_id = $1
+		ORDER BY c.name
+	`, editorID))
+}
+
+func (r *channelRepo) DeleteByYtChannelID(ctx context.Context, ownerID, ytChannelID string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM channels WHERE owner_id = $1 AND yt_channel_id = $2`, ownerID, ytChannelID)
+	return err
+}
+
+func (r *channelRepo) SetYouTubeAccount(ctx context.Context, id, accountID string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE channels SET youtube_account_id = $1 WHERE id = $2
+	`, nullString(accountID), id))
+}
+
+func (r *channelRepo) DetachYouTubeAccount(ctx context.Context, accountID string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE channels SET youtube_account_id = NULL WHERE youtube_account_id = $1`, accountID)
+	return mapError(err)
+}
+
+func (r *channelRepo) ListAll(ctx context.Context) ([]models.Channel, error) {
+	return scanChannels(r.q.QueryContext(ctx, `
+		SELECT `+channelColumns+` FROM channels c ORDER BY c.youtube_account_id, c.name
+	`))
+}
+
+func (r *channelRepo) SaveSync(ctx context.Context, ch *models.Channel) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE channels
+		SET name = $1, icon_url = $2, subscriber_count = $3, view_count = $4, video_count = $5,
+		    last_synced_at = $6, sync_error = NULLIF($7, '')
+		WHERE id = $8
+	`, ch.Name, ch.IconURL, ch.SubscriberCount, ch.ViewCount, ch.VideoCount, ch.LastSyncedAt, ch.SyncError, ch.ID))
+}
+
+func (r *channelRepo) EditorRole(ctx context.Context, channelID, editorID string) (string, error) {
+	var role string
+	err := r.q.QueryRowContext(ctx, `
+		SELECT role FROM editors_channels WHERE channel_id = $1::uuid AND editor_id = $2::uuid
+	`, uuidArg(channelID), uuidArg(editorID)).Scan(&role)
+	if err != nil {
+		return "", mapError(err)
+	}
+	return role, nil
+}
+
+func (r *channelRepo) AddEditor(ctx context.Context, channelID, editorID string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		INSERT INTO editors_channels (editor_id, channel_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, editorID, channelID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *channelRepo) SetEditorRole(ctx context.Context, channelID, editorID, role string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE editors_channels SET role = $1 WHERE channel_id = $2::uuid AND editor_id = $3::uuid
+	`, role, uuidArg(channelID), uuidArg(editorID)))
+}
+
+func (r *channelRepo) ListEditors(ctx context.Context, channelID string) ([]repository.ChannelEditor, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT u.id, u.name, u.email, ec.role
+		FROM editors_channels ec
+		JOIN users u ON ec.editor_id = u.id
+		WHERE ec.channel_id = $1::uuid
+		ORDER BY u.name, u.email
+	`, uuidArg(channelID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	editors := []repository.ChannelEditor{}
+	for rows.Next() {
+		var e repository.ChannelEditor
+		if err := rows.Scan(&e.ID, &e.Name, &e.Email, &e.Role); err != nil {
+			return nil, err
+		}
+		editors = append(editors, e)
+	}
+	return editors, rows.Err()
+}
+
+func (r *channelRepo) RemoveEditor(ctx context.Context, channelID, editorID string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		DELETE FROM editors_channels WHERE channel_id = $1::uuid AND editor_id = $2::uuid
+	`, uuidArg(channelID), uuidArg(editorID)))
+}
+
+func (r *channelRepo) ListEditorsOfOwner(ctx context.Context, ownerID string) ([]models.User, error) {
+	return scanPartners(r.q.QueryContext(ctx, `
+		SELECT DISTINCT u.id, u.name, u.email
+		FROM editors_channels ec
+		JOIN channels c ON ec.channel_id = c.id
+		JOIN users u ON ec.editor_id = u.id
+		WHERE c.owner_id = $1
+	`, ownerID))
+}
+
+func (r *channelRepo) ListOwnersOfEditor(ctx context.Context, editorID string) ([]models.User, error) {
+	return scanPartners(r.q.QueryContext(ctx, `
+		SELECT DISTINCT u.id, u.name, u.email
+		FROM editors_channels ec
+		JOIN channels c ON ec.channel_id = c.id
+		JOIN users u ON c.owner_id = u.id
+		WHERE ec.editor_id = $1
+	`, editorID))
+}
+
+func scanPartners(rows *sql.Rows, err error) ([]models.User, error) {
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *channelRepo) RecordMembershipEvent(ctx context.Context, e *models.ChannelMembershipEvent) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO channel_membership_events (id, actor_id, action, invite_id, editor_id, channel_id, email, created_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, NULLIF($4, '')::uuid, NULLIF($5, '')::uuid,
+		        NULLIF($6, '')::uuid, NULLIF($7, ''), $8)
+	`, e.ID, e.ActorID, e.Action, e.InviteID, e.EditorID, e.ChannelID, e.Email, e.CreatedAt)
+	return err
+}
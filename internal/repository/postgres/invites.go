@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type inviteRepo struct {
+	q querier
+}
+
+const pendingInvite = `i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > now()`
+
+func (r *inviteRepo) Create(ctx context.Context, inv *models.EditorInvite, channelIDs []string) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO editor_invites (id, owner_id, email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, inv.ID, inv.OwnerID, inv.Email, inv.TokenHash, inv.ExpiresAt, inv.CreatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	for _, channelID := range channelIDs {
+		if _, err := r.q.ExecContext(ctx, `
+			INSERT INTO editor_invite_channels (invite_id, channel_id) VALUES ($1, $2)
+		`, inv.ID, channelID); err != nil {
+			return mapError(err)
+		}
+	}
+	return nil
+}
+
+func (r *inviteRepo) ListPending(ctx context.Context, ownerID string) ([]repository.InviteDetails, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT i.id, i.owner_id, i.email, i.expires_at, i.created_at, u.name, ch.id, ch.name
+		FROM editor_invites i
+		JOIN users u ON u.id = i.owner_id
+		JOIN editor_invite_channels ic ON ic.invite_id = i.id
+		JOIN channels ch ON ch.id = ic.channel_id
+		WHERE i.owner_id = $1 AND `+pendingInvite+`
+		ORDER BY i.created_at DESC, ch.name
+	`, ownerID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	invites := []repository.InviteDetails{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var inv repository.InviteDetails
+		var ch models.Channel
+		if err := rows.Scan(
+			&inv.ID, &inv.OwnerID, &inv.Email, &inv.ExpiresAt, &inv.CreatedAt, &inv.OwnerName,
+			&ch.ID, &ch.Name,
+		); err != nil {
+			return nil, err
+		}
+		i, ok := index[inv.ID]
+		if !ok {
+			i = len(invites)
+			index[inv.ID] = i
+			invites = append(invites, inv)
+		}
+		invites[i].Channels = append(invites[i].Channels, ch)
+	}
+	return invites, rows.Err()
+}
+
+func (r *inviteRepo) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*repository.InviteDetails, error) {
+	var inv repository.InviteDetails
+	err := r.q.QueryRowContext(ctx, `
+		SELECT i.id, i.owner_id, i.email, i.token_hash, i.expires_at, i.created_at, u.name
+		FROM editor_invites i
+		JOIN users u ON u.id = i.owner_id
+		WHERE i.token_hash = $1 AND `+pendingInvite+`
+		FOR UPDATE OF i
+	`, tokenHash).Scan(&inv.ID, &inv.OwnerID, &inv.Email, &inv.TokenHash, &inv.ExpiresAt, &inv.CreatedAt, &inv.OwnerName)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT ch.id, ch.workspace_id, ch.name
+		FROM editor_invite_channels ic
+		JOIN channels ch ON ch.id = ic.channel_id
+		WHERE ic.invite_id = $1
+		ORDER BY ch.name
+	`, inv.ID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ch models.Channel
+		if err := rows.Scan(&ch.ID, &ch.WorkspaceID, &ch.Name); err != nil {
+			return nil, err
+		}
+		inv.Channels = append(inv.Channels, ch)
+	}
+	return &inv, rows.Err()
+}
+
+func (r *inviteRepo) Revoke(ctx context.Context, ownerID, id string) (*models.EditorInvite, error) {
+	var inv models.EditorInvite
+	err := r.q.QueryRowContext(ctx, `
+		UPDATE editor_invites SET revoked_at = now()
+		WHERE id = $1::uuid AND owner_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+		RETURNING id, owner_id, email, expires_at, revoked_at, created_at
+	`, uuidArg(id), ownerID).Scan(&inv.ID, &inv.OwnerID, &inv.Email, &inv.ExpiresAt, &inv.RevokedAt, &inv.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &inv, nil
+}
+
+func (r *inviteRepo) MarkAccepted(ctx context.Context, id, userID string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE editor_invites SET accepted_at = now(), accepted_by = $1 WHERE id = $2
+	`, userID, id))
+}
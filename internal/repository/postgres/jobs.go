@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type jobRepo struct {
+	q querier
+}
+
+const jobColumns = `
+	id, kind, COALESCE(key, ''), payload, status, attempts, max_attempts, run_at,
+	locked_at, COALESCE(locked_by, ''), COALESCE(last_error, ''), created_at, updated_at`
+
+func scanJob(row interface{ Scan(...any) error }) (*models.Job, error) {
+	var j models.Job
+	err := row.Scan(&j.ID, &j.Kind, &j.Key, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LockedAt, &j.LockedBy, &j.LastError, &j.CreatedAt, &j.UpdatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &j, nil
+}
+
+func (r *jobRepo) Enqueue(ctx context.Context, j *models.Job) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO jobs (id, kind, key, payload, status, max_attempts, run_at, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, 'queued', $5, $6, $7, $7)
+	`, j.ID, j.Kind, j.Key, []byte(j.Payload), j.MaxAttempts, j.RunAt, j.CreatedAt)
+	return mapError(err)
+}
+
+func (r *jobRepo) Claim(ctx context.Context, workerID string, staleBefore time.Time) (*models.Job, error) {
+	return scanJob(r.q.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = now(), locked_by = $1, updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'queued' AND run_at <= now())
+			   OR (status = 'running' AND locked_at < $2)
+			ORDER BY run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns, workerID, staleBefore))
+}
+
+func (r *jobRepo) Complete(ctx context.Context, id, workerID string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE jobs SET status = 'succeeded', locked_at = NULL, last_error = NULL, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_by = $2
+	`, id, workerID))
+}
+
+func (r *jobRepo) Retry(ctx context.Context, id, workerID string, runAt time.Time, lastErr string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE jobs SET status = 'queued', run_at = $3, locked_at = NULL, last_error = $4, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_by = $2
+	`, id, workerID, runAt, lastErr))
+}
+
+func (r *jobRepo) Bury(ctx context.Context, id, workerID string, lastErr string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE jobs SET status = 'dead', locked_at = NULL, last_error = $3, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_by = $2
+	`, id, workerID, lastErr))
+}
+
+func (r *jobRepo) GetActiveByKey(ctx context.Context, key string) (*models.Job, error) {
+	return scanJob(r.q.QueryRowContext(ctx, `
+		SELECT `+jobColumns+` FROM jobs WHERE key = $1 AND status IN ('queued', 'running')
+	`, key))
+}
+
+func (r *jobRepo) CancelByKey(ctx context.Context, key string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE jobs SET status = 'cancelled', updated_at = now()
+		WHERE key = $1 AND status = 'queued'
+	`, key))
+}
+
+func (r *jobRepo) RescheduleByKey(ctx context.Context, key string, runAt time.Time) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE jobs SET run_at = $2, updated_at = now()
+		WHERE key = $1 AND status = 'queued'
+	`, key, runAt))
+}
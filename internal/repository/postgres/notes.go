@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type noteRepo struct {
+	q querier
+}
+
+const noteColumns = `
+	n.id, n.project_id, n.user_id, n.parent_id::text, n.timestamp, n.end_timestamp,
+	n.content, n.resolved, n.resolved_by::text, n.resolved_at, n.created_at,
+	COALESCE(n.updated_at, n.created_at), u.name`
+
+func scanNote(row interface{ Scan(...any) error }) (*models.Note, error) {
+	var n models.Note
+	err := row.Scan(
+		&n.ID, &n.ProjectID, &n.UserID, &n.ParentID, &n.Timestamp, &n.EndTimestamp,
+		&n.Content, &n.Resolved, &n.ResolvedBy, &n.ResolvedAt, &n.CreatedAt,
+		&n.UpdatedAt, &n.AuthorName,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &n, nil
+}
+
+func (r *noteRepo) Create(ctx context.Context, n *models.Note) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO notes (id, project_id, user_id, parent_id, timestamp, end_timestamp, content, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, n.ID, n.ProjectID, n.UserID, n.ParentID, n.Timestamp, n.EndTimestamp,
+		n.Content, n.CreatedAt, n.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *noteRepo) Get(ctx context.Context, projectID, id string) (*models.Note, error) {
+	return scanNote(r.q.QueryRowContext(ctx, `
+		SELECT `+noteColumns+`
+		FROM notes n
+		JOIN users u ON u.id = n.user_id
+		WHERE n.id = $1::uuid AND n.project_id = $2::uuid
+	`, uuidArg(id), uuidArg(projectID)))
+}
+
+func (r *noteRepo) List(ctx context.Context, projectID string) ([]models.Note, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+noteColumns+`
+		FROM notes n
+		JOIN users u ON u.id = n.user_id
+		WHERE n.project_id = $1::uuid
+		ORDER BY n.timestamp, n.created_at
+	`, uuidArg(projectID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	notes := []models.Note{}
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *n)
+	}
+	return notes, rows.Err()
+}
+
+func (r *noteRepo) Update(ctx context.Context, n *models.Note) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE notes
+		SET content = $2, timestamp = $3, end_timestamp = $4,
+		    resolved = $5, resolved_by = $6, resolved_at = $7, updated_at = $8
+		WHERE id = $1
+	`, n.ID, n.Content, n.Timestamp, n.EndTimestamp,
+		n.Resolved, n.ResolvedBy, n.ResolvedAt, n.UpdatedAt))
+}
+
+func (r *noteRepo) MoveReplies(ctx context.Context, parentID string, timestamp int, endTimestamp *int) error {
+	_, err := r.q.ExecContext(ctx, `
+		UPDATE notes SET timestamp = $1, end_timestamp = $2 WHERE parent_id = $3
+	`, timestamp, endTimestamp, parentID)
+	return err
+}
+
+// Delete relies on the parent_id foreign key to cascade to replies
+func (r *noteRepo) Delete(ctx context.Context, id string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `DELETE FROM notes WHERE id = $1`, id))
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type oauthStateRepo struct {
+	q querier
+}
+
+func (r *oauthStateRepo) Create(ctx context.Context, st *models.OAuthState) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO oauth_states (id, user_id, state_hash, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, st.ID, st.UserID, st.StateHash, st.CodeVerifier, st.ExpiresAt, st.CreatedAt)
+	return mapError(err)
+}
+
+func (r *oauthStateRepo) Consume(ctx context.Context, stateHash string) (*models.OAuthState, error) {
+	var st models.OAuthState
+	err := r.q.QueryRowContext(ctx, `
+		UPDATE oauth_states SET used_at = now()
+		WHERE state_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, state_hash, code_verifier, expires_at, used_at, COALESCE(created_at, now())
+	`, stateHash).Scan(&st.ID, &st.UserID, &st.StateHash, &st.CodeVerifier, &st.ExpiresAt, &st.UsedAt, &st.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &st, nil
+}
+
+func (r *oauthStateRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM oauth_states WHERE expires_at < now()`)
+	return err
+}
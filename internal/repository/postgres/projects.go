@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/lib/pq"
+)
+
+type projectRepo struct {
+	q querier
+}
+
+const projectColumns = `
+	p.id, p.title, COALESCE(p.description, ''), p.video_path, p.status, COALESCE(p.editor_id::text, ''), p.owner_id,
+	p.channel_id, COALESCE(p.youtube_video_id, ''), COALESCE(p.approved_version_id::text, ''),
+	COALESCE(p.upload_session_url, ''), p.publishing_until, p.published_at, p.publish_at, p.source, p.created_at, p.updated_at`
+
+func projectFields(p *models.Project) []any {
+	return []any{
+		&p.ID, &p.Title, &p.Description, &p.VideoPath, &p.Status, &p.EditorID, &p.OwnerID,
+		&p.ChannelID, &p.YouTubeID, &p.ApprovedVersionID,
+		&p.UploadSessionURL, &p.PublishingUntil, &p.PublishedAt, &p.PublishAt, &p.Source, &p.CreatedAt, &p.UpdatedAt,
+	}
+}
+
+func (r *projectRepo) Create(ctx context.Context, p *models.Project) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO projects (id, title, description, video_path, status, editor_id, owner_id, channel_id,
+		                      youtube_video_id, published_at, source, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8, NULLIF($9, ''), $10, COALESCE(NULLIF($11, ''), 'app'), $12, $13)
+	`, p.ID, p.Title, p.Description, p.VideoPath, p.Status, p.EditorID, p.OwnerID, p.ChannelID,
+		p.YouTubeID, p.PublishedAt, p.Source, p.CreatedAt, p.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *projectRepo) Get(ctx context.Context, id string) (*models.Project, error) {
+	return r.get(ctx, "", id)
+}
+
+func (r *projectRepo) GetForUpdate(ctx context.Context, id string) (*models.Project, error) {
+	return r.get(ctx, "FOR UPDATE", id)
+}
+
+func (r *projectRepo) get(ctx context.Context, lock, id string) (*models.Project, error) {
+	var p models.Project
+	err := r.q.QueryRowContext(ctx, `
+		SELECT `+projectColumns+` FROM projects p WHERE p.id = $1::uuid `+lock,
+		uuidArg(id)).Scan(projectFields(&p)...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &p, nil
+}
+
+func (r *projectRepo) Update(ctx context.Context, p *models.Project) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE projects
+		SET title = $2, description = $3, video_path = $4, status = $5,
+		    youtube_video_id = NULLIF($6, ''), approved_version_id = NULLIF($7, '')::uuid,
+		    upload_session_url = NULLIF($8, ''), published_at = $9, publish_at = $10, updated_at = $11,
+		    publishing_until = $12
+		WHERE id = $1
+	`, p.ID, p.Title, p.Description, p.VideoPath, p.Status,
+		p.YouTubeID, p.ApprovedVersionID,
+		p.UploadSessionURL, p.PublishedAt, p.PublishAt, p.UpdatedAt,
+		p.PublishingUntil))
+}
+
+func (r *projectRepo) List(ctx context.Context, f repository.ProjectFilter) ([]models.Project, error) {
+	where, args := projectFilter(f)
+	if where == "" {
+		where = "TRUE"
+	}
+	return r.list(ctx, where, args...)
+}
+
+func (r *projectRepo) list(ctx context.Context, where string, args ...any) ([]models.Project, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+projectColumns+` FROM projects p WHERE `+where+` ORDER BY p.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		var p models.Project
+		if err := rows.Scan(projectFields(&p)...); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func (r *projectRepo) ListRecent(ctx context.Context, f repository.ProjectFilter) ([]repository.ProjectSummary, error) {
+	where, args := projectFilter(f)
+	query := `
+		SELECT ` + projectColumns + `, ch.name, o.name, COALESCE(e.name, '')
+		FROM projects p
+		JOIN channels ch ON p.channel_id = ch.id
+		JOIN users o ON p.owner_id = o.id
+		LEFT JOIN users e ON p.editor_id = e.id`
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query += " ORDER BY p.updated_at DESC"
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	projects := []repository.ProjectSummary{}
+	for rows.Next() {
+		var p repository.ProjectSummary
+		fields := append(projectFields(&p.Project), &p.ChannelName, &p.OwnerName, &p.EditorName)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// projectFilter turns f into conditions on projects p and their arguments
+func projectFilter(f repository.ProjectFilter) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, uuidArg(value))
+			where = append(where, fmt.Sprintf("%s = $%d::uuid", column, len(args)))
+		}
+	}
+	add("p.owner_id", f.OwnerID)
+	add("p.editor_id", f.EditorID)
+	add("p.channel_id", f.ChannelID)
+	if f.MemberID != "" {
+		args = append(args, uuidArg(f.MemberID))
+		where = append(where, fmt.Sprintf(`(p.owner_id = $%[1]d::uuid OR p.editor_id = $%[1]d::uuid OR p.channel_id IN (
+			SELECT channel_id FROM editors_channels WHERE editor_id = $%[1]d::uuid AND role <> 'editor'))`, len(args)))
+	}
+	if f.WorkspaceID != "" {
+		args = append(args, uuidArg(f.WorkspaceID))
+		where = append(where, fmt.Sprintf("p.channel_id IN (SELECT id FROM channels WHERE workspace_id = $%d::uuid)", len(args)))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+func (r *projectRepo) ListYouTubeIDs(ctx context.Context, channelID string) ([]string, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT youtube_video_id FROM projects WHERE channel_id = $1::uuid AND youtube_video_id IS NOT NULL
+	`, uuidArg(channelID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *projectRepo) ListPublished(ctx context.Context) ([]models.Project, error) {
+	return r.list(ctx, "p.youtube_video_id IS NOT NULL AND p.status = $1", "published")
+}
+
+func (r *projectRepo) GetMetadata(ctx context.Context, projectID string) (*models.ProjectMetadata, error) {
+	var m models.ProjectMetadata
+	err := r.q.QueryRowContext(ctx, `
+		SELECT project_id, tags, category_id, COALESCE(default_language, ''), made_for_kids, license,
+		       playlist_ids, COALESCE(thumbnail_path, ''), COALESCE(updated_by::text, ''), updated_at
+		FROM project_metadata WHERE project_id = $1::uuid
+	`, uuidArg(projectID)).Scan(&m.ProjectID, pq.Array(&m.Tags), &m.CategoryID, &m.DefaultLanguage, &m.MadeForKids, &m.License,
+		pq.Array(&m.PlaylistIDs), &m.ThumbnailPath, &m.UpdatedBy, &m.UpdatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &m, nil
+}
+
+func (r *projectRepo) SaveMetadata(ctx context.Context, m *models.ProjectMetadata) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO project_metadata (project_id, tags, category_id, default_language, made_for_kids, license,
+		                              playlist_ids, thumbnail_path, updated_by, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), NULLIF($9, '')::uuid, $10)
+		ON CONFLICT (project_id) DO UPDATE
+		SET tags = EXCLUDED.tags, category_id = EXCLUDED.category_id, default_language = EXCLUDED.default_language,
+		    made_for_kids = EXCLUDED.made_for_kids, license = EXCLUDED.license, playlist_ids = EXCLUDED.playlist_ids,
+		    thumbnail_path = EXCLUDED.thumbnail_path, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, m.ProjectID, pq.Array(m.Tags), m.CategoryID, m.DefaultLanguage, m.MadeForKids, m.License,
+		pq.Array(m.PlaylistIDs), m.ThumbnailPath, m.UpdatedBy, m.UpdatedAt)
+	return mapError(err)
+}
+
+// AddVersion locks the project row so concurrent uploads cannot take the same number
+func (r *projectRepo) AddVersion(ctx context.Context, v *models.ProjectVersion) error {
+	if _, err := r.q.ExecContext(ctx, `SELECT id FROM projects WHERE id = $1 FOR UPDATE`, v.ProjectID); err != nil {
+		return err
+	}
+	err := r.q.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1 FROM project_versions WHERE project_id = $1
+	`, v.ProjectID).Scan(&v.Version)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.q.ExecContext(ctx, `
+		INSERT INTO project_versions (id, project_id, version, video_path, uploader_id, size_bytes, checksum, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, v.ID, v.ProjectID, v.Version, v.VideoPath, v.UploaderID, v.SizeBytes, v.Checksum, v.CreatedAt)
+	return mapError(err)
+}
+
+const versionColumns = `id, project_id, version, video_path, uploader_id, size_bytes, checksum, created_at`
+
+func versionFields(v *models.ProjectVersion) []any {
+	return []any{&v.ID, &v.ProjectID, &v.Version, &v.VideoPath, &v.UploaderID, &v.SizeBytes, &v.Checksum, &v.CreatedAt}
+}
+
+func (r *projectRepo) GetVersion(ctx context.Context, projectID, versionID string) (*models.ProjectVersion, error) {
+	if versionID != "" && uuidArg(versionID) == nil {
+		return nil, repository.ErrNotFound
+	}
+	var v models.ProjectVersion
+	err := r.q.QueryRowContext(ctx, `
+		SELECT `+versionColumns+`
+		FROM project_versions
+		WHERE project_id = $1::uuid AND ($2 = '' OR id = NULLIF($2, '')::uuid)
+		ORDER BY version DESC
+		LIMIT 1
+	`, uuidArg(projectID), versionID).Scan(versionFields(&v)...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &v, nil
+}
+
+func (r *projectRepo) ListVersions(ctx context.Context, projectID string) ([]models.ProjectVersion, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+versionColumns+`
+		FROM project_versions
+		WHERE project_id = $1::uuid
+		ORDER BY version
+	`, uuidArg(projectID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	versions := []models.ProjectVersion{}
+	for rows.Next() {
+		var v models.ProjectVersion
+		if err := rows.Scan(versionFields(&v)...); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *projectRepo) AddEvent(ctx context.Context, e *models.ProjectEvent) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO project_events (id, project_id, actor_id, from_status, to_status, reason, created_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, ''), $5, NULLIF($6, ''), $7)
+	`, e.ID, e.ProjectID, e.ActorID, e.FromStatus, e.ToStatus, e.Reason, e.CreatedAt)
+	return err
+}
+
+func (r *projectRepo) ListEvents(ctx context.Context, projectID string) ([]models.ProjectEvent, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT e.id, e.project_id, COALESCE(e.actor_id::text, ''), COALESCE(u.name, ''),
+		       COALESCE(e.from_status, ''), e.to_status, COALESCE(e.reason, ''), e.created_at
+		FROM project_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		WHERE e.project_id = $1::uuid
+		ORDER BY e.created_at, e.id
+	`, uuidArg(projectID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	events := []models.ProjectEvent{}
+	for rows.Next() {
+		var e models.ProjectEvent
+		if err := rows.Scan(
+			&e.ID, &e.ProjectID, &e.ActorID, &e.ActorName,
+			&e.FromStatus, &e.ToStatus, &e.Reason, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
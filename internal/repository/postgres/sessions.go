@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type sessionRepo struct {
+	q querier
+}
+
+const sessionColumns = `
+	id, user_id, refresh_token_hash, COALESCE(previous_token_hash, ''), COALESCE(user_agent, ''),
+	expires_at, last_used_at, revoked_at, COALESCE(created_at, now())`
+
+func scanSession(row interface{ Scan(...any) error }) (*models.Session, error) {
+	var s models.Session
+	err := row.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &s.PreviousTokenHash, &s.UserAgent,
+		&s.ExpiresAt, &s.LastUsedAt, &s.RevokedAt, &s.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &s, nil
+}
+
+func (r *sessionRepo) Create(ctx context.Context, s *models.Session) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, refresh_token_hash, user_agent, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, s.ID, s.UserID, s.RefreshTokenHash, nullString(s.UserAgent), s.ExpiresAt, s.CreatedAt)
+	return mapError(err)
+}
+
+func (r *sessionRepo) Get(ctx context.Context, id string) (*models.Session, error) {
+	return scanSession(r.q.QueryRowContext(ctx, `
+		SELECT `+sessionColumns+` FROM sessions WHERE id = $1::uuid
+	`, uuidArg(id)))
+}
+
+func (r *sessionRepo) GetByTokenHashForUpdate(ctx context.Context, tokenHash string) (*models.Session, error) {
+	return scanSession(r.q.QueryRowContext(ctx, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE refresh_token_hash = $1 OR previous_token_hash = $1
+		FOR UPDATE
+	`, tokenHash))
+}
+
+func (r *sessionRepo) Rotate(ctx context.Context, id, tokenHash string, expiresAt time.Time) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE sessions
+		SET previous_token_hash = refresh_token_hash, refresh_token_hash = $1,
+		    expires_at = $2, last_used_at = now()
+		WHERE id = $3
+	`, tokenHash, expiresAt, id))
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, id string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1::uuid
+	`, uuidArg(id)))
+}
+
+func (r *sessionRepo) RevokeAllForUser(ctx context.Context, userID, exceptID string) ([]string, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1::uuid AND id IS DISTINCT FROM $2::uuid AND revoked_at IS NULL
+		RETURNING id
+	`, uuidArg(userID), uuidArg(exceptID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
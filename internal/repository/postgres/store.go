@@ -0,0 +1,113 @@
+// Package postgres implements the repositories on top of database/sql.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Store is the Postgres repository.Store
+type Store struct {
+	db   *sql.DB
+	q    querier
+	inTx bool
+}
+
+// NewStore returns a Store running its queries on db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, q: db}
+}
+
+func (s *Store) Users() repository.UserRepository       { return &userRepo{q: s.q} }
+func (s *Store) Channels() repository.ChannelRepository { return &channelRepo{q: s.q} }
+func (s *Store) Projects() repository.ProjectRepository { return &projectRepo{q: s.q} }
+func (s *Store) Notes() repository.NoteRepository       { return &noteRepo{q: s.q} }
+func (s *Store) YouTubeAccounts() repository.YouTubeAccountRepository {
+	return &youtubeAccountRepo{q: s.q}
+}
+func (s *Store) Invites() repository.InviteRepository         { return &inviteRepo{q: s.q} }
+func (s *Store) Sessions() repository.SessionRepository       { return &sessionRepo{q: s.q} }
+func (s *Store) OAuthStates() repository.OAuthStateRepository { return &oauthStateRepo{q: s.q} }
+func (s *Store) YouTubeConnections() repository.YouTubeConnectionRepository {
+	return &youtubeConnectionRepo{q: s.q}
+}
+func (s *Store) Jobs() repository.JobRepository                   { return &jobRepo{q: s.q} }
+func (s *Store) YouTubeVideos() repository.YouTubeVideoRepository { return &youtubeVideoRepo{q: s.q} }
+func (s *Store) Analytics() repository.AnalyticsRepository        { return &analyticsRepo{q: s.q} }
+func (s *Store) Workspaces() repository.WorkspaceRepository       { return &workspaceRepo{q: s.q} }
+func (s *Store) UserTokens() repository.UserTokenRepository       { return &userTokenRepo{q: s.q} }
+
+// WithTx runs fn in a transaction, committing if it succeeds
+func (s *Store) WithTx(ctx context.Context, fn func(repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Store{db: s.db, q: tx, inTx: true}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mapError turns driver errors the services care about into repository errors
+func mapError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return repository.ErrDuplicate
+		case "22P02": // invalid_text_representation; see uuidArg
+			return repository.ErrNotFound
+		}
+	}
+	return err
+}
+
+// rowsAffected reports ErrNotFound when an update or delete matched nothing
+func rowsAffected(res sql.Result, err error) error {
+	if err != nil {
+		return mapError(err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// uuidArg passes an ID compared against a uuid column. IDs that are not
+// UUIDs become NULL and so match nothing, instead of failing the query (and
+// with it any transaction it runs in).
+func uuidArg(id string) any {
+	if uuid.Validate(id) != nil {
+		return nil
+	}
+	return id
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+var _ repository.Store = (*Store)(nil)
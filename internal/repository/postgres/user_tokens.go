@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type userTokenRepo struct {
+	q querier
+}
+
+func (r *userTokenRepo) Create(ctx context.Context, t *models.UserToken) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO user_tokens (id, user_id, purpose, token_hash, email, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, t.ID, t.UserID, t.Purpose, t.TokenHash, t.Email, t.ExpiresAt, t.CreatedAt)
+	return mapError(err)
+}
+
+func (r *userTokenRepo) Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	var t models.UserToken
+	err := r.q.QueryRowContext(ctx, `
+		UPDATE user_tokens SET used_at = now()
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, purpose, token_hash, email, expires_at, used_at, COALESCE(created_at, now())
+	`, purpose, tokenHash).Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.Email, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &t, nil
+}
+
+func (r *userTokenRepo) RevokeForUser(ctx context.Context, userID, purpose string) error {
+	_, err := r.q.ExecContext(ctx, `
+		UPDATE user_tokens SET used_at = now()
+		WHERE user_id = $1::uuid AND purpose = $2 AND used_at IS NULL
+	`, uuidArg(userID), purpose)
+	return mapError(err)
+}
+
+func (r *userTokenRepo) CountSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	var n int
+	err := r.q.QueryRowContext(ctx, `
+		SELECT count(*) FROM user_tokens
+		WHERE user_id = $1::uuid AND purpose = $2 AND created_at > $3
+	`, uuidArg(userID), purpose, since).Scan(&n)
+	return n, err
+}
+
+func (r *userTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM user_tokens WHERE expires_at < now()`)
+	return mapError(err)
+}
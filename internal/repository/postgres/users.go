@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type userRepo struct {
+	q querier
+}
+
+func (r *userRepo) Create(ctx context.Context, u *models.User) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO users (id, name, email, password_hash, role, email_verified_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, u.ID, u.Name, u.Email, u.PasswordHash, u.Role, u.EmailVerifiedAt, u.CreatedAt)
+	return mapError(err)
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.get(ctx, `id = $1::uuid`, uuidArg(id))
+}
+
+func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.get(ctx, `email = $1`, email)
+}
+
+func (r *userRepo) SetRole(ctx context.Context, id, role string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2::uuid`, role, uuidArg(id)))
+}
+
+func (r *userRepo) UpdateProfile(ctx context.Context, id, name, email string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE users SET name = $1, email = $2,
+			email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+		WHERE id = $3::uuid
+	`, name, email, uuidArg(id)))
+}
+
+func (r *userRepo) SetPassword(ctx context.Context, id, passwordHash string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2::uuid`, passwordHash, uuidArg(id)))
+}
+
+func (r *userRepo) MarkEmailVerified(ctx context.Context, id, email string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = $1::uuid AND email = $2
+	`, uuidArg(id), email))
+}
+
+func (r *userRepo) get(ctx context.Context, where string, arg any) (*models.User, error) {
+	var u models.User
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id, name, email, password_hash, role, email_verified_at, created_at
+		FROM users
+		WHERE `+where, arg).Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.EmailVerifiedAt, &u.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &u, nil
+}
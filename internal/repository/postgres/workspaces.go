@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+type workspaceRepo struct {
+	q querier
+}
+
+func (r *workspaceRepo) Create(ctx context.Context, w *models.Workspace) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO workspaces (id, name, created_by, created_at) VALUES ($1, $2, $3, $4)
+	`, w.ID, w.Name, nullString(w.CreatedBy), w.CreatedAt)
+	return mapError(err)
+}
+
+func (r *workspaceRepo) Get(ctx context.Context, id string) (*models.Workspace, error) {
+	var w models.Workspace
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(created_by::text, ''), COALESCE(created_at, now())
+		FROM workspaces WHERE id = $1::uuid
+	`, uuidArg(id)).Scan(&w.ID, &w.Name, &w.CreatedBy, &w.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &w, nil
+}
+
+func (r *workspaceRepo) ListByUser(ctx context.Context, userID string) ([]repository.WorkspaceMembership, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT w.id, w.name, COALESCE(w.created_by::text, ''), COALESCE(w.created_at, now()), m.role
+		FROM workspace_members m
+		JOIN workspaces w ON m.workspace_id = w.id
+		WHERE m.user_id = $1::uuid
+		ORDER BY m.created_at, w.name
+	`, uuidArg(userID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	workspaces := []repository.WorkspaceMembership{}
+	for rows.Next() {
+		var w repository.WorkspaceMembership
+		if err := rows.Scan(&w.ID, &w.Name, &w.CreatedBy, &w.CreatedAt, &w.Role); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, rows.Err()
+}
+
+func (r *workspaceRepo) AddMember(ctx context.Context, m *models.WorkspaceMember) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, m.WorkspaceID, m.UserID, m.Role, m.CreatedAt)
+	if err != nil {
+		return false, mapError(err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *workspaceRepo) GetMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	var m models.WorkspaceMember
+	err := r.q.QueryRowContext(ctx, `
+		SELECT workspace_id, user_id, role, COALESCE(created_at, now())
+		FROM workspace_members WHERE workspace_id = $1::uuid AND user_id = $2::uuid
+	`, uuidArg(workspaceID), uuidArg(userID)).Scan(&m.WorkspaceID, &m.UserID, &m.Role, &m.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &m, nil
+}
+
+func (r *workspaceRepo) ListMembers(ctx context.Context, workspaceID string) ([]repository.WorkspaceMemberDetails, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT m.workspace_id, m.user_id, m.role, COALESCE(m.created_at, now()), u.name, u.email
+		FROM workspace_members m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.workspace_id = $1::uuid
+		ORDER BY u.name, u.email
+	`, uuidArg(workspaceID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	members := []repository.WorkspaceMemberDetails{}
+	for rows.Next() {
+		var m repository.WorkspaceMemberDetails
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Role, &m.CreatedAt, &m.Name, &m.Email); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *workspaceRepo) SetMemberRole(ctx context.Context, workspaceID, userID, role string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE workspace_members SET role = $1 WHERE workspace_id = $2::uuid AND user_id = $3::uuid
+	`, role, uuidArg(workspaceID), uuidArg(userID)))
+}
+
+func (r *workspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		DELETE FROM workspace_members WHERE workspace_id = $1::uuid AND user_id = $2::uuid
+	`, uuidArg(workspaceID), uuidArg(userID)))
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type youtubeAccountRepo struct {
+	q querier
+}
+
+const youtubeAccountColumns = `
+	id, user_id, email, access_token, refresh_token,
+	COALESCE(token_key_id, ''), token_data_key, expires_at,
+	COALESCE(created_at, now()), COALESCE(updated_at, now()), reauth_required_at`
+
+func scanYouTubeAccount(row interface{ Scan(...any) error }) (*models.YouTubeAccount, error) {
+	var a models.YouTubeAccount
+	err := row.Scan(&a.ID, &a.UserID, &a.Email, &a.AccessToken, &a.RefreshToken, &a.TokenKeyID, &a.TokenDataKey, &a.ExpiresAt, &a.CreatedAt, &a.UpdatedAt, &a.ReauthRequiredAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &a, nil
+}
+
+func (r *youtubeAccountRepo) Upsert(ctx context.Context, a *models.YouTubeAccount) error {
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO youtube_accounts (user_id, email, access_token, refresh_token, token_key_id, token_data_key, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, email) DO UPDATE
+		SET access_token = $3,
+		    refresh_token = $4,
+		    token_key_id = $5,
+		    token_data_key = $6,
+		    expires_at = $7,
+		    reauth_required_at = NULL,
+		    updated_at = now()
+		RETURNING id
+	`, a.UserID, a.Email, a.AccessToken, a.RefreshToken, nullString(a.TokenKeyID), a.TokenDataKey, a.ExpiresAt).Scan(&a.ID)
+	return mapError(err)
+}
+
+func (r *youtubeAccountRepo) Get(ctx context.Context, id string) (*models.YouTubeAccount, error) {
+	return scanYouTubeAccount(r.q.QueryRowContext(ctx, `
+		SELECT `+youtubeAccountColumns+` FROM youtube_accounts WHERE id = $1::uuid
+	`, uuidArg(id)))
+}
+
+func (r *youtubeAccountRepo) GetByEmail(ctx context.Context, userID, email string) (*models.YouTubeAccount, error) {
+	return scanYouTubeAccount(r.q.QueryRowContext(ctx, `
+		SELECT `+youtubeAccountColumns+` FROM youtube_accounts WHERE user_id = $1 AND email = $2
+	`, userID, email))
+}
+
+func (r *youtubeAccountRepo) GetForUpdate(ctx context.Context, id string) (*models.YouTubeAccount, error) {
+	return scanYouTubeAccount(r.q.QueryRowContext(ctx, `
+		SELECT `+youtubeAccountColumns+` FROM youtube_accounts WHERE id = $1::uuid FOR UPDATE
+	`, uuidArg(id)))
+}
+
+func (r *youtubeAccountRepo) ListByUser(ctx context.Context, userID string) ([]models.YouTubeAccount, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+youtubeAccountColumns+` FROM youtube_accounts WHERE user_id = $1 ORDER BY email
+	`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	accounts := []models.YouTubeAccount{}
+	for rows.Next() {
+		a, err := scanYouTubeAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *youtubeAccountRepo) UpdateToken(ctx context.Context, a *models.YouTubeAccount) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE youtube_accounts
+		SET access_token = $1, refresh_token = $2, token_key_id = $3, token_data_key = $4,
+		    expires_at = $5, reauth_required_at = NULL, updated_at = now()
+		WHERE id = $6
+	`, a.AccessToken, a.RefreshToken, nullString(a.TokenKeyID), a.TokenDataKey, a.ExpiresAt, a.ID))
+}
+
+func (r *youtubeAccountRepo) MarkReauthRequired(ctx context.Context, id string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `
+		UPDATE youtube_accounts SET reauth_required_at = now(), updated_at = now() WHERE id = $1
+	`, id))
+}
+
+func (r *youtubeAccountRepo) Delete(ctx context.Context, id string) error {
+	return rowsAffected(r.q.ExecContext(ctx, `DELETE FROM youtube_accounts WHERE id = $1`, id))
+}
+
+func (r *youtubeAccountRepo) ListIDsNotUsingKey(ctx context.Context, keyID string) ([]string, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT id FROM youtube_accounts
+		WHERE token_key_id IS DISTINCT FROM $1
+		ORDER BY created_at, id
+	`, keyID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+type youtubeConnectionRepo struct {
+	q querier
+}
+
+func (r *youtubeConnectionRepo) Create(ctx context.Context, c *models.YouTubeConnection) error {
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO youtube_connections (id, user_id, email, channels, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, c.ID, c.UserID, c.Email, []byte(c.Channels), c.ExpiresAt, c.CreatedAt)
+	return mapError(err)
+}
+
+func (r *youtubeConnectionRepo) Consume(ctx context.Context, id, userID string) (*models.YouTubeConnection, error) {
+	var c models.YouTubeConnection
+	err := r.q.QueryRowContext(ctx, `
+		UPDATE youtube_connections SET consumed_at = now()
+		WHERE id = $1::uuid AND user_id = $2::uuid AND consumed_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, email, channels, expires_at, consumed_at, COALESCE(created_at, now())
+	`, uuidArg(id), uuidArg(userID)).Scan(&c.ID, &c.UserID, &c.Email, &c.Channels, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &c, nil
+}
+
+func (r *youtubeConnectionRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM youtube_connections WHERE expires_at < now()`)
+	return err
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/lib/pq"
+)
+
+type youtubeVideoRepo struct {
+	q querier
+}
+
+func (r *youtubeVideoRepo) Get(ctx context.Context, projectID string) (*models.YouTubeVideo, error) {
+	var v models.YouTubeVideo
+	var conflict []byte
+	err := r.q.QueryRowContext(ctx, `
+		SELECT project_id, youtube_video_id, title, description, tags, COALESCE(category_id, ''),
+		       COALESCE(privacy_status, ''), COALESCE(etag, ''), conflict, synced_at
+		FROM youtube_videos WHERE project_id = $1::uuid
+	`, uuidArg(projectID)).Scan(&v.ProjectID, &v.YouTubeID, &v.Title, &v.Description, pq.Array(&v.Tags), &v.CategoryID,
+		&v.PrivacyStatus, &v.ETag, &conflict, &v.SyncedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	v.Conflict = conflict
+	return &v, nil
+}
+
+func (r *youtubeVideoRepo) Save(ctx context.Context, v *models.YouTubeVideo) error {
+	var conflict any
+	if len(v.Conflict) > 0 {
+		conflict = []byte(v.Conflict)
+	}
+	_, err := r.q.ExecContext(ctx, `
+		INSERT INTO youtube_videos (project_id, youtube_video_id, title, description, tags, category_id,
+		                            privacy_status, etag, conflict, synced_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+		ON CONFLICT (project_id) DO UPDATE
+		SET youtube_video_id = EXCLUDED.youtube_video_id, title = EXCLUDED.title,
+		    description = EXCLUDED.description, tags = EXCLUDED.tags, category_id = EXCLUDED.category_id,
+		    privacy_status = EXCLUDED.privacy_status, etag = EXCLUDED.etag, conflict = EXCLUDED.conflict,
+		    synced_at = EXCLUDED.synced_at
+	`, v.ProjectID, v.YouTubeID, v.Title, v.Description, pq.Array(v.Tags), v.CategoryID,
+		v.PrivacyStatus, v.ETag, conflict, v.SyncedAt)
+	return mapError(err)
+}
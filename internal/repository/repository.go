@@ -0,0 +1,318 @@
+// Package repository defines how the services load and store data. The
+// postgres package implements it for production and the memory package
+// provides in-memory fakes.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when a row would violate a unique constraint
+	ErrDuplicate = errors.New("already exists")
+)
+
+// Store gives access to every repository
+type Store interface {
+	Users() UserRepository
+	Channels() ChannelRepository
+	Projects() ProjectRepository
+	Notes() NoteRepository
+	YouTubeAccounts() YouTubeAccountRepository
+	Invites() InviteRepository
+	Sessions() SessionRepository
+	OAuthStates() OAuthStateRepository
+	YouTubeConnections() YouTubeConnectionRepository
+	Jobs() JobRepository
+	YouTubeVideos() YouTubeVideoRepository
+	Analytics() AnalyticsRepository
+	Workspaces() WorkspaceRepository
+	UserTokens() UserTokenRepository
+
+	// WithTx runs fn with a Store whose repositories share one transaction,
+	// committing if fn returns nil. Calls on a Store that is already in a
+	// transaction join it.
+	WithTx(ctx context.Context, fn func(Store) error) error
+}
+
+type UserRepository interface {
+	Create(ctx context.Context, u *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// SetRole changes the account's global role (owner or editor)
+	SetRole(ctx context.Context, id, role string) error
+	// UpdateProfile changes the name and email; a new email is unverified.
+	// Emails taken by another account are ErrDuplicate.
+	UpdateProfile(ctx context.Context, id, name, email string) error
+	SetPassword(ctx context.Context, id, passwordHash string) error
+	// MarkEmailVerified confirms email, or returns ErrNotFound if it is no
+	// longer the user's address
+	MarkEmailVerified(ctx context.Context, id, email string) error
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, t *models.UserToken) error
+	// Consume marks the token used and returns it. Unknown, used and expired
+	// tokens are ErrNotFound, so each token works once.
+	Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	// RevokeForUser uses up the user's outstanding tokens for purpose
+	RevokeForUser(ctx context.Context, userID, purpose string) error
+	// CountSince counts the user's tokens for purpose created after since,
+	// used or not
+	CountSince(ctx context.Context, userID, purpose string, since time.Time) (int, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type ChannelRepository interface {
+	Create(ctx context.Context, ch *models.Channel) error
+	Get(ctx context.Context, id string) (*models.Channel, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]models.Channel, error)
+	ListByWorkspace(ctx context.Context, workspaceID string) ([]models.Channel, error)
+	// ListByEditor returns the channels the editor is assigned to
+	ListByEditor(ctx context.Context, editorID string) ([]models.Channel, error)
+	DeleteByYtChannelID(ctx context.Context, ownerID, ytChannelID string) error
+	// SetYouTubeAccount moves the channel to another of the owner's Google accounts
+	SetYouTubeAccount(ctx context.Context, id, accountID string) error
+	// DetachYouTubeAccount unlinks every channel from the account
+	DetachYouTubeAccount(ctx context.Context, accountID string) error
+	// ListAll returns every channel on any dashboard, for background jobs
+	ListAll(ctx context.Context) ([]models.Channel, error)
+	// SaveSync stores what a sync learnt: name, icon, counts, sync time and error
+	SaveSync(ctx context.Context, ch *models.Channel) error
+
+	// EditorRole returns the editor's role on the channel, or ErrNotFound if
+	// they are not assigned to it
+	EditorRole(ctx context.Context, channelID, editorID string) (string, error)
+	// AddEditor assigns the editor to the channel with the editor role,
+	// reporting false if they already were
+	AddEditor(ctx context.Context, channelID, editorID string) (bool, error)
+	// SetEditorRole returns ErrNotFound if the editor was not assigned
+	SetEditorRole(ctx context.Context, channelID, editorID, role string) error
+	// ListEditors returns everyone assigned to the channel with their role
+	ListEditors(ctx context.Context, channelID string) ([]ChannelEditor, error)
+	// RemoveEditor returns ErrNotFound if the editor was not assigned
+	RemoveEditor(ctx context.Context, channelID, editorID string) error
+	// ListEditorsOfOwner returns every editor assigned to any of the owner's channels
+	ListEditorsOfOwner(ctx context.Context, ownerID string) ([]models.User, error)
+	// ListOwnersOfEditor returns the owners of every channel the editor is assigned to
+	ListOwnersOfEditor(ctx context.Context, editorID string) ([]models.User, error)
+
+	RecordMembershipEvent(ctx context.Context, e *models.ChannelMembershipEvent) error
+}
+
+// ChannelEditor is a user assigned to a channel and their role on it
+type ChannelEditor struct {
+	models.User
+	Role string
+}
+
+// ProjectFilter narrows List and ListRecent; every field that is set must match
+type ProjectFilter struct {
+	WorkspaceID string // of the project's channel
+	OwnerID     string
+	EditorID    string
+	ChannelID   string
+	// MemberID keeps the projects the user owns or edits and those on
+	// channels they are assigned to in a role above editor
+	MemberID string
+	Limit    int // ListRecent only; 0 means no limit
+}
+
+// ProjectSummary is a project with the names shown on its card
+type ProjectSummary struct {
+	models.Project
+	ChannelName string
+	OwnerName   string
+	EditorName  string
+}
+
+type ProjectRepository interface {
+	Create(ctx context.Context, p *models.Project) error
+	Get(ctx context.Context, id string) (*models.Project, error)
+	// GetForUpdate is Get, locking the row until the transaction ends
+	GetForUpdate(ctx context.Context, id string) (*models.Project, error)
+	// Update saves the project's mutable fields
+	Update(ctx context.Context, p *models.Project) error
+	// List returns the matching projects, newest first
+	List(ctx context.Context, f ProjectFilter) ([]models.Project, error)
+	// ListRecent returns the matching projects with their names, most
+	// recently updated first
+	ListRecent(ctx context.Context, f ProjectFilter) ([]ProjectSummary, error)
+	// ListYouTubeIDs returns the YouTube video IDs of the channel's projects
+	ListYouTubeIDs(ctx context.Context, channelID string) ([]string, error)
+	// ListPublished returns every project with a video on YouTube, for background jobs
+	ListPublished(ctx context.Context) ([]models.Project, error)
+
+	// GetMetadata returns ErrNotFound until metadata was saved for the project
+	GetMetadata(ctx context.Context, projectID string) (*models.ProjectMetadata, error)
+	// SaveMetadata creates or replaces the project's metadata
+	SaveMetadata(ctx context.Context, m *models.ProjectMetadata) error
+
+	// AddVersion gives v the project's next version number and stores it
+	AddVersion(ctx context.Context, v *models.ProjectVersion) error
+	// GetVersion returns the given version, or the latest one if versionID is empty
+	GetVersion(ctx context.Context, projectID, versionID string) (*models.ProjectVersion, error)
+	ListVersions(ctx context.Context, projectID string) ([]models.ProjectVersion, error)
+
+	AddEvent(ctx context.Context, e *models.ProjectEvent) error
+	ListEvents(ctx context.Context, projectID string) ([]models.ProjectEvent, error)
+}
+
+type NoteRepository interface {
+	Create(ctx context.Context, n *models.Note) error
+	Get(ctx context.Context, projectID, id string) (*models.Note, error)
+	// List returns the project's notes and replies ordered by timestamp
+	List(ctx context.Context, projectID string) ([]models.Note, error)
+	Update(ctx context.Context, n *models.Note) error
+	// MoveReplies keeps replies at the same point in the video as their parent
+	MoveReplies(ctx context.Context, parentID string, timestamp int, endTimestamp *int) error
+	// Delete removes the note together with its replies
+	Delete(ctx context.Context, id string) error
+}
+
+type YouTubeAccountRepository interface {
+	// Upsert stores the account's sealed tokens, keyed by user and Google
+	// email, clears its reauth flag and sets a.ID
+	Upsert(ctx context.Context, a *models.YouTubeAccount) error
+	Get(ctx context.Context, id string) (*models.YouTubeAccount, error)
+	GetByEmail(ctx context.Context, userID, email string) (*models.YouTubeAccount, error)
+	// GetForUpdate is Get, locking the row until the transaction ends
+	GetForUpdate(ctx context.Context, id string) (*models.YouTubeAccount, error)
+	ListByUser(ctx context.Context, userID string) ([]models.YouTubeAccount, error)
+	// UpdateToken saves a's tokens, their key and expiry
+	UpdateToken(ctx context.Context, a *models.YouTubeAccount) error
+	// MarkReauthRequired flags the account until its tokens are saved again
+	MarkReauthRequired(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	// ListIDsNotUsingKey returns the accounts whose tokens are not sealed
+	// under keyID, including those still in plaintext
+	ListIDsNotUsingKey(ctx context.Context, keyID string) ([]string, error)
+}
+
+// InviteDetails is an invite with who sent it and the channels it grants
+type InviteDetails struct {
+	models.EditorInvite
+	OwnerName string
+	Channels  []models.Channel
+}
+
+type InviteRepository interface {
+	Create(ctx context.Context, inv *models.EditorInvite, channelIDs []string) error
+	// ListPending returns the owner's invites that are neither accepted, revoked nor expired
+	ListPending(ctx context.Context, ownerID string) ([]InviteDetails, error)
+	// GetPendingByTokenHash finds a pending invite by the hash of its token,
+	// locking it until the transaction ends
+	GetPendingByTokenHash(ctx context.Context, tokenHash string) (*InviteDetails, error)
+	// Revoke cancels one of the owner's pending invites and returns it
+	Revoke(ctx context.Context, ownerID, id string) (*models.EditorInvite, error)
+	MarkAccepted(ctx context.Context, id, userID string) error
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, s *models.Session) error
+	Get(ctx context.Context, id string) (*models.Session, error)
+	// GetByTokenHashForUpdate finds the session whose current or previous
+	// refresh token has this hash, locking it until the transaction ends
+	GetByTokenHashForUpdate(ctx context.Context, tokenHash string) (*models.Session, error)
+	// Rotate replaces the refresh token, keeping the old hash as the previous one
+	Rotate(ctx context.Context, id, tokenHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id string) error
+	// RevokeAllForUser revokes the user's active sessions but exceptID (which
+	// may be empty) and returns their IDs
+	RevokeAllForUser(ctx context.Context, userID, exceptID string) ([]string, error)
+}
+
+type OAuthStateRepository interface {
+	Create(ctx context.Context, st *models.OAuthState) error
+	// Consume marks the state used and returns it. Unknown, used and expired
+	// states are ErrNotFound, so each state works once.
+	Consume(ctx context.Context, stateHash string) (*models.OAuthState, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type YouTubeConnectionRepository interface {
+	Create(ctx context.Context, c *models.YouTubeConnection) error
+	// Consume marks the user's connection read and returns it. Unknown, read
+	// and expired connections are ErrNotFound.
+	Consume(ctx context.Context, id, userID string) (*models.YouTubeConnection, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type JobRepository interface {
+	// Enqueue stores a queued job; ErrDuplicate if another job with the same
+	// key is still queued or running
+	Enqueue(ctx context.Context, j *models.Job) error
+	// Claim marks the next due job running and returns it: a queued job whose
+	// run_at has passed, or a running one locked before staleBefore whose
+	// worker presumably died. ErrNotFound if there is none.
+	Claim(ctx context.Context, workerID string, staleBefore time.Time) (*models.Job, error)
+	// Complete, Retry and Bury record how a run of the job ended. They
+	// return ErrNotFound if workerID no longer holds the job, e.g. because
+	// another worker took it over after the lease ran out.
+	Complete(ctx context.Context, id, workerID string) error
+	// Retry queues the job again at runAt, remembering why it failed
+	Retry(ctx context.Context, id, workerID string, runAt time.Time, lastErr string) error
+	// Bury gives up on the job, leaving it dead for someone to look at
+	Bury(ctx context.Context, id, workerID string, lastErr string) error
+	// GetActiveByKey returns the queued or running job with this key
+	GetActiveByKey(ctx context.Context, key string) (*models.Job, error)
+	// CancelByKey cancels the queued job with this key; ErrNotFound if none
+	CancelByKey(ctx context.Context, key string) error
+	// RescheduleByKey moves the queued job with this key to runAt; ErrNotFound if none
+	RescheduleByKey(ctx context.Context, key string, runAt time.Time) error
+}
+
+type YouTubeVideoRepository interface {
+	// Get returns ErrNotFound until the project's video was first synced
+	Get(ctx context.Context, projectID string) (*models.YouTubeVideo, error)
+	// Save creates or replaces the stored copy of the project's video
+	Save(ctx context.Context, v *models.YouTubeVideo) error
+}
+
+// AnalyticsRepository stores the daily statistics snapshots. Ranges are
+// inclusive days and results are ordered oldest first.
+type AnalyticsRepository interface {
+	// SaveChannelStat stores the day's snapshot, replacing an earlier one that day
+	SaveChannelStat(ctx context.Context, s *models.ChannelStat) error
+	// SaveVideoStat stores the day's snapshot, replacing an earlier one that day
+	SaveVideoStat(ctx context.Context, s *models.VideoStat) error
+	ListChannelStats(ctx context.Context, channelID string, from, to time.Time) ([]models.ChannelStat, error)
+	ListVideoStats(ctx context.Context, projectID string, from, to time.Time) ([]models.VideoStat, error)
+	// ListChannelVideoStats returns the snapshots of every project on the channel
+	ListChannelVideoStats(ctx context.Context, channelID string, from, to time.Time) ([]models.VideoStat, error)
+}
+
+// WorkspaceMembership is one of a user's workspaces and their role in it
+type WorkspaceMembership struct {
+	models.Workspace
+	Role string
+}
+
+// WorkspaceMemberDetails is a workspace member with their name and email
+type WorkspaceMemberDetails struct {
+	models.WorkspaceMember
+	Name  string
+	Email string
+}
+
+type WorkspaceRepository interface {
+	Create(ctx context.Context, w *models.Workspace) error
+	Get(ctx context.Context, id string) (*models.Workspace, error)
+	// ListByUser returns the user's workspaces, the one they joined first first
+	ListByUser(ctx context.Context, userID string) ([]WorkspaceMembership, error)
+
+	// AddMember adds the user to the workspace, reporting false if they already were
+	AddMember(ctx context.Context, m *models.WorkspaceMember) (bool, error)
+	GetMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error)
+	// ListMembers returns the workspace's members ordered by name
+	ListMembers(ctx context.Context, workspaceID string) ([]WorkspaceMemberDetails, error)
+	SetMemberRole(ctx context.Context, workspaceID, userID, role string) error
+	// RemoveMember returns ErrNotFound if the user was not a member
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+}
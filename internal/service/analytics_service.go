@@ -0,0 +1,422 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+// JobSnapshotAnalytics records the day's statistics of every channel and
+// published video
+const JobSnapshotAnalytics = "analytics.snapshot"
+
+const (
+	// defaultAnalyticsRange is how far back a trend goes without ?from
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	// maxAnalyticsRange keeps a trend to about a year of daily points
+	maxAnalyticsRange = 366 * 24 * time.Hour
+	// topVideosLimit is how many videos a channel trend lists
+	topVideosLimit = 10
+)
+
+// Date is a calendar day, written as 2006-01-02
+type Date time.Time
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(d).Format(time.DateOnly) + `"`), nil
+}
+
+// ChannelPoint is a channel's counters on one day with the change since the
+// previous snapshot
+type ChannelPoint struct {
+	Date              Date  `json:"date"`
+	Subscribers       int64 `json:"subscribers"`
+	Views             int64 `json:"views"`
+	Videos            int64 `json:"videos"`
+	SubscribersGained int64 `json:"subscribers_gained"`
+	ViewsGained       int64 `json:"views_gained"`
+}
+
+// VideoPoint is a video's counters on one day with the change since the
+// previous snapshot
+type VideoPoint struct {
+	Date          Date  `json:"date"`
+	Views         int64 `json:"views"`
+	Likes         int64 `json:"likes"`
+	Comments      int64 `json:"comments"`
+	ViewsGained   int64 `json:"views_gained"`
+	LikesGained   int64 `json:"likes_gained"`
+	CommentsAdded int64 `json:"comments_added"`
+}
+
+// StatChange is how much counters moved between the first and last
+// snapshot of a range, and that as a percentage of the first
+type StatChange struct {
+	Delta  map[string]int64   `json:"delta"`
+	Growth map[string]float64 `json:"growth_percent,omitempty"` // left out for counters that started at 0
+}
+
+// VideoChange is one video's change over a range
+type VideoChange struct {
+	ProjectID   string `json:"project_id"`
+	Title       string `json:"title"`
+	YouTubeID   string `json:"youtube_video_id"`
+	ViewsGained int64  `json:"views_gained"`
+	LikesGained int64  `json:"likes_gained"`
+}
+
+// ChannelAnalytics is a channel's trend over a range of days
+type ChannelAnalytics struct {
+	ChannelID string         `json:"channel_id"`
+	From      Date           `json:"from"`
+	To        Date           `json:"to"`
+	Points    []ChannelPoint `json:"points"`
+	Change    StatChange     `json:"change"`
+	TopVideos []VideoChange  `json:"top_videos"` // by views gained
+}
+
+// ProjectAnalytics is a published project's trend over a range of days
+type ProjectAnalytics struct {
+	ProjectID string       `json:"project_id"`
+	YouTubeID string       `json:"youtube_video_id"`
+	From      Date         `json:"from"`
+	To        Date         `json:"to"`
+	Points    []VideoPoint `json:"points"`
+	Change    StatChange   `json:"change"`
+}
+
+// AnalyticsService snapshots YouTube's counters daily and turns the
+// snapshots into trends
+type AnalyticsService struct {
+	store  repository.Store
+	tokens *TokenManager
+}
+
+// NewAnalyticsService creates an AnalyticsService
+func NewAnalyticsService(store repository.Store, tokens *TokenManager) *AnalyticsService {
+	return &AnalyticsService{store: store, tokens: tokens}
+}
+
+// RegisterJobs makes q run the snapshot job
+func (s *AnalyticsService) RegisterJobs(q *JobQueue) {
+	q.Handle(JobSnapshotAnalytics, func(ctx context.Context, job *models.Job) error {
+		return s.Snapshot(ctx)
+	})
+}
+
+// Run queues a snapshot now and then every interval until ctx is done.
+// Snapshots are per day, so extra runs only refresh the day's numbers.
+func (s *AnalyticsService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := enqueueJob(ctx, s.store, JobSnapshotAnalytics, JobSnapshotAnalytics, struct{}{}, time.Now())
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			log.Printf("analytics snapshot: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot records today's statistics of every channel and published
+// video, one YouTube call per account and batch of 50. Accounts waiting to
+// be reconnected are skipped, and a failing account only costs its own
+// channels their snapshot.
+func (s *AnalyticsService) Snapshot(ctx context.Context) error {
+	now := time.Now()
+	today := day(now)
+
+	channels, err := s.store.Channels().ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list channels: %w", err)
+	}
+	projects, err := s.store.Projects().ListPublished(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list published projects: %w", err)
+	}
+	videosByChannel := make(map[string][]models.Project)
+	for _, p := range projects {
+		videosByChannel[p.ChannelID] = append(videosByChannel[p.ChannelID], p)
+	}
+
+	byAccount := make(map[string][]models.Channel)
+	for _, ch := range channels {
+		if ch.YouTubeAccountID != "" {
+			byAccount[ch.YouTubeAccountID] = append(byAccount[ch.YouTubeAccountID], ch)
+		}
+	}
+
+	for accountID, chs := range byAccount {
+		account, err := s.store.YouTubeAccounts().Get(ctx, accountID)
+		if err != nil {
+			log.Printf("analytics snapshot for account %s: %v", accountID, err)
+			continue
+		}
+		if account.ReauthRequiredAt != nil {
+			continue
+		}
+		ytService, err := s.tokens.YouTube(ctx, accountID)
+		if err != nil {
+			log.Printf("analytics snapshot for account %s: %v", accountID, err)
+			continue
+		}
+
+		for start := 0; start < len(chs); start += maxChannelsPerCall {
+			batch := chs[start:min(start+maxChannelsPerCall, len(chs))]
+			ids := make([]string, len(batch))
+			for i, ch := range batch {
+				ids[i] = ch.YtChannelID
+			}
+			resp, err := ytService.Channels.List([]string{"statistics"}).Id(ids...).Context(ctx).Do()
+			if err != nil {
+				log.Printf("analytics snapshot for account %s: %v", accountID, err)
+				continue
+			}
+			for _, item := range resp.Items {
+				i := slices.IndexFunc(batch, func(ch models.Channel) bool { return ch.YtChannelID == item.Id })
+				if i < 0 || item.Statistics == nil {
+					continue
+				}
+				err := s.store.Analytics().SaveChannelStat(ctx, &models.ChannelStat{
+					ChannelID:       batch[i].ID,
+					CapturedOn:      today,
+					SubscriberCount: int64(item.Statistics.SubscriberCount),
+					ViewCount:       int64(item.Statistics.ViewCount),
+					VideoCount:      int64(item.Statistics.VideoCount),
+					CapturedAt:      now,
+				})
+				if err != nil {
+					log.Printf("analytics snapshot for account %s: %v", accountID, err)
+				}
+			}
+		}
+
+		var videos []models.Project
+		for _, ch := range chs {
+			videos = append(videos, videosByChannel[ch.ID]...)
+		}
+		for start := 0; start < len(videos); start += maxVideosPerCall {
+			batch := videos[start:min(start+maxVideosPerCall, len(videos))]
+			ids := make([]string, len(batch))
+			for i, p := range batch {
+				ids[i] = p.YouTubeID
+			}
+			resp, err := ytService.Videos.List([]string{"statistics"}).Id(ids...).Context(ctx).Do()
+			if err != nil {
+				log.Printf("analytics snapshot for account %s: %v", accountID, err)
+				continue
+			}
+			for _, item := range resp.Items {
+				i := slices.IndexFunc(batch, func(p models.Project) bool { return p.YouTubeID == item.Id })
+				if i < 0 || item.Statistics == nil {
+					continue
+				}
+				err := s.store.Analytics().SaveVideoStat(ctx, &models.VideoStat{
+					ProjectID:    batch[i].ID,
+					CapturedOn:   today,
+					YouTubeID:    item.Id,
+					ViewCount:    int64(item.Statistics.ViewCount),
+					LikeCount:    int64(item.Statistics.LikeCount),
+					CommentCount: int64(item.Statistics.CommentCount),
+					CapturedAt:   now,
+				})
+				if err != nil {
+					log.Printf("analytics snapshot for account %s: %v", accountID, err)
+				}
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// ChannelTrend returns the channel's statistics between from and to, which
+// default to the last 30 days. Anyone with a role on the channel may see it.
+func (s *AnalyticsService) ChannelTrend(ctx context.Context, userID, channelID, fromParam, toParam string) (*ChannelAnalytics, error) {
+	ch, err := channelWithPermission(ctx, s.store, channelID, userID, PermProjectView)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := parseRange(fromParam, toParam)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.Analytics().ListChannelStats(ctx, ch.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch statistics: %w", err)
+	}
+	result := &ChannelAnalytics{ChannelID: ch.ID, From: Date(from), To: Date(to), Points: []ChannelPoint{}, Change: StatChange{Delta: map[string]int64{}}}
+	for i, st := range stats {
+		point := ChannelPoint{
+			Date:        Date(st.CapturedOn),
+			Subscribers: st.SubscriberCount,
+			Views:       st.ViewCount,
+			Videos:      st.VideoCount,
+		}
+		if i > 0 {
+			point.SubscribersGained = st.SubscriberCount - stats[i-1].SubscriberCount
+			point.ViewsGained = st.ViewCount - stats[i-1].ViewCount
+		}
+		result.Points = append(result.Points, point)
+	}
+	if len(stats) > 0 {
+		first, last := stats[0], stats[len(stats)-1]
+		result.Change = newStatChange(map[string][2]int64{
+			"subscribers": {first.SubscriberCount, last.SubscriberCount},
+			"views":       {first.ViewCount, last.ViewCount},
+			"videos":      {first.VideoCount, last.VideoCount},
+		})
+	}
+
+	videoStats, err := s.store.Analytics().ListChannelVideoStats(ctx, ch.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch statistics: %w", err)
+	}
+	result.TopVideos, err = s.topVideos(ctx, videoStats)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// topVideos ranks the videos by views gained between their first and last
+// snapshot
+func (s *AnalyticsService) topVideos(ctx context.Context, stats []models.VideoStat) ([]VideoChange, error) {
+	first := make(map[string]models.VideoStat)
+	last := make(map[string]models.VideoStat)
+	for _, st := range stats {
+		if _, ok := first[st.ProjectID]; !ok {
+			first[st.ProjectID] = st
+		}
+		last[st.ProjectID] = st
+	}
+
+	changes := []VideoChange{}
+	for projectID, l := range last {
+		f := first[projectID]
+		changes = append(changes, VideoChange{
+			ProjectID:   projectID,
+			YouTubeID:   l.YouTubeID,
+			ViewsGained: l.ViewCount - f.ViewCount,
+			LikesGained: l.LikeCount - f.LikeCount,
+		})
+	}
+	slices.SortFunc(changes, func(a, b VideoChange) int {
+		if c := cmp.Compare(b.ViewsGained, a.ViewsGained); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.LikesGained, a.LikesGained)
+	})
+	if len(changes) > topVideosLimit {
+		changes = changes[:topVideosLimit]
+	}
+	for i := range changes {
+		p, err := s.store.Projects().Get(ctx, changes[i].ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch project: %w", err)
+		}
+		changes[i].Title = p.Title
+	}
+	return changes, nil
+}
+
+// ProjectTrend returns the published project's statistics between from and
+// to, which default to the last 30 days
+func (s *AnalyticsService) ProjectTrend(ctx context.Context, projectID, fromParam, toParam string) (*ProjectAnalytics, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	from, to, err := parseRange(fromParam, toParam)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.Analytics().ListVideoStats(ctx, p.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch statistics: %w", err)
+	}
+	result := &ProjectAnalytics{ProjectID: p.ID, YouTubeID: p.YouTubeID, From: Date(from), To: Date(to), Points: []VideoPoint{}, Change: StatChange{Delta: map[string]int64{}}}
+	for i, st := range stats {
+		point := VideoPoint{
+			Date:     Date(st.CapturedOn),
+			Views:    st.ViewCount,
+			Likes:    st.LikeCount,
+			Comments: st.CommentCount,
+		}
+		if i > 0 {
+			point.ViewsGained = st.ViewCount - stats[i-1].ViewCount
+			point.LikesGained = st.LikeCount - stats[i-1].LikeCount
+			point.CommentsAdded = st.CommentCount - stats[i-1].CommentCount
+		}
+		result.Points = append(result.Points, point)
+	}
+	if len(stats) > 0 {
+		first, last := stats[0], stats[len(stats)-1]
+		result.Change = newStatChange(map[string][2]int64{
+			"views":    {first.ViewCount, last.ViewCount},
+			"likes":    {first.LikeCount, last.LikeCount},
+			"comments": {first.CommentCount, last.CommentCount},
+		})
+	}
+	return result, nil
+}
+
+// newStatChange takes each counter's first and last value
+func newStatChange(values map[string][2]int64) StatChange {
+	change := StatChange{Delta: make(map[string]int64), Growth: make(map[string]float64)}
+	for name, v := range values {
+		change.Delta[name] = v[1] - v[0]
+		if v[0] > 0 {
+			change.Growth[name] = float64(v[1]-v[0]) / float64(v[0]) * 100
+		}
+	}
+	return change
+}
+
+// parseRange reads the from and to query parameters (YYYY-MM-DD)
+func parseRange(fromParam, toParam string) (time.Time, time.Time, error) {
+	to := day(time.Now())
+	if toParam != "" {
+		t, err := time.Parse(time.DateOnly, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, invalidf("to must be a date like 2006-01-02")
+		}
+		to = t
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if fromParam != "" {
+		f, err := time.Parse(time.DateOnly, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, invalidf("from must be a date like 2006-01-02")
+		}
+		from = f
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, invalidf("from must not be after to")
+	}
+	if to.Sub(from) > maxAnalyticsRange {
+		return time.Time{}, time.Time{}, invalidf("the range may span at most 366 days")
+	}
+	return from, to, nil
+}
+
+// day is the UTC calendar day of t
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// testKeyring is a keyring with one all-zero key, k1
+func testKeyring(t *testing.T) *TokenKeyring {
+	t.Helper()
+	keys, err := NewTokenKeyring(map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 32))}, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+// tokenEndpoint is Google's token endpoint, answering refreshes with reply
+type tokenEndpoint struct {
+	mu        sync.Mutex
+	refreshed []string // refresh tokens it was sent
+	reply     func(w http.ResponseWriter, refreshToken string)
+}
+
+func newTokenEndpoint(t *testing.T, reply func(w http.ResponseWriter, refreshToken string)) (*tokenEndpoint, *oauth2.Config) {
+	e := &tokenEndpoint{reply: reply}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshToken := r.FormValue("refresh_token")
+		e.mu.Lock()
+		e.refreshed = append(e.refreshed, refreshToken)
+		e.mu.Unlock()
+		e.reply(w, refreshToken)
+	}))
+	t.Cleanup(srv.Close)
+	return e, &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint:     oauth2.Endpoint{TokenURL: srv.URL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+}
+
+func (e *tokenEndpoint) calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.refreshed...)
+}
+
+// revokedGrant is Google's answer to a refresh token it no longer honours
+func revokedGrant(w http.ResponseWriter, refreshToken string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte(`{"error": "invalid_grant"}`))
+}
+
+// connectAccount stores an account whose access token has expired, sealed
+// under keys, and a channel of it
+func connectAccount(t *testing.T, st repository.Store, keys *TokenKeyring, refreshToken string) *models.YouTubeAccount {
+	t.Helper()
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Hour)
+	a := &models.YouTubeAccount{UserID: uuid.New().String(), Email: refreshToken + "@example.com", ExpiresAt: &expired}
+	if err := keys.Seal(a, "stale-access", refreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.YouTubeAccounts().Upsert(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	err := st.Channels().Create(ctx, &models.Channel{
+		ID:               uuid.New().String(),
+		OwnerID:          a.UserID,
+		YtChannelID:      "UC" + refreshToken,
+		Name:             refreshToken,
+		YouTubeAccountID: a.ID,
+		CreatedAt:        time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestSnapshotSkipsBrokenAccounts(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	keys := testKeyring(t)
+	endpoint, cfg := newTokenEndpoint(t, revokedGrant)
+	s := NewAnalyticsService(st, NewTokenManager(st, cfg, keys))
+
+	connectAccount(t, st, keys, "revoked")
+	waiting := connectAccount(t, st, keys, "waiting")
+	if err := st.YouTubeAccounts().MarkReauthRequired(ctx, waiting.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// One account's failure must not fail, and so retry, everyone's snapshot
+	if err := s.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if got := endpoint.calls(); len(got) != 1 || got[0] != "revoked" {
+		t.Errorf("refreshed %q, want only the account not waiting for reconnect", got)
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// What a user token is for (user_tokens.purpose)
+const (
+	tokenVerifyEmail   = "verify_email"
+	tokenResetPassword = "reset_password"
+)
+
+const (
+	// verifyTokenTTL is how long an email verification link works
+	verifyTokenTTL = 48 * time.Hour
+	// resetTokenTTL is how long a password reset link works
+	resetTokenTTL = time.Hour
+	// minPasswordLength matches what signup accepts
+	minPasswordLength = 6
+	// mailsPerHour caps the links of one kind an account is mailed on
+	// request, so the endpoints cannot be used to flood an address
+	mailsPerHour = 3
+)
+
+var (
+	// ErrUserTokenNotFound is returned for unknown, used and expired links
+	ErrUserTokenNotFound = &Error{Kind: ErrNotFound, Message: "link is invalid or expired"}
+	// ErrEmailNotVerified is returned when logging in before confirming the email
+	ErrEmailNotVerified = &Error{Kind: ErrForbidden, Message: "verify your email address before logging in"}
+)
+
+// Profile is what a user sees and edits about their own account
+type Profile struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Profile returns the user's own account
+func (s *AuthService) Profile(ctx context.Context, userID string) (*Profile, error) {
+	user, err := s.store.Users().GetByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newProfile(user), nil
+}
+
+// UpdateProfile changes the user's name and email. A new email has to be
+// verified again; the link is sent to the new address.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID, name, email string) (*Profile, error) {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if name == "" || email == "" {
+		return nil, invalidf("name and email are required")
+	}
+
+	var user *models.User
+	changed := false
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		if user, err = st.Users().GetByID(ctx, userID); err != nil {
+			return err
+		}
+		changed = user.Email != email
+		err = st.Users().UpdateProfile(ctx, userID, name, email)
+		if errors.Is(err, repository.ErrDuplicate) {
+			return conflict("an account with this email already exists")
+		}
+		if err != nil {
+			return err
+		}
+		if changed {
+			// Links sent to the old address must neither confirm the new one
+			// nor reset the password of an account that no longer uses it
+			for _, purpose := range []string{tokenVerifyEmail, tokenResetPassword} {
+				if err := st.UserTokens().RevokeForUser(ctx, userID, purpose); err != nil {
+					return err
+				}
+			}
+			user.EmailVerifiedAt = nil
+		}
+		user.Name, user.Email = name, email
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := s.sendVerification(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return newProfile(user), nil
+}
+
+// ChangePassword sets a new password after checking the current one. The
+// user's other sessions are logged out; sessionID stays logged in.
+func (s *AuthService) ChangePassword(ctx context.Context, userID, sessionID, current, next string) error {
+	if len(next) < minPasswordLength {
+		return invalidf("password must be at least %d characters", minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	var revoked []string
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		user, err := st.Users().GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)) != nil {
+			return invalidf("current password is incorrect")
+		}
+		if err := st.Users().SetPassword(ctx, userID, string(hash)); err != nil {
+			return err
+		}
+		if err := st.UserTokens().RevokeForUser(ctx, userID, tokenResetPassword); err != nil {
+			return err
+		}
+		revoked, err = st.Sessions().RevokeAllForUser(ctx, userID, sessionID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.forget(revoked...)
+	return nil
+}
+
+// ResendVerification mails a new verification link if email belongs to an
+// account that is not verified yet. Unknown addresses, failures to send and
+// throttled requests all look like success so the endpoint does not reveal
+// who has an account.
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.store.Users().GetByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	if err := s.resendVerification(ctx, user); err != nil {
+		log.Printf("resend verification to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *AuthService) resendVerification(ctx context.Context, user *models.User) error {
+	if throttled, err := s.mailThrottled(ctx, user.ID, tokenVerifyEmail); err != nil || throttled {
+		return err
+	}
+	if err := s.store.UserTokens().RevokeForUser(ctx, user.ID, tokenVerifyEmail); err != nil {
+		return err
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// VerifyEmail redeems a verification link
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		t, err := st.UserTokens().Consume(ctx, tokenVerifyEmail, HashToken(token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserTokenNotFound
+		}
+		if err != nil {
+			return err
+		}
+		err = st.Users().MarkEmailVerified(ctx, t.UserID, t.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserTokenNotFound
+		}
+		return err
+	})
+}
+
+// RequestPasswordReset mails a reset link if email belongs to an account.
+// Unknown addresses, failures to send and throttled requests all look like
+// success so the endpoint does not reveal who has an account.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.store.Users().GetByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.sendPasswordReset(ctx, user); err != nil {
+		log.Printf("password reset for user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *AuthService) sendPasswordReset(ctx context.Context, user *models.User) error {
+	if throttled, err := s.mailThrottled(ctx, user.ID, tokenResetPassword); err != nil || throttled {
+		return err
+	}
+	// Only the newest link works
+	if err := s.store.UserTokens().RevokeForUser(ctx, user.ID, tokenResetPassword); err != nil {
+		return err
+	}
+	link, err := s.newUserToken(ctx, user, tokenResetPassword, resetTokenTTL, "/reset-password")
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. "+
+			"Choose a new one here:\n\n%s\n\nThe link expires in an hour. "+
+			"If it was not you, ignore this email; your password stays the same.\n", user.Name, link),
+	})
+}
+
+// mailThrottled reports whether the user already got mailsPerHour links
+// for purpose in the last hour
+func (s *AuthService) mailThrottled(ctx context.Context, userID, purpose string) (bool, error) {
+	n, err := s.store.UserTokens().CountSince(ctx, userID, purpose, time.Now().Add(-time.Hour))
+	if err != nil {
+		return false, err
+	}
+	return n >= mailsPerHour, nil
+}
+
+// ResetPassword redeems a reset link, setting a new password and logging
+// the user out everywhere. Following the link also proves the email works.
+func (s *AuthService) ResetPassword(ctx context.Context, token, password string) error {
+	if len(password) < minPasswordLength {
+		return invalidf("password must be at least %d characters", minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	var revoked []string
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		t, err := st.UserTokens().Consume(ctx, tokenResetPassword, HashToken(token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserTokenNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := st.Users().SetPassword(ctx, t.UserID, string(hash)); err != nil {
+			return err
+		}
+		// The address may have changed since the link was sent
+		if err := st.Users().MarkEmailVerified(ctx, t.UserID, t.Email); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		revoked, err = st.Sessions().RevokeAllForUser(ctx, t.UserID, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.forget(revoked...)
+	return nil
+}
+
+// sendVerification mails the user a link confirming their current email
+func (s *AuthService) sendVerification(ctx context.Context, user *models.User) error {
+	link, err := s.newUserToken(ctx, user, tokenVerifyEmail, verifyTokenTTL, "/verify-email")
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, Mail{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening this link:\n\n%s\n\n"+
+			"The link expires in 48 hours. If you did not create an account, ignore this email.\n", user.Name, link),
+	})
+}
+
+// newUserToken stores a token for the user and returns the frontend link at
+// path that redeems it
+func (s *AuthService) newUserToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration, path string) (string, error) {
+	token, hash, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	// Old links are useless; drop them while we are here
+	if err := s.store.UserTokens().DeleteExpired(ctx); err != nil {
+		log.Printf("failed to delete expired user tokens: %v", err)
+	}
+	now := time.Now()
+	err = s.store.UserTokens().Create(ctx, &models.UserToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		Email:     user.Email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.frontendURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+func (s *AuthService) send(ctx context.Context, m Mail) error {
+	if err := s.mailer.Send(ctx, m); err != nil {
+		return upstream("Failed to send email", err)
+	}
+	return nil
+}
+
+func newProfile(u *models.User) *Profile {
+	return &Profile{
+		ID:            u.ID,
+		Name:          u.Name,
+		Email:         u.Email,
+		Role:          u.Role,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		CreatedAt:     u.CreatedAt,
+	}
+}
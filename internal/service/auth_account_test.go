@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+)
+
+const accountPassword = "correct horse"
+
+// accountFixture is an AuthService on the in-memory store with one user who
+// signed up but has not verified their email yet
+type accountFixture struct {
+	t      *testing.T
+	auth   *AuthService
+	mailer *MemoryMailer
+	userID string
+	email  string
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	mailer := NewMemoryMailer(false)
+	f := &accountFixture{t: t, mailer: mailer, email: "alice@example.com"}
+	f.auth = NewAuthService(memory.NewStore(), "test-jwt-secret", mailer, "http://frontend.test")
+	user, err := f.auth.Signup(context.Background(), SignupInput{Name: "Alice", Email: f.email, Password: accountPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.userID = user.ID
+	return f
+}
+
+var linkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastLink returns the token of the newest link mailed to to
+func (f *accountFixture) lastLink(to string) string {
+	f.t.Helper()
+	sent := f.mailer.Sent()
+	for i := len(sent) - 1; i >= 0; i-- {
+		if sent[i].To != to {
+			continue
+		}
+		m := linkToken.FindStringSubmatch(sent[i].Body)
+		if m == nil {
+			f.t.Fatalf("mail %q has no link", sent[i].Subject)
+		}
+		token, err := url.QueryUnescape(m[1])
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		return token
+	}
+	f.t.Fatalf("nothing was mailed to %s", to)
+	return ""
+}
+
+func (f *accountFixture) login(password string) error {
+	_, err := f.auth.Login(context.Background(), f.email, password, "test")
+	return err
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	if err := f.login(accountPassword); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("login before verifying: err = %v, want ErrEmailNotVerified", err)
+	}
+
+	token := f.lastLink(f.email)
+	if err := f.auth.VerifyEmail(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.auth.VerifyEmail(ctx, token); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("second use: err = %v, want ErrUserTokenNotFound", err)
+	}
+	if err := f.auth.VerifyEmail(ctx, "made-up"); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("unknown link: err = %v, want ErrUserTokenNotFound", err)
+	}
+	if err := f.login(accountPassword); err != nil {
+		t.Errorf("login after verifying: %v", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+
+	if err := f.auth.RequestPasswordReset(ctx, f.email); err != nil {
+		t.Fatal(err)
+	}
+	older := f.lastLink(f.email)
+	if err := f.auth.RequestPasswordReset(ctx, f.email); err != nil {
+		t.Fatal(err)
+	}
+	token := f.lastLink(f.email)
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		want     error
+	}{
+		{name: "superseded link", token: older, password: "new password", want: ErrUserTokenNotFound},
+		{name: "short password", token: token, password: "short", want: ErrInvalidInput},
+		{name: "reset", token: token, password: "new password"},
+		{name: "used link", token: token, password: "another one", want: ErrUserTokenNotFound},
+	}
+	for _, tt := range tests {
+		err := f.auth.ResetPassword(ctx, tt.token, tt.password)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Following the link proved the address, so the user can log in
+	if err := f.login("new password"); err != nil {
+		t.Errorf("login with the new password: %v", err)
+	}
+	if err := f.login(accountPassword); err == nil {
+		t.Error("the old password still works")
+	}
+}
+
+func TestAccountMailsAreThrottled(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	before := len(f.mailer.Sent())
+
+	for range mailsPerHour + 2 {
+		if err := f.auth.RequestPasswordReset(ctx, f.email); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.auth.ResendVerification(ctx, f.email); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Signup already sent one verification link
+	if got, want := len(f.mailer.Sent())-before, 2*mailsPerHour-1; got != want {
+		t.Errorf("sent %d mails, want %d", got, want)
+	}
+}
+
+func TestEmailChangeRevokesLinks(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	verify := f.lastLink(f.email)
+	if err := f.auth.RequestPasswordReset(ctx, f.email); err != nil {
+		t.Fatal(err)
+	}
+	reset := f.lastLink(f.email)
+
+	profile, err := f.auth.UpdateProfile(ctx, f.userID, "Alice", "alice@example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.EmailVerified {
+		t.Error("the new address counts as verified")
+	}
+
+	// Whoever still reads the old inbox can no longer use its links
+	if err := f.auth.VerifyEmail(ctx, verify); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("old verification link: err = %v, want ErrUserTokenNotFound", err)
+	}
+	if err := f.auth.ResetPassword(ctx, reset, "taken over"); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("old reset link: err = %v, want ErrUserTokenNotFound", err)
+	}
+	if err := f.auth.VerifyEmail(ctx, f.lastLink("alice@example.org")); err != nil {
+		t.Errorf("new verification link: %v", err)
+	}
+}
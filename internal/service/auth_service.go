@@ -1 +1,382 @@
 package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// accessTokenTTL is how long a signed access token stays valid. Revoked
+	// sessions are also refused by the middleware before this runs out.
+	accessTokenTTL = 15 * time.Minute
+	// refreshTokenTTL is how long a session lasts without being used
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// sessionCacheTTL is how long a session found active is trusted without
+	// asking the database again
+	sessionCacheTTL = 30 * time.Second
+)
+
+var errInvalidRefreshToken = &Error{Kind: ErrUnauthorized, Message: "invalid or expired refresh token"}
+
+// AuthService signs users up, logs them in and manages their sessions
+type AuthService struct {
+	store       repository.Store
+	jwtSecret   string
+	mailer      Mailer
+	frontendURL string // base of the verification and reset links
+
+	mu     sync.Mutex
+	active map[string]time.Time // session ID -> when it was last seen active
+}
+
+// NewAuthService creates an AuthService signing tokens with jwtSecret and
+// mailing account links that point at frontendURL
+func NewAuthService(store repository.Store, jwtSecret string, mailer Mailer, frontendURL string) *AuthService {
+	return &AuthService{
+		store:       store,
+		jwtSecret:   jwtSecret,
+		mailer:      mailer,
+		frontendURL: frontendURL,
+		active:      make(map[string]time.Time),
+	}
+}
+
+// Tokens is what a login or refresh hands back to the client
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // seconds until AccessToken expires
+}
+
+// SignupInput is what a new user provides
+type SignupInput struct {
+	Name        string
+	Email       string
+	Password    string
+	Role        string // "editor"; owner accounts are granted, see GrantOwner
+	InviteToken string // signing up from an editor invite
+}
+
+// Signup creates the user and, when they came from an invite, assigns them
+// to the invite's channels in the same transaction. The account cannot log
+// in until the emailed verification link is followed.
+func (s *AuthService) Signup(ctx context.Context, in SignupInput) (*models.User, error) {
+	// Owners manage other people's work, so nobody can make themselves one
+	switch in.Role {
+	case "", RoleEditor:
+		in.Role = RoleEditor
+	case RoleOwner:
+		return nil, forbidden("owner accounts cannot be created by signing up")
+	default:
+		return nil, invalidf("unknown role %q", in.Role)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Name:         in.Name,
+		Email:        in.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         in.Role,
+		CreatedAt:    time.Now(),
+	}
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := st.Users().Create(ctx, user); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return conflict("an account with this email already exists")
+			}
+			return err
+		}
+		if in.InviteToken != "" {
+			return acceptInvite(ctx, st, in.InviteToken, user.ID, user.Email)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The account exists either way; the user can ask for another link
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("signup: verification email for %s: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+// GrantOwner turns the account with email into an owner account and gives it
+// a workspace of its own to add channels to, unless it already administers or
+// owns one. Signup cannot create owners; operators grant them with this.
+func GrantOwner(ctx context.Context, store repository.Store, email string) (*models.User, error) {
+	var user *models.User
+	err := store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		user, err = st.Users().GetByEmail(ctx, strings.TrimSpace(email))
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("No user with this email")
+		}
+		if err != nil {
+			return err
+		}
+		if err := st.Users().SetRole(ctx, user.ID, RoleOwner); err != nil {
+			return err
+		}
+		user.Role = RoleOwner
+
+		memberships, err := st.Workspaces().ListByUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			if m.Role == WorkspaceAdmin || m.Role == WorkspaceOwner {
+				return nil
+			}
+		}
+		_, err = createWorkspace(ctx, st, user.ID, user.Name+"'s workspace")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login checks the credentials and starts a session
+func (s *AuthService) Login(ctx context.Context, email, password, userAgent string) (*Tokens, error) {
+	user, err := s.store.Users().GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, &Error{Kind: ErrUnauthorized, Message: "invalid email or password"}
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, &Error{Kind: ErrUnauthorized, Message: "invalid email or password"}
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	refreshToken, hash, err := NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &models.Session{
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		RefreshTokenHash: hash,
+		UserAgent:        userAgent,
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		CreatedAt:        now,
+	}
+	if err := s.store.Sessions().Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.issue(user, session.ID, refreshToken)
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token. Presenting a refresh token that was already rotated means it was
+// copied, so the whole session is revoked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	var user *models.User
+	var sessionID, next string
+	var reused bool
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		hash := HashToken(refreshToken)
+		session, err := st.Sessions().GetByTokenHashForUpdate(ctx, hash)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errInvalidRefreshToken
+			}
+			return err
+		}
+		if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+			return errInvalidRefreshToken
+		}
+		sessionID = session.ID
+		if session.RefreshTokenHash != hash {
+			reused = true
+			return st.Sessions().Revoke(ctx, session.ID)
+		}
+
+		if user, err = st.Users().GetByID(ctx, session.UserID); err != nil {
+			return err
+		}
+		var nextHash string
+		if next, nextHash, err = NewOpaqueToken(); err != nil {
+			return err
+		}
+		return st.Sessions().Rotate(ctx, session.ID, nextHash, time.Now().Add(refreshTokenTTL))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		s.forget(sessionID)
+		return nil, errInvalidRefreshToken
+	}
+	return s.issue(user, sessionID, next)
+}
+
+// Logout ends the session the refresh token belongs to. Unknown tokens are
+// ignored so logging out twice is harmless.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.store.Sessions().GetByTokenHashForUpdate(ctx, HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.store.Sessions().Revoke(ctx, session.ID); err != nil {
+		return err
+	}
+	s.forget(session.ID)
+	return nil
+}
+
+// RevokeEditorSessions logs an editor out everywhere. Only users who may
+// manage one of the channels the editor is assigned to can do this.
+func (s *AuthService) RevokeEditorSessions(ctx context.Context, userID, editorID string) (int, error) {
+	channels, err := s.store.Channels().ListByEditor(ctx, editorID)
+	if err != nil {
+		return 0, err
+	}
+	allowed, member := false, false
+	for i := range channels {
+		role, err := channelRole(ctx, s.store, &channels[i], userID)
+		if err != nil {
+			return 0, err
+		}
+		if role == "" {
+			continue
+		}
+		member = true
+		if RoleHas(role, PermChannelManage) {
+			allowed = true
+			break
+		}
+	}
+	if !member {
+		return 0, notFound("editor not found")
+	}
+	if !allowed {
+		return 0, forbidden("You are not allowed to manage this editor's channels")
+	}
+
+	ids, err := s.store.Sessions().RevokeAllForUser(ctx, editorID, "")
+	if err != nil {
+		return 0, err
+	}
+	s.forget(ids...)
+	return len(ids), nil
+}
+
+// SessionActive reports whether the session behind an access token may still
+// be used. Active sessions are cached briefly so most requests skip the
+// database; revocations on this server take effect immediately and those on
+// other servers within sessionCacheTTL.
+func (s *AuthService) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	s.mu.Lock()
+	seen, ok := s.active[sessionID]
+	s.mu.Unlock()
+	if ok && time.Since(seen) < sessionCacheTTL {
+		return true, nil
+	}
+
+	session, err := s.store.Sessions().Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		s.forget(sessionID)
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, at := range s.active {
+		if time.Since(at) >= sessionCacheTTL {
+			delete(s.active, id)
+		}
+	}
+	s.active[sessionID] = time.Now()
+	return true, nil
+}
+
+// forget drops sessions from the active cache
+func (s *AuthService) forget(sessionIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range sessionIDs {
+		delete(s.active, id)
+	}
+}
+
+// issue signs a short-lived access token for the session
+func (s *AuthService) issue(user *models.User, sessionID, refreshToken string) (*Tokens, error) {
+	if s.jwtSecret == "" {
+		return nil, errors.New("JWT secret not configured")
+	}
+	claims := jwt.MapClaims{
+		"sub":   user.ID,
+		"sid":   sessionID,
+		"email": user.Email,
+		"name":  user.Name,
+		"role":  user.Role,
+		"exp":   time.Now().Add(accessTokenTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return nil, errors.New("could not generate token")
+	}
+	return &Tokens{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// NewOpaqueToken returns a random URL-safe token to hand to the user and its
+// hash to store. Only the hash is ever written to the database.
+func NewOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes an opaque token for lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sameEmail compares addresses the way users expect
+func sameEmail(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
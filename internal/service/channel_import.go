@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"google.golang.org/api/youtube/v3"
+)
+
+// ImportResult says what an import of a channel's uploads did
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // already on the dashboard, or not viewable
+}
+
+// ImportUploads pages through the channel's uploads playlist and adds every
+// video that is not a project yet as a read-only published project.
+func (s *ChannelService) ImportUploads(ctx context.Context, userID, channelID string) (*ImportResult, error) {
+	ch, err := channelWithPermission(ctx, s.store, channelID, userID, PermChannelManage)
+	if err != nil {
+		return nil, err
+	}
+	if ch.YouTubeAccountID == "" {
+		return nil, conflict("channel has no YouTube account; reconnect it first")
+	}
+
+	ytService, err := s.tokens.YouTube(ctx, ch.YouTubeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ytService.Channels.List([]string{"contentDetails"}).Id(ch.YtChannelID).Context(ctx).Do()
+	if err != nil {
+		return nil, upstream("Failed to fetch channel", err)
+	}
+	if len(resp.Items) == 0 || resp.Items[0].ContentDetails == nil || resp.Items[0].ContentDetails.RelatedPlaylists == nil {
+		return nil, upstream("Failed to fetch channel", errors.New("channel not found on YouTube"))
+	}
+	uploads := resp.Items[0].ContentDetails.RelatedPlaylists.Uploads
+
+	existing, err := s.store.Projects().ListYouTubeIDs(ctx, ch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	result := &ImportResult{}
+	var importErr error
+	err = ytService.PlaylistItems.List([]string{"snippet", "contentDetails"}).
+		PlaylistId(uploads).MaxResults(50).
+		Pages(ctx, func(page *youtube.PlaylistItemListResponse) error {
+			for _, item := range page.Items {
+				imported, err := s.importUpload(ctx, ch, item, seen)
+				if err != nil {
+					importErr = err
+					return err
+				}
+				if imported {
+					result.Imported++
+				} else {
+					result.Skipped++
+				}
+			}
+			return nil
+		})
+	if importErr != nil {
+		return nil, importErr
+	}
+	if err != nil {
+		return nil, upstream("Failed to list uploads", err)
+	}
+	return result, nil
+}
+
+// importUpload creates the project for one playlist item, reporting false
+// for videos already imported and those that are deleted or private to
+// someone else.
+func (s *ChannelService) importUpload(ctx context.Context, ch *models.Channel, item *youtube.PlaylistItem, seen map[string]bool) (bool, error) {
+	if item.ContentDetails == nil || item.Snippet == nil || item.ContentDetails.VideoPublishedAt == "" {
+		return false, nil
+	}
+	videoID := item.ContentDetails.VideoId
+	if videoID == "" || seen[videoID] {
+		return false, nil
+	}
+	publishedAt, err := time.Parse(time.RFC3339, item.ContentDetails.VideoPublishedAt)
+	if err != nil {
+		return false, nil
+	}
+
+	// Imported projects sort by when the video went live, not when we saw it
+	p := &models.Project{
+		ID:          uuid.New().String(),
+		Title:       item.Snippet.Title,
+		Description: item.Snippet.Description,
+		Status:      string(StatusPublished),
+		OwnerID:     ch.OwnerID,
+		ChannelID:   ch.ID,
+		YouTubeID:   videoID,
+		PublishedAt: &publishedAt,
+		Source:      ProjectSourceYouTube,
+		CreatedAt:   publishedAt,
+		UpdatedAt:   publishedAt,
+	}
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := st.Projects().Create(ctx, p); err != nil {
+			return err
+		}
+		return recordEvent(ctx, st, p.ID, "", "", StatusPublished, "Imported from YouTube")
+	})
+	seen[videoID] = true
+	if errors.Is(err, repository.ErrDuplicate) {
+		// Imported concurrently, or published from here meanwhile
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to import video %s: %w", videoID, err)
+	}
+	return true, nil
+}
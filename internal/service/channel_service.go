@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// googleUserInfoURL returns the email of the Google account behind a token
+const googleUserInfoURL = "https://www.googleapis.com/oauth2/v2/userinfo"
+
+const (
+	// oauthStateTTL is how long the owner has to finish Google's consent screen
+	oauthStateTTL = 10 * time.Minute
+	// connectionTTL is how long the frontend has to fetch a connection's channels
+	connectionTTL = 10 * time.Minute
+)
+
+// DiscoveredChannel is a YouTube channel found on one of the owner's Google accounts
+type DiscoveredChannel struct {
+	ID               string `json:"id"` // YouTube channel ID
+	Name             string `json:"name"`
+	IconURL          string `json:"iconUrl"`
+	Email            string `json:"email,omitempty"`
+	YouTubeAccountID string `json:"youtube_account_id,omitempty"`
+}
+
+// ConnectedAccount is one of the owner's Google accounts and the dashboard
+// channels it manages
+type ConnectedAccount struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	// Status is "connected", or "reauth_required" once Google rejected the
+	// account's refresh token and the owner has to connect it again
+	Status           string           `json:"status"`
+	ReauthRequiredAt *time.Time       `json:"reauth_required_at,omitempty"`
+	ExpiresAt        *time.Time       `json:"expires_at,omitempty"` // of the current access token
+	ConnectedAt      time.Time        `json:"connected_at"`
+	Channels         []AccountChannel `json:"channels"`
+}
+
+// AccountChannel is a dashboard channel of a connected account
+type AccountChannel struct {
+	ID          string `json:"id"`
+	YtChannelID string `json:"yt_channel_id"`
+	Name        string `json:"name"`
+	IconURL     string `json:"iconUrl"`
+}
+
+// Connection is what the owner's frontend fetches after connecting a Google account
+type Connection struct {
+	Email    string              `json:"email"`
+	Channels []DiscoveredChannel `json:"channels"`
+}
+
+// ChannelService manages the owner's connected Google accounts and the
+// channels shown on the dashboard.
+type ChannelService struct {
+	store  repository.Store
+	tokens *TokenManager
+	oauth  *oauth2.Config
+}
+
+// NewChannelService creates a ChannelService
+func NewChannelService(store repository.Store, tokens *TokenManager, oauth *oauth2.Config) *ChannelService {
+	return &ChannelService{store: store, tokens: tokens, oauth: oauth}
+}
+
+// CheckPermission reports, as an error, whether the user may do p on the
+// channel: ErrChannelNotFound without a role on it, forbidden when their role
+// lacks p
+func (s *ChannelService) CheckPermission(ctx context.Context, channelID, userID string, p Permission) error {
+	_, err := channelWithPermission(ctx, s.store, channelID, userID, p)
+	return err
+}
+
+// Sidebar returns the workspace's channels the user works on and the people
+// they work with there: owners for editors, editors for owners. Workspace
+// admins and viewers see every channel of the workspace.
+func (s *ChannelService) Sidebar(ctx context.Context, userID, role string, workspace *models.WorkspaceMember) ([]models.Channel, []models.User, error) {
+	if workspace == nil {
+		return []models.Channel{}, []models.User{}, nil
+	}
+	members, err := s.store.Workspaces().ListMembers(ctx, workspace.WorkspaceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	channels := s.store.Channels()
+	var (
+		chs      []models.Channel
+		partners []models.User
+	)
+	switch role {
+	case RoleEditor:
+		if chs, err = channels.ListByEditor(ctx, userID); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch channels: %w", err)
+		}
+		if partners, err = channels.ListOwnersOfEditor(ctx, userID); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch owners: %w", err)
+		}
+	case RoleOwner:
+		if chs, err = channels.ListByOwner(ctx, userID); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch channels: %w", err)
+		}
+		if partners, err = channels.ListEditorsOfOwner(ctx, userID); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch editors: %w", err)
+		}
+	default:
+		return nil, nil, forbidden("Unsupported role")
+	}
+	if seesWholeWorkspace(workspace) {
+		if chs, err = channels.ListByWorkspace(ctx, workspace.WorkspaceID); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch channels: %w", err)
+		}
+	}
+
+	chs = slices.DeleteFunc(chs, func(ch models.Channel) bool { return ch.WorkspaceID != workspace.WorkspaceID })
+	partners = slices.DeleteFunc(partners, func(u models.User) bool { return !isMember[u.ID] })
+	return chs, partners, nil
+}
+
+// AuthURL starts connecting a Google account: it returns the consent screen
+// URL with a single-use state bound to the user and a PKCE challenge.
+func (s *ChannelService) AuthURL(ctx context.Context, userID string) (string, error) {
+	state, hash, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Old states are useless; drop them while we are here
+	if err := s.store.OAuthStates().DeleteExpired(ctx); err != nil {
+		log.Printf("failed to delete expired oauth states: %v", err)
+	}
+	now := time.Now()
+	err = s.store.OAuthStates().Create(ctx, &models.OAuthState{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		StateHash:    hash,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oauthStateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save oauth state: %w", err)
+	}
+	// Offline access with a fresh consent makes Google issue a new refresh
+	// token, which reconnecting a revoked account depends on
+	return s.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier),
+		oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent")), nil
+}
+
+// CompleteAuth handles Google's callback: it consumes the state, exchanges
+// the code and connects the account for the user who started the flow. The
+// discovered channels are kept under the returned connection ID.
+func (s *ChannelService) CompleteAuth(ctx context.Context, state, code string) (string, error) {
+	pending, err := s.store.OAuthStates().Consume(ctx, HashToken(state))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", invalidf("invalid or expired OAuth state")
+		}
+		return "", err
+	}
+
+	token, err := s.oauth.Exchange(ctx, code, oauth2.VerifierOption(pending.CodeVerifier))
+	if err != nil {
+		return "", upstream("Failed to exchange token", err)
+	}
+
+	email, channels, err := s.ConnectAccount(ctx, pending.UserID, token)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(channels)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode channels: %w", err)
+	}
+	if err := s.store.YouTubeConnections().DeleteExpired(ctx); err != nil {
+		log.Printf("failed to delete expired youtube connections: %v", err)
+	}
+	now := time.Now()
+	conn := &models.YouTubeConnection{
+		ID:        uuid.New().String(),
+		UserID:    pending.UserID,
+		Email:     email,
+		Channels:  encoded,
+		ExpiresAt: now.Add(connectionTTL),
+		CreatedAt: now,
+	}
+	if err := s.store.YouTubeConnections().Create(ctx, conn); err != nil {
+		return "", fmt.Errorf("failed to save connection: %w", err)
+	}
+	return conn.ID, nil
+}
+
+// Connection returns the channels found by one of the user's connections.
+// It can be fetched once.
+func (s *ChannelService) Connection(ctx context.Context, userID, id string) (*Connection, error) {
+	conn, err := s.store.YouTubeConnections().Consume(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, notFound("connection not found or already used")
+		}
+		return nil, err
+	}
+
+	result := &Connection{Email: conn.Email}
+	if err := json.Unmarshal(conn.Channels, &result.Channels); err != nil {
+		return nil, fmt.Errorf("failed to decode channels: %w", err)
+	}
+	return result, nil
+}
+
+// ConnectAccount stores the tokens of a Google account the owner just
+// authorised and returns its email and the channels it manages.
+func (s *ChannelService) ConnectAccount(ctx context.Context, userID string, token *oauth2.Token) (string, []DiscoveredChannel, error) {
+	client := s.oauth.Client(ctx, token)
+
+	// Fetch email from Google UserInfo API
+	resp, err := client.Get(googleUserInfoURL)
+	if err != nil {
+		return "", nil, upstream("Failed to fetch user info", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, upstream("Failed to fetch user info", fmt.Errorf("status %d", resp.StatusCode))
+	}
+	var userInfo struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+		return "", nil, upstream("Failed to decode user info", err)
+	}
+
+	account, err := s.tokens.Save(ctx, userID, userInfo.Email, token)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to save tokens: %w", err)
+	}
+
+	ytService, err := youtube.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create YouTube service: %w", err)
+	}
+	channels, err := listMyChannels(ytService, account)
+	if err != nil {
+		return "", nil, err
+	}
+	return account.Email, channels, nil
+}
+
+// Unattached lists the channels of the owner's Google accounts that are not
+// on the dashboard yet. Accounts YouTube rejects are skipped.
+func (s *ChannelService) Unattached(ctx context.Context, userID string) ([]DiscoveredChannel, error) {
+	accounts, err := s.store.YouTubeAccounts().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch YouTube accounts: %w", err)
+	}
+	attached, err := s.store.Channels().ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch channels: %w", err)
+	}
+	isAttached := make(map[string]bool, len(attached))
+	for _, ch := range attached {
+		isAttached[ch.YtChannelID] = true
+	}
+
+	var unattached []DiscoveredChannel
+	for i := range accounts {
+		ytService, err := s.tokens.YouTube(ctx, accounts[i].ID)
+		if err != nil {
+			log.Printf("youtube account %s: %v", accounts[i].Email, err)
+			continue
+		}
+		channels, err := listMyChannels(ytService, &accounts[i])
+		if err != nil {
+			log.Printf("youtube account %s: %v", accounts[i].Email, err)
+			continue
+		}
+		for _, ch := range channels {
+			if !isAttached[ch.ID] {
+				unattached = append(unattached, ch)
+			}
+		}
+	}
+	return unattached, nil
+}
+
+// Accounts lists the owner's connected Google accounts with their channels
+// and whether they need to be reconnected
+func (s *ChannelService) Accounts(ctx context.Context, userID string) ([]ConnectedAccount, error) {
+	accounts, err := s.store.YouTubeAccounts().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch YouTube accounts: %w", err)
+	}
+	channels, err := s.store.Channels().ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch channels: %w", err)
+	}
+	byAccount := make(map[string][]AccountChannel)
+	for _, ch := range channels {
+		byAccount[ch.YouTubeAccountID] = append(byAccount[ch.YouTubeAccountID], AccountChannel{
+			ID:          ch.ID,
+			YtChannelID: ch.YtChannelID,
+			Name:        ch.Name,
+			IconURL:     ch.IconURL,
+		})
+	}
+
+	result := make([]ConnectedAccount, 0, len(accounts))
+	for _, a := range accounts {
+		ca := ConnectedAccount{
+			ID:               a.ID,
+			Email:            a.Email,
+			Status:           "connected",
+			ReauthRequiredAt: a.ReauthRequiredAt,
+			ExpiresAt:        a.ExpiresAt,
+			ConnectedAt:      a.CreatedAt,
+			Channels:         byAccount[a.ID],
+		}
+		if a.ReauthRequiredAt != nil {
+			ca.Status = "reauth_required"
+		}
+		if ca.Channels == nil {
+			ca.Channels = []AccountChannel{}
+		}
+		result = append(result, ca)
+	}
+	return result, nil
+}
+
+// DisconnectAccount revokes our access to one of the owner's Google accounts
+// and forgets its tokens. Its channels stay on the dashboard, detached, until
+// they are added again from a connected account. Revoking is best-effort: the
+// owner can always disconnect, even while Google is unreachable.
+func (s *ChannelService) DisconnectAccount(ctx context.Context, userID, accountID string) error {
+	account, err := s.store.YouTubeAccounts().Get(ctx, accountID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to fetch YouTube account: %w", err)
+	}
+	if account == nil || account.UserID != userID {
+		return notFound("YouTube account not found")
+	}
+
+	if err := s.tokens.Revoke(ctx, account); err != nil {
+		log.Printf("youtube account %s: revoking at Google failed, disconnecting anyway: %v", accountID, err)
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := st.Channels().DetachYouTubeAccount(ctx, accountID); err != nil {
+			return fmt.Errorf("failed to detach channels: %w", err)
+		}
+		err := st.YouTubeAccounts().Delete(ctx, accountID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("YouTube account not found")
+		}
+		return err
+	})
+}
+
+// SetDashboardChannels makes the workspace show exactly the given channels
+// of the owner, adding new ones and removing those no longer selected.
+// Channels the owner keeps in other workspaces are left alone.
+func (s *ChannelService) SetDashboardChannels(ctx context.Context, ownerID string, workspace *models.WorkspaceMember, selected []DiscoveredChannel) error {
+	if workspace == nil {
+		return conflict("Create or join a workspace first")
+	}
+	if workspace.Role != WorkspaceAdmin && workspace.Role != WorkspaceOwner {
+		return forbidden("Only workspace admins and owners can add channels")
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		current, err := st.Channels().ListByOwner(ctx, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch current channels: %w", err)
+		}
+		existing := make(map[string]*models.Channel, len(current))
+		for i := range current {
+			existing[current[i].YtChannelID] = &current[i]
+		}
+		accounts, err := st.YouTubeAccounts().ListByUser(ctx, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch YouTube accounts: %w", err)
+		}
+		ownAccount := make(map[string]bool, len(accounts))
+		for _, a := range accounts {
+			ownAccount[a.ID] = true
+		}
+
+		keep := make(map[string]bool, len(selected))
+		for _, ch := range selected {
+			if ch.YouTubeAccountID != "" && !ownAccount[ch.YouTubeAccountID] {
+				return invalidf("channel %s is not on one of your YouTube accounts", ch.Name)
+			}
+			keep[ch.ID] = true
+			if found, ok := existing[ch.ID]; ok {
+				// Reattaches channels of a disconnected or re-added account
+				if ch.YouTubeAccountID != "" && found.YouTubeAccountID != ch.YouTubeAccountID {
+					if err := st.Channels().SetYouTubeAccount(ctx, found.ID, ch.YouTubeAccountID); err != nil {
+						return fmt.Errorf("failed to reattach channel %s: %w", ch.Name, err)
+					}
+					found.YouTubeAccountID = ch.YouTubeAccountID
+				}
+				continue
+			}
+			created := &models.Channel{
+				ID:               uuid.New().String(),
+				OwnerID:          ownerID,
+				WorkspaceID:      workspace.WorkspaceID,
+				YouTubeAccountID: ch.YouTubeAccountID,
+				YtChannelID:      ch.ID,
+				Name:             ch.Name,
+				IconURL:          ch.IconURL,
+				Email:            ch.Email,
+				CreatedAt:        time.Now(),
+			}
+			if err := st.Channels().Create(ctx, created); err != nil {
+				return fmt.Errorf("failed to add channel %s: %w", ch.Name, err)
+			}
+			existing[ch.ID] = created
+		}
+
+		for _, ch := range current {
+			if ch.WorkspaceID == workspace.WorkspaceID && !keep[ch.YtChannelID] {
+				if err := st.Channels().DeleteByYtChannelID(ctx, ownerID, ch.YtChannelID); err != nil {
+					return fmt.Errorf("failed to remove channel %s: %w", ch.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// listMyChannels lists the channels the account manages
+func listMyChannels(ytService *youtube.Service, account *models.YouTubeAccount) ([]DiscoveredChannel, error) {
+	resp, err := ytService.Channels.List([]string{"snippet"}).Mine(true).Do()
+	if err != nil {
+		return nil, upstream("Failed to fetch channels", err)
+	}
+
+	channels := make([]DiscoveredChannel, 0, len(resp.Items))
+	for _, ch := range resp.Items {
+		dc := DiscoveredChannel{
+			ID:               ch.Id,
+			Name:             ch.Snippet.Title,
+			Email:            account.Email,
+			YouTubeAccountID: account.ID,
+		}
+		if ch.Snippet.Thumbnails != nil && ch.Snippet.Thumbnails.Default != nil {
+			dc.IconURL = ch.Snippet.Thumbnails.Default.Url
+		}
+		channels = append(channels, dc)
+	}
+	return channels, nil
+}
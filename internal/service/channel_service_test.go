@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+const testTokenURL = "https://oauth.test/token"
+
+// fakeGoogle answers the token exchange, userinfo and YouTube calls made
+// through a context carrying it as the oauth2 HTTP client
+type fakeGoogle struct {
+	mu        sync.Mutex
+	verifiers []string // code_verifier of every exchange
+}
+
+func (g *fakeGoogle) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: g})
+}
+
+func (g *fakeGoogle) RoundTrip(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.String() == testTokenURL:
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		g.mu.Lock()
+		g.verifiers = append(g.verifiers, r.PostForm.Get("code_verifier"))
+		g.mu.Unlock()
+		w.WriteString(`{"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 3600}`)
+	case r.URL.String() == googleUserInfoURL:
+		w.WriteString(`{"email": "owner@example.com"}`)
+	case r.URL.Path == "/youtube/v3/channels":
+		w.WriteString(`{"items": [{"id": "UC1", "snippet": {"title": "Main"}}]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+	return w.Result(), nil
+}
+
+func (g *fakeGoogle) exchanges() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.verifiers...)
+}
+
+func newOAuthChannelService(t *testing.T) (*ChannelService, *memory.Store) {
+	st := memory.NewStore()
+	cfg := &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://api.test/api/youtube/callback",
+		Endpoint:     oauth2.Endpoint{AuthURL: "https://accounts.test/auth", TokenURL: testTokenURL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+	return NewChannelService(st, NewTokenManager(st, cfg, testKeyring(t)), cfg), st
+}
+
+func TestCompleteAuth(t *testing.T) {
+	google := &fakeGoogle{}
+	ctx := google.context(context.Background())
+	s, st := newOAuthChannelService(t)
+	ownerID, otherID := uuid.New().String(), uuid.New().String()
+
+	authURL, err := s.AuthURL(ctx, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, challenge := u.Query().Get("state"), u.Query().Get("code_challenge")
+
+	connID, err := s.CompleteAuth(ctx, state, "code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiers := google.exchanges()
+	if len(verifiers) != 1 {
+		t.Fatalf("%d code exchanges, want 1", len(verifiers))
+	}
+	sum := sha256.Sum256([]byte(verifiers[0]))
+	if got := base64.RawURLEncoding.EncodeToString(sum[:]); got != challenge {
+		t.Errorf("exchanged with a verifier for challenge %q, want %q", got, challenge)
+	}
+
+	// The connection belongs to whoever started the flow
+	if _, err := s.Connection(ctx, otherID, connID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("someone else's connection: err = %v, want not found", err)
+	}
+	conn, err := s.Connection(ctx, ownerID, connID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Email != "owner@example.com" || len(conn.Channels) != 1 || conn.Channels[0].ID != "UC1" {
+		t.Errorf("connection = %+v", conn)
+	}
+
+	expired, hash, err := NewOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.OAuthStates().Create(ctx, &models.OAuthState{
+		ID:           uuid.New().String(),
+		UserID:       ownerID,
+		StateHash:    hash,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		ExpiresAt:    time.Now().Add(-time.Minute),
+		CreatedAt:    time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, state := range map[string]string{
+		"replayed": state,
+		"expired":  expired,
+		"foreign":  "state-we-never-issued",
+		"empty":    "",
+	} {
+		if _, err := s.CompleteAuth(ctx, state, "code"); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s state: err = %v, want invalid input", name, err)
+		}
+	}
+	// None of them got as far as spending a code
+	if n := len(google.exchanges()); n != 1 {
+		t.Errorf("%d code exchanges, want 1", n)
+	}
+}
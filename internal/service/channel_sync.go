@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+// maxChannelsPerCall is how many IDs Channels.List accepts at once
+const maxChannelsPerCall = 50
+
+// ChannelSyncer refreshes the channels' names, icons and statistics from YouTube
+type ChannelSyncer struct {
+	store  repository.Store
+	tokens *TokenManager
+}
+
+// NewChannelSyncer creates a ChannelSyncer
+func NewChannelSyncer(store repository.Store, tokens *TokenManager) *ChannelSyncer {
+	return &ChannelSyncer{store: store, tokens: tokens}
+}
+
+// Run syncs every channel now and then every interval until ctx is done
+func (s *ChannelSyncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.SyncAll(ctx); err != nil {
+			log.Printf("channel sync: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll syncs every attached channel, one YouTube call per account and
+// batch. Failures are recorded on the channels they concern.
+func (s *ChannelSyncer) SyncAll(ctx context.Context) error {
+	channels, err := s.store.Channels().ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list channels: %w", err)
+	}
+
+	byAccount := make(map[string][]models.Channel)
+	for _, ch := range channels {
+		byAccount[ch.YouTubeAccountID] = append(byAccount[ch.YouTubeAccountID], ch)
+	}
+	for accountID, chs := range byAccount {
+		for start := 0; start < len(chs); start += maxChannelsPerCall {
+			batch := chs[start:min(start+maxChannelsPerCall, len(chs))]
+			if err := s.syncBatch(ctx, accountID, batch); err != nil {
+				log.Printf("channel sync for account %s: %v", accountID, err)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// SyncChannel syncs a channel the user manages right away and returns it
+func (s *ChannelSyncer) SyncChannel(ctx context.Context, userID, channelID string) (*models.Channel, error) {
+	ch, err := channelWithPermission(ctx, s.store, channelID, userID, PermChannelManage)
+	if err != nil {
+		return nil, err
+	}
+	if ch.YouTubeAccountID == "" {
+		return nil, conflict("channel has no YouTube account; reconnect it first")
+	}
+
+	batch := []models.Channel{*ch}
+	if err := s.syncBatch(ctx, ch.YouTubeAccountID, batch); err != nil {
+		return nil, err
+	}
+	if batch[0].SyncError != "" {
+		return nil, upstream("Failed to sync channel", errors.New(batch[0].SyncError))
+	}
+	return &batch[0], nil
+}
+
+// syncBatch fetches up to maxChannelsPerCall channels of one account and
+// saves the result, or the error, on each of them in place.
+func (s *ChannelSyncer) syncBatch(ctx context.Context, accountID string, batch []models.Channel) error {
+	now := time.Now()
+	fail := func(err error) error {
+		for i := range batch {
+			batch[i].LastSyncedAt, batch[i].SyncError = &now, err.Error()
+			if saveErr := s.store.Channels().SaveSync(ctx, &batch[i]); saveErr != nil {
+				return saveErr
+			}
+		}
+		return err
+	}
+
+	if accountID == "" {
+		return fail(errors.New("channel has no YouTube account"))
+	}
+	ytService, err := s.tokens.YouTube(ctx, accountID)
+	if err != nil {
+		return fail(err)
+	}
+
+	ids := make([]string, len(batch))
+	for i, ch := range batch {
+		ids[i] = ch.YtChannelID
+	}
+	resp, err := ytService.Channels.List([]string{"snippet", "statistics"}).
+		Id(ids...).MaxResults(maxChannelsPerCall).Context(ctx).Do()
+	if err != nil {
+		return fail(err)
+	}
+
+	found := make(map[string]int, len(resp.Items))
+	for i, item := range resp.Items {
+		found[item.Id] = i
+	}
+	for i := range batch {
+		ch := &batch[i]
+		ch.LastSyncedAt, ch.SyncError = &now, ""
+		idx, ok := found[ch.YtChannelID]
+		if !ok {
+			ch.SyncError = "channel not found on YouTube"
+		} else {
+			item := resp.Items[idx]
+			if item.Snippet != nil {
+				ch.Name = item.Snippet.Title
+				if item.Snippet.Thumbnails != nil && item.Snippet.Thumbnails.Default != nil {
+					ch.IconURL = item.Snippet.Thumbnails.Default.Url
+				}
+			}
+			if item.Statistics != nil {
+				ch.SubscriberCount = int64(item.Statistics.SubscriberCount)
+				ch.ViewCount = int64(item.Statistics.ViewCount)
+				ch.VideoCount = int64(item.Statistics.VideoCount)
+			}
+		}
+		if err := s.store.Channels().SaveSync(ctx, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
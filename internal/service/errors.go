@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure the handlers map onto HTTP statuses
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrUpstream     = errors.New("upstream service failed") // YouTube or Google rejected a call
+)
+
+// Error is a failure the caller can act on. Its message is safe to show to
+// clients; Kind is one of the errors above.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Kind }
+
+func notFound(msg string) error  { return &Error{Kind: ErrNotFound, Message: msg} }
+func forbidden(msg string) error { return &Error{Kind: ErrForbidden, Message: msg} }
+func conflict(msg string) error  { return &Error{Kind: ErrConflict, Message: msg} }
+
+func invalidf(format string, args ...any) error {
+	return &Error{Kind: ErrInvalidInput, Message: fmt.Sprintf(format, args...)}
+}
+
+// upstream wraps a failed call to an external API
+func upstream(msg string, err error) error {
+	return &Error{Kind: ErrUpstream, Message: fmt.Sprintf("%s: %v", msg, err)}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+// inviteTTL is how long an editor has to accept an invitation
+const inviteTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInviteNotFound      = &Error{Kind: ErrNotFound, Message: "invite not found or expired"}
+	ErrInviteEmailMismatch = &Error{Kind: ErrForbidden, Message: "invite was sent to a different email address"}
+)
+
+// InviteChannel is a channel an invite grants access to
+type InviteChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Invite is an editor invitation as shown to owners
+type Invite struct {
+	models.EditorInvite
+	Channels  []InviteChannel `json:"channels"`
+	Token     string          `json:"token,omitempty"`      // only returned when the invite is created
+	AcceptURL string          `json:"accept_url,omitempty"` // only returned when the invite is created
+}
+
+// InvitePreview is what the holder of an invite token sees before accepting it
+type InvitePreview struct {
+	Email     string          `json:"email"`
+	OwnerName string          `json:"owner_name"`
+	Channels  []InviteChannel `json:"channels"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// InviteService manages editor invitations and channel assignments
+type InviteService struct {
+	store       repository.Store
+	frontendURL string
+}
+
+// NewInviteService creates an InviteService whose accept links point at frontendURL
+func NewInviteService(store repository.Store, frontendURL string) *InviteService {
+	return &InviteService{store: store, frontendURL: strings.TrimRight(frontendURL, "/")}
+}
+
+// Create lets a user invite an editor, by email, to channels they manage.
+// The editor does not need an account yet; the token can be used at signup.
+func (s *InviteService) Create(ctx context.Context, ownerID, email string, channelIDs []string) (*Invite, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	var channels []models.Channel
+	for _, id := range uniqueStrings(channelIDs) {
+		ch, err := channelWithPermission(ctx, s.store, id, ownerID, PermChannelManage)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return nil, invalidf("You can only invite editors to channels you manage")
+		}
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *ch)
+	}
+
+	token, tokenHash, err := NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &Invite{
+		EditorInvite: models.EditorInvite{
+			ID:        uuid.New().String(),
+			OwnerID:   ownerID,
+			Email:     email,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(inviteTTL),
+			CreatedAt: time.Now(),
+		},
+		Channels:  inviteChannels(channels),
+		Token:     token,
+		AcceptURL: s.frontendURL + "/invite?token=" + token,
+	}
+
+	ids := make([]string, len(channels))
+	for i, ch := range channels {
+		ids[i] = ch.ID
+	}
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := st.Invites().Create(ctx, &invite.EditorInvite, ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := recordMembershipEvent(ctx, st, ownerID, "invited", invite.ID, "", id, email); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// List returns the owner's pending invites
+func (s *InviteService) List(ctx context.Context, ownerID string) ([]Invite, error) {
+	pending, err := s.store.Invites().ListPending(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	invites := make([]Invite, len(pending))
+	for i, inv := range pending {
+		invites[i] = Invite{EditorInvite: inv.EditorInvite, Channels: inviteChannels(inv.Channels)}
+	}
+	return invites, nil
+}
+
+// Revoke cancels a pending invite
+func (s *InviteService) Revoke(ctx context.Context, ownerID, inviteID string) error {
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		inv, err := st.Invites().Revoke(ctx, ownerID, inviteID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Invite not found")
+		}
+		if err != nil {
+			return err
+		}
+		return recordMembershipEvent(ctx, st, ownerID, "invite_revoked", inv.ID, "", "", inv.Email)
+	})
+}
+
+// Preview shows an invite to the person holding its token, before they
+// sign up or log in to accept it.
+func (s *InviteService) Preview(ctx context.Context, token string) (*InvitePreview, error) {
+	inv, err := s.store.Invites().GetPendingByTokenHash(ctx, HashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &InvitePreview{
+		Email:     inv.Email,
+		OwnerName: inv.OwnerName,
+		Channels:  inviteChannels(inv.Channels),
+		ExpiresAt: inv.ExpiresAt,
+	}, nil
+}
+
+// Accept attaches a logged-in editor to the invite's channels
+func (s *InviteService) Accept(ctx context.Context, userID, role, email, token string) error {
+	if role != RoleEditor {
+		return forbidden("Only editors can accept invites")
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		return acceptInvite(ctx, st, token, userID, email)
+	})
+}
+
+// ChannelMember is someone assigned to a channel, as listed to its members
+type ChannelMember struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// Members lists the channel's owner and everyone assigned to it to anyone
+// with a role on the channel
+func (s *InviteService) Members(ctx context.Context, userID, channelID string) ([]ChannelMember, error) {
+	ch, err := channelWithPermission(ctx, s.store, channelID, userID, PermProjectView)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.store.Users().GetByID(ctx, ch.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch owner: %w", err)
+	}
+	editors, err := s.store.Channels().ListEditors(ctx, ch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+
+	members := []ChannelMember{{UserID: owner.ID, Name: owner.Name, Email: owner.Email, Role: RoleOwner}}
+	for _, e := range editors {
+		members = append(members, ChannelMember{UserID: e.ID, Name: e.Name, Email: e.Email, Role: e.Role})
+	}
+	return members, nil
+}
+
+// SetRole changes the role of someone assigned to a channel the actor manages
+func (s *InviteService) SetRole(ctx context.Context, actorID, channelID, editorID, role string) error {
+	if !AssignableRole(role) {
+		return invalidf("role must be manager, reviewer, editor or viewer")
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		ch, err := channelWithPermission(ctx, st, channelID, actorID, PermChannelManage)
+		if err != nil {
+			return err
+		}
+		err = st.Channels().SetEditorRole(ctx, ch.ID, editorID, role)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Editor is not assigned to this channel")
+		}
+		if err != nil {
+			return err
+		}
+		return recordMembershipEvent(ctx, st, actorID, "role_changed", "", editorID, ch.ID, "")
+	})
+}
+
+// RemoveEditor detaches an editor from a channel the actor manages
+func (s *InviteService) RemoveEditor(ctx context.Context, ownerID, channelID, editorID string) error {
+	errNotAssigned := notFound("Editor is not assigned to this channel")
+
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		ch, err := channelWithPermission(ctx, st, channelID, ownerID, PermChannelManage)
+		if errors.Is(err, ErrNotFound) {
+			return errNotAssigned
+		}
+		if err != nil {
+			return err
+		}
+
+		err = st.Channels().RemoveEditor(ctx, ch.ID, editorID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errNotAssigned
+		}
+		if err != nil {
+			return err
+		}
+		return recordMembershipEvent(ctx, st, ownerID, "editor_removed", "", editorID, ch.ID, "")
+	})
+}
+
+// acceptInvite redeems an invite token for the given user inside st's
+// transaction. The user's email must match the address the invite was sent to.
+func acceptInvite(ctx context.Context, st repository.Store, token, userID, email string) error {
+	inv, err := st.Invites().GetPendingByTokenHash(ctx, HashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInviteNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !sameEmail(inv.Email, email) {
+		return ErrInviteEmailMismatch
+	}
+
+	var added []string
+	for _, ch := range inv.Channels {
+		ok, err := st.Channels().AddEditor(ctx, ch.ID, userID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			added = append(added, ch.ID)
+		}
+		// Editors join the channel's workspace; existing members keep their role
+		_, err = st.Workspaces().AddMember(ctx, &models.WorkspaceMember{
+			WorkspaceID: ch.WorkspaceID,
+			UserID:      userID,
+			Role:        WorkspaceEditor,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := st.Invites().MarkAccepted(ctx, inv.ID, userID); err != nil {
+		return err
+	}
+	for _, channelID := range added {
+		if err := recordMembershipEvent(ctx, st, userID, "invite_accepted", inv.ID, userID, channelID, inv.Email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordMembershipEvent appends to the channel membership audit log
+func recordMembershipEvent(ctx context.Context, st repository.Store, actorID, action, inviteID, editorID, channelID, email string) error {
+	return st.Channels().RecordMembershipEvent(ctx, &models.ChannelMembershipEvent{
+		ID:        uuid.New().String(),
+		ActorID:   actorID,
+		Action:    action,
+		InviteID:  inviteID,
+		EditorID:  editorID,
+		ChannelID: channelID,
+		Email:     email,
+		CreatedAt: time.Now(),
+	})
+}
+
+func inviteChannels(channels []models.Channel) []InviteChannel {
+	out := make([]InviteChannel, len(channels))
+	for i, ch := range channels {
+		out[i] = InviteChannel{ID: ch.ID, Name: ch.Name}
+	}
+	return out
+}
+
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
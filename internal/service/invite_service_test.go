@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+func TestAcceptInvite(t *testing.T) {
+	const invited = "ed@example.com"
+	tests := []struct {
+		name    string
+		role    string
+		email   string
+		prepare func(t *testing.T, s *InviteService, st *memory.Store, inv *Invite) string // returns the token to accept
+		want    error
+	}{
+		{name: "accepted", role: RoleEditor, email: "Ed@Example.com"},
+		{name: "owner account", role: RoleOwner, email: invited, want: ErrForbidden},
+		{name: "other address", role: RoleEditor, email: "someone@example.com", want: ErrInviteEmailMismatch},
+		{name: "unknown token", role: RoleEditor, email: invited, want: ErrInviteNotFound,
+			prepare: func(t *testing.T, s *InviteService, st *memory.Store, inv *Invite) string { return "made-up" }},
+		{name: "revoked", role: RoleEditor, email: invited, want: ErrInviteNotFound,
+			prepare: func(t *testing.T, s *InviteService, st *memory.Store, inv *Invite) string {
+				if err := s.Revoke(context.Background(), inv.OwnerID, inv.ID); err != nil {
+					t.Fatal(err)
+				}
+				return inv.Token
+			}},
+		{name: "used", role: RoleEditor, email: invited, want: ErrInviteNotFound,
+			prepare: func(t *testing.T, s *InviteService, st *memory.Store, inv *Invite) string {
+				if err := s.Accept(context.Background(), uuid.New().String(), RoleEditor, invited, inv.Token); err != nil {
+					t.Fatal(err)
+				}
+				return inv.Token
+			}},
+		{name: "expired", role: RoleEditor, email: invited, want: ErrInviteNotFound,
+			prepare: func(t *testing.T, s *InviteService, st *memory.Store, inv *Invite) string {
+				token, hash, err := NewOpaqueToken()
+				if err != nil {
+					t.Fatal(err)
+				}
+				expired := inv.EditorInvite
+				expired.ID, expired.TokenHash = uuid.New().String(), hash
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				if err := st.Invites().Create(context.Background(), &expired, []string{inv.Channels[0].ID}); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			st := memory.NewStore()
+			s := NewInviteService(st, "http://frontend.test")
+			owner := &models.User{ID: uuid.New().String(), Name: "Owner", Email: "owner@example.com", Role: RoleOwner, CreatedAt: time.Now()}
+			if err := st.Users().Create(ctx, owner); err != nil {
+				t.Fatal(err)
+			}
+			ws, err := createWorkspace(ctx, st, owner.ID, "Studio")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ch := &models.Channel{ID: uuid.New().String(), OwnerID: owner.ID, WorkspaceID: ws.ID, YtChannelID: "UC1", CreatedAt: time.Now()}
+			if err := st.Channels().Create(ctx, ch); err != nil {
+				t.Fatal(err)
+			}
+			inv, err := s.Create(ctx, owner.ID, " ED@example.com ", []string{ch.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			token := inv.Token
+			if tt.prepare != nil {
+				token = tt.prepare(t, s, st, inv)
+			}
+
+			editorID := uuid.New().String()
+			err = s.Accept(ctx, editorID, tt.role, tt.email, token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			role, err := st.Channels().EditorRole(ctx, ch.ID, editorID)
+			if tt.want != nil {
+				if err == nil {
+					t.Errorf("assigned to the channel as %q", role)
+				}
+				return
+			}
+			if err != nil || role != RoleEditor {
+				t.Errorf("channel role = %q, %v, want editor", role, err)
+			}
+			if _, err := s.Preview(ctx, token); !errors.Is(err, ErrInviteNotFound) {
+				t.Errorf("preview of the accepted invite: err = %v, want ErrInviteNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultMaxAttempts is how often a job runs before it is left dead
+	defaultMaxAttempts = 5
+	// jobLease is how long a job may run before another worker assumes its
+	// worker died and takes it over; it has to outlast a video upload
+	jobLease = 2 * time.Hour
+	// retryBaseDelay doubles after every failed attempt, up to retryMaxDelay
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+// JobHandler does the work of one kind of job. Returning an error retries
+// the job later, until it runs out of attempts.
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// JobQueue runs the jobs stored in the jobs table. Any number of servers may
+// run workers against the same database; each job is claimed by one of them.
+type JobQueue struct {
+	store    repository.Store
+	handlers map[string]JobHandler
+	workerID string
+}
+
+// NewJobQueue creates a JobQueue with no handlers
+func NewJobQueue(store repository.Store) *JobQueue {
+	host, _ := os.Hostname()
+	return &JobQueue{
+		store:    store,
+		handlers: make(map[string]JobHandler),
+		workerID: fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// Handle registers the handler for a kind of job. It must be called before Run.
+func (q *JobQueue) Handle(kind string, fn JobHandler) {
+	q.handlers[kind] = fn
+}
+
+// enqueueJob queues a job to run at runAt. A non-empty key identifies what
+// the job is about so it can be cancelled or rescheduled; ErrDuplicate is
+// returned while another job with the same key is pending.
+func enqueueJob(ctx context.Context, st repository.Store, kind, key string, payload any, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return st.Jobs().Enqueue(ctx, &models.Job{
+		ID:          uuid.New().String(),
+		Kind:        kind,
+		Key:         key,
+		Payload:     data,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       runAt,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// Run starts workers that poll for due jobs every poll interval, and
+// returns once ctx is done and the jobs they were running have finished.
+func (q *JobQueue) Run(ctx context.Context, workers int, poll time.Duration) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		// Each worker holds its jobs under its own ID
+		workerID := fmt.Sprintf("%s/%d", q.workerID, i)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, workerID, poll)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *JobQueue) work(ctx context.Context, workerID string, poll time.Duration) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		// Drain every due job before sleeping again
+		for ctx.Err() == nil {
+			ran, err := q.RunNext(ctx, workerID)
+			if err != nil {
+				log.Printf("job queue: %v", err)
+				break
+			}
+			if !ran {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNext claims and runs one due job as workerID, reporting whether there
+// was one
+func (q *JobQueue) RunNext(ctx context.Context, workerID string) (bool, error) {
+	job, err := q.store.Jobs().Claim(ctx, workerID, time.Now().Add(-jobLease))
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	runErr := q.run(ctx, job)
+	// The outcome is recorded even when shutting down, or the job would sit
+	// running until its lease ran out and then run again
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case runErr == nil:
+		err = q.store.Jobs().Complete(ctx, job.ID, workerID)
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("job %s (%s) failed for good after %d attempts: %v", job.ID, job.Kind, job.Attempts, runErr)
+		err = q.store.Jobs().Bury(ctx, job.ID, workerID, runErr.Error())
+	default:
+		log.Printf("job %s (%s) attempt %d failed: %v", job.ID, job.Kind, job.Attempts, runErr)
+		err = q.store.Jobs().Retry(ctx, job.ID, workerID, time.Now().Add(retryDelay(job.Attempts)), runErr.Error())
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("job %s (%s) was taken over by another worker; not recording this run", job.ID, job.Kind)
+		return true, nil
+	}
+	return true, err
+}
+
+// run calls the job's handler, turning a panic into an error
+func (q *JobQueue) run(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s (%s) panicked: %v\n%s", job.ID, job.Kind, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// retryDelay is the exponential backoff after the given attempt, with up to
+// 10% jitter so jobs that failed together do not retry together
+func retryDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 12 {
+		delay = min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	}
+	return delay + rand.N(delay/10+1)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+// testJob queues a job of kind "test" under key that may run maxAttempts times
+func testJob(t *testing.T, st repository.Store, key string, maxAttempts int) {
+	t.Helper()
+	err := st.Jobs().Enqueue(context.Background(), &models.Job{
+		ID:          uuid.New().String(),
+		Kind:        "test",
+		Key:         key,
+		Payload:     []byte("{}"),
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now(),
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cancelAwareStore fails job updates on a cancelled context like the
+// postgres store does
+type cancelAwareStore struct {
+	*memory.Store
+}
+
+func (s cancelAwareStore) Jobs() repository.JobRepository {
+	return cancelAwareJobs{s.Store.Jobs()}
+}
+
+type cancelAwareJobs struct {
+	repository.JobRepository
+}
+
+func (j cancelAwareJobs) Complete(ctx context.Context, id, workerID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return j.JobRepository.Complete(ctx, id, workerID)
+}
+
+func (j cancelAwareJobs) Retry(ctx context.Context, id, workerID string, runAt time.Time, lastErr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return j.JobRepository.Retry(ctx, id, workerID, runAt, lastErr)
+}
+
+func (j cancelAwareJobs) Bury(ctx context.Context, id, workerID string, lastErr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return j.JobRepository.Bury(ctx, id, workerID, lastErr)
+}
+
+func TestRunNextRecordsOutcomeDuringShutdown(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		err         error
+		maxAttempts int
+		wantQueued  bool // retried, rather than done with
+	}{
+		{name: "succeeded", maxAttempts: 5},
+		{name: "retried", err: errors.New("boom"), maxAttempts: 5, wantQueued: true},
+		{name: "buried", err: errors.New("boom"), maxAttempts: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			st := cancelAwareStore{memory.NewStore()}
+			q := NewJobQueue(st)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			q.Handle("test", func(context.Context, *models.Job) error {
+				cancel() // the server is told to stop while the job runs
+				return tt.err
+			})
+			testJob(t, st, "test:1", tt.maxAttempts)
+
+			if _, err := q.RunNext(ctx, "worker-1"); err != nil {
+				t.Fatal(err)
+			}
+			job, err := st.Jobs().GetActiveByKey(context.Background(), "test:1")
+			switch {
+			case tt.wantQueued && (err != nil || job.Status != "queued"):
+				t.Errorf("job = %+v, %v; want it queued again", job, err)
+			case !tt.wantQueued && !errors.Is(err, repository.ErrNotFound):
+				t.Errorf("job = %+v, %v; want it finished", job, err)
+			}
+		})
+	}
+}
+
+func TestRunNextAfterTakeover(t *testing.T) {
+	st := memory.NewStore()
+	q := NewJobQueue(st)
+	ctx := context.Background()
+	q.Handle("test", func(ctx context.Context, job *models.Job) error {
+		// The lease ran out and another worker took the job over
+		if _, err := st.Jobs().Claim(ctx, "worker-2", time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		return nil
+	})
+	testJob(t, st, "test:1", 5)
+
+	if _, err := q.RunNext(ctx, "worker-1"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := st.Jobs().GetActiveByKey(ctx, "test:1")
+	if err != nil || job.Status != "running" || job.LockedBy != "worker-2" {
+		t.Errorf("job = %+v, %v; want it still running by worker-2", job, err)
+	}
+}
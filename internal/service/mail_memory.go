@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+)
+
+// MemoryMailer keeps mail in memory instead of sending it, for tests and
+// development
+type MemoryMailer struct {
+	logMail bool
+
+	mu   sync.Mutex
+	sent []Mail
+}
+
+// NewMemoryMailer creates a MemoryMailer. With logMail the recipient and
+// subject of every message are logged; bodies never are, as they carry
+// verification and reset tokens.
+func NewMemoryMailer(logMail bool) *MemoryMailer {
+	return &MemoryMailer{logMail: logMail}
+}
+
+// Send records m
+func (s *MemoryMailer) Send(ctx context.Context, m Mail) error {
+	s.mu.Lock()
+	s.sent = append(s.sent, m)
+	s.mu.Unlock()
+	if s.logMail {
+		log.Printf("mail to %s (not sent, MAIL_BACKEND=memory): %s", m.To, m.Subject)
+	}
+	return nil
+}
+
+// Sent returns every message sent so far, oldest first
+func (s *MemoryMailer) Sent() []Mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.sent)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+)
+
+// Mail is a plain text email
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends the emails the app needs, such as verification and password
+// reset links
+type Mailer interface {
+	Send(ctx context.Context, m Mail) error
+}
+
+// Mail backends selectable with MAIL_BACKEND
+const (
+	MailSMTP   = "smtp"
+	MailMemory = "memory"
+)
+
+// NewMailer builds the backend named by cfg.Backend
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Backend {
+	case MailSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case MailMemory:
+		return NewMemoryMailer(true), nil
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+var errNoteNotFound = &Error{Kind: ErrNotFound, Message: "Note not found"}
+
+// NoteThread is a top-level note with the replies to it
+type NoteThread struct {
+	models.Note
+	Replies []models.Note `json:"replies,omitempty"`
+}
+
+// AddNoteInput is a new note, or a reply when ParentID is set
+type AddNoteInput struct {
+	Timestamp    *int // seconds; required for top-level notes
+	EndTimestamp *int // optional end of a time range
+	Content      string
+	ParentID     string
+}
+
+// UpdateNoteInput is an edit of a note's text or time range. Fields left
+// nil keep their value.
+type UpdateNoteInput struct {
+	Timestamp    *int
+	EndTimestamp *int
+	ClearEnd     bool // turn a time range back into a single point
+	Content      string
+}
+
+// NoteService manages the review notes left on projects
+type NoteService struct {
+	store repository.Store
+}
+
+// NewNoteService creates a NoteService
+func NewNoteService(store repository.Store) *NoteService {
+	return &NoteService{store: store}
+}
+
+// List returns a project's notes ordered by timestamp, with replies nested
+// under the note they answer.
+func (s *NoteService) List(ctx context.Context, projectID string) ([]NoteThread, error) {
+	all, err := s.store.Notes().List(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Threads are one level deep: replies always point at a top-level note
+	replies := make(map[string][]models.Note)
+	for _, n := range all {
+		if n.ParentID != nil {
+			replies[*n.ParentID] = append(replies[*n.ParentID], n)
+		}
+	}
+	threads := []NoteThread{}
+	for _, n := range all {
+		if n.ParentID == nil {
+			threads = append(threads, NoteThread{Note: n, Replies: replies[n.ID]})
+		}
+	}
+	return threads, nil
+}
+
+// Add lets the owner leave a note on a project, and the owner or editor
+// reply to an existing note.
+func (s *NoteService) Add(ctx context.Context, access *ProjectAccess, userID string, in AddNoteInput) (*models.Note, error) {
+	if access.ReadOnly {
+		return nil, ErrProjectReadOnly
+	}
+
+	now := time.Now()
+	note := &models.Note{
+		ID:           uuid.New().String(),
+		ProjectID:    access.ProjectID,
+		UserID:       userID,
+		EndTimestamp: in.EndTimestamp,
+		Content:      in.Content,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if in.ParentID != "" {
+		// Replies sit at the same point in the video as the note they answer
+		parent, err := s.store.Notes().Get(ctx, access.ProjectID, in.ParentID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && parent.ParentID != nil) {
+			return nil, notFound("Note to reply to not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch note: %w", err)
+		}
+		note.ParentID = &parent.ID
+		note.Timestamp = parent.Timestamp
+		note.EndTimestamp = parent.EndTimestamp
+	} else {
+		if !access.Can(PermProjectApprove) {
+			return nil, forbidden("Only reviewers can add notes; editors can reply")
+		}
+		if in.Timestamp == nil {
+			return nil, invalidf("timestamp is required")
+		}
+		note.Timestamp = *in.Timestamp
+	}
+
+	if err := checkRange(note); err != nil {
+		return nil, err
+	}
+	if err := s.store.Notes().Create(ctx, note); err != nil {
+		return nil, fmt.Errorf("failed to add note: %w", err)
+	}
+	return note, nil
+}
+
+// Update lets the author edit a note's text or time range
+func (s *NoteService) Update(ctx context.Context, access *ProjectAccess, userID, noteID string, in UpdateNoteInput) (*models.Note, error) {
+	if access.ReadOnly {
+		return nil, ErrProjectReadOnly
+	}
+	if in.ClearEnd && in.EndTimestamp != nil {
+		return nil, invalidf("end_timestamp cannot be set and cleared at once")
+	}
+
+	var note *models.Note
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		if note, err = loadOwnNote(ctx, st, access.ProjectID, userID, noteID); err != nil {
+			return err
+		}
+
+		// Replies follow their parent's position, so only the text can change
+		if note.ParentID == nil {
+			if in.Timestamp != nil {
+				note.Timestamp = *in.Timestamp
+			}
+			switch {
+			case in.ClearEnd:
+				note.EndTimestamp = nil
+			case in.EndTimestamp != nil:
+				note.EndTimestamp = in.EndTimestamp
+			}
+			if err := checkRange(note); err != nil {
+				return err
+			}
+		}
+		note.Content = in.Content
+		note.UpdatedAt = time.Now()
+
+		if err := st.Notes().Update(ctx, note); err != nil {
+			return fmt.Errorf("failed to update note: %w", err)
+		}
+		// Keep replies aligned with the note they answer
+		if note.ParentID == nil {
+			if err := st.Notes().MoveReplies(ctx, note.ID, note.Timestamp, note.EndTimestamp); err != nil {
+				return fmt.Errorf("failed to update replies: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Delete lets the author delete a note together with its replies
+func (s *NoteService) Delete(ctx context.Context, access *ProjectAccess, userID, noteID string) error {
+	if access.ReadOnly {
+		return ErrProjectReadOnly
+	}
+	note, err := loadOwnNote(ctx, s.store, access.ProjectID, userID, noteID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Notes().Delete(ctx, note.ID); err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	return nil
+}
+
+// Resolve marks a top-level note as resolved or unresolved, e.g. when the
+// editor has fixed what the note asked for.
+func (s *NoteService) Resolve(ctx context.Context, access *ProjectAccess, userID, noteID string, resolved bool) error {
+	if access.ReadOnly {
+		return ErrProjectReadOnly
+	}
+	note, err := s.store.Notes().Get(ctx, access.ProjectID, noteID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && note.ParentID != nil) {
+		return errNoteNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch note: %w", err)
+	}
+
+	now := time.Now()
+	note.Resolved = resolved
+	note.ResolvedBy, note.ResolvedAt = nil, nil
+	if resolved {
+		note.ResolvedBy, note.ResolvedAt = &userID, &now
+	}
+	note.UpdatedAt = now
+	if err := s.store.Notes().Update(ctx, note); err != nil {
+		return fmt.Errorf("failed to update note: %w", err)
+	}
+	return nil
+}
+
+// loadOwnNote fetches a note of the project and checks the caller wrote it
+func loadOwnNote(ctx context.Context, st repository.Store, projectID, userID, noteID string) (*models.Note, error) {
+	note, err := st.Notes().Get(ctx, projectID, noteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errNoteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch note: %w", err)
+	}
+	if note.UserID != userID {
+		return nil, forbidden("You can only change your own notes")
+	}
+	return note, nil
+}
+
+func checkRange(n *models.Note) error {
+	if n.EndTimestamp != nil && *n.EndTimestamp < n.Timestamp {
+		return invalidf("end_timestamp must not be before timestamp")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+// rangedNote stores a note on 10-20s by userID and a reply to it
+func rangedNote(t *testing.T, st repository.Store, projectID, userID string) (*models.Note, *models.Note) {
+	t.Helper()
+	ctx := context.Background()
+	end := 20
+	note := &models.Note{ID: uuid.New().String(), ProjectID: projectID, UserID: userID, Timestamp: 10, EndTimestamp: &end, Content: "Trim this", CreatedAt: time.Now()}
+	reply := &models.Note{ID: uuid.New().String(), ProjectID: projectID, UserID: userID, ParentID: &note.ID, Timestamp: 10, EndTimestamp: &end, Content: "Done", CreatedAt: time.Now()}
+	for _, n := range []*models.Note{note, reply} {
+		if err := st.Notes().Create(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return note, reply
+}
+
+func TestUpdateNoteRange(t *testing.T) {
+	end := func(n int) *int { return &n }
+	tests := []struct {
+		name    string
+		in      UpdateNoteInput
+		wantEnd *int
+	}{
+		{name: "text only", in: UpdateNoteInput{Content: "Trim more"}, wantEnd: end(20)},
+		{name: "new end", in: UpdateNoteInput{Content: "Trim more", EndTimestamp: end(30)}, wantEnd: end(30)},
+		{name: "cleared", in: UpdateNoteInput{Content: "Trim more", ClearEnd: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			st := memory.NewStore()
+			s := NewNoteService(st)
+			access := &ProjectAccess{ProjectID: uuid.New().String()}
+			userID := uuid.New().String()
+			note, reply := rangedNote(t, st, access.ProjectID, userID)
+
+			if _, err := s.Update(ctx, access, userID, note.ID, tt.in); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []string{note.ID, reply.ID} {
+				got, err := st.Notes().Get(ctx, access.ProjectID, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (got.EndTimestamp == nil) != (tt.wantEnd == nil) || (got.EndTimestamp != nil && *got.EndTimestamp != *tt.wantEnd) {
+					t.Errorf("note %s ends at %v, want %v", id, got.EndTimestamp, tt.wantEnd)
+				}
+			}
+		})
+	}
+}
+
+func TestNotesOnReadOnlyProject(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewNoteService(st)
+	access := &ProjectAccess{ProjectID: uuid.New().String(), Role: RoleOwner, ReadOnly: true}
+	userID := uuid.New().String()
+	note, _ := rangedNote(t, st, access.ProjectID, userID)
+
+	for name, call := range map[string]func() error{
+		"add": func() error {
+			_, err := s.Add(ctx, access, userID, AddNoteInput{Content: "Hi", ParentID: note.ID})
+			return err
+		},
+		"update": func() error {
+			_, err := s.Update(ctx, access, userID, note.ID, UpdateNoteInput{Content: "Hi"})
+			return err
+		},
+		"delete":  func() error { return s.Delete(ctx, access, userID, note.ID) },
+		"resolve": func() error { return s.Resolve(ctx, access, userID, note.ID, true) },
+	} {
+		if err := call(); !errors.Is(err, ErrProjectReadOnly) {
+			t.Errorf("%s: err = %v, want ErrProjectReadOnly", name, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+// Permission names something a user may do on a channel or its projects
+type Permission string
+
+const (
+	PermProjectView     Permission = "project.view"     // see projects, notes, history and analytics
+	PermProjectCreate   Permission = "project.create"   // start projects on the channel
+	PermProjectUpload   Permission = "project.upload"   // upload versions and submit them for review
+	PermProjectMetadata Permission = "project.metadata" // edit title, description and thumbnail for YouTube
+	PermProjectApprove  Permission = "project.approve"  // review: request changes or approve
+	PermProjectPublish  Permission = "project.publish"  // publish, schedule, archive and sync with YouTube
+	PermNoteWrite       Permission = "note.write"       // reply to, edit and resolve notes
+	PermChannelManage   Permission = "channel.manage"   // invite members, assign roles, sync and import
+)
+
+// Roles group permissions. A channel's owner (channels.owner_id) has
+// RoleOwner on it; everyone else assigned to it has the role stored with
+// their assignment. RoleOwner and RoleEditor are also the global account
+// roles in users.role. RoleSystem is used by background work such as
+// scheduled publishing.
+const (
+	RoleOwner    = "owner"
+	RoleManager  = "manager"
+	RoleReviewer = "reviewer"
+	RoleEditor   = "editor"
+	RoleViewer   = "viewer"
+	RoleSystem   = "system"
+)
+
+// ErrChannelNotFound is returned for channels the caller has no role on
+var ErrChannelNotFound = &Error{Kind: ErrNotFound, Message: "channel not found"}
+
+// rolePermissions lists what each role may do
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermProjectView, PermProjectMetadata, PermProjectApprove, PermProjectPublish,
+		PermNoteWrite, PermChannelManage,
+	},
+	RoleManager: {
+		PermProjectView, PermProjectMetadata, PermProjectApprove, PermProjectPublish,
+		PermNoteWrite, PermChannelManage,
+	},
+	RoleReviewer: {PermProjectView, PermProjectApprove, PermNoteWrite},
+	RoleEditor: {
+		PermProjectView, PermProjectCreate, PermProjectUpload, PermProjectMetadata,
+		PermNoteWrite,
+	},
+	RoleViewer: {PermProjectView},
+	RoleSystem: {PermProjectPublish},
+}
+
+// RoleInfo describes a role to clients
+type RoleInfo struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	Assignable  bool         `json:"assignable"` // can be given to channel members
+}
+
+// Roles lists the channel roles and their permissions
+func Roles() []RoleInfo {
+	var roles []RoleInfo
+	for _, name := range []string{RoleOwner, RoleManager, RoleReviewer, RoleEditor, RoleViewer} {
+		roles = append(roles, RoleInfo{
+			Name:        name,
+			Permissions: rolePermissions[name],
+			Assignable:  AssignableRole(name),
+		})
+	}
+	return roles
+}
+
+// RoleHas reports whether role grants p
+func RoleHas(role string, p Permission) bool {
+	return slices.Contains(rolePermissions[role], p)
+}
+
+// AssignableRole reports whether role can be given to a channel member.
+// Ownership follows the YouTube account the channel was added from.
+func AssignableRole(role string) bool {
+	switch role {
+	case RoleManager, RoleReviewer, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// channelRole works out the user's role on a channel: owner, their assigned
+// role, viewer for admins and viewers of the channel's workspace, or empty
+// when they have no part in it.
+func channelRole(ctx context.Context, st repository.Store, ch *models.Channel, userID string) (string, error) {
+	if ch.OwnerID == userID {
+		return RoleOwner, nil
+	}
+	role, err := st.Channels().EditorRole(ctx, ch.ID, userID)
+	if err == nil {
+		return role, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+	m, err := st.Workspaces().GetMember(ctx, ch.WorkspaceID, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+	if seesWholeWorkspace(m) {
+		return RoleViewer, nil
+	}
+	return "", nil
+}
+
+// channelWithPermission fetches a channel the user may do p on. Channels
+// they have no role on are reported as ErrChannelNotFound.
+func channelWithPermission(ctx context.Context, st repository.Store, channelID, userID string, p Permission) (*models.Channel, error) {
+	ch, err := st.Channels().Get(ctx, channelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrChannelNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	role, err := channelRole(ctx, st, ch, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrChannelNotFound
+	}
+	if !RoleHas(role, p) {
+		return nil, forbidden("You are not allowed to do this on this channel")
+	}
+	return ch, nil
+}
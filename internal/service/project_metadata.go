@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+const (
+	// DefaultCategoryID is People & Blogs, what videos were published as before
+	// projects carried a category
+	DefaultCategoryID = "22"
+	// LicenseYouTube and LicenseCreativeCommon are the licenses YouTube accepts
+	LicenseYouTube        = "youtube"
+	LicenseCreativeCommon = "creativeCommon"
+
+	// YouTube limits
+	maxTagsLength      = 500
+	maxPlaylists       = 20
+	maxThumbnailSize   = 2 << 20
+	thumbnailURLExpiry = time.Hour
+
+	// JobApplyMetadata sets a published video's thumbnail and adds it to its
+	// playlists, which YouTube only allows once the video exists
+	JobApplyMetadata = "project.apply_metadata"
+)
+
+var (
+	categoryIDPattern = regexp.MustCompile(`^[0-9]{1,3}$`)
+	languagePattern   = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	playlistIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{10,64}$`)
+)
+
+// ProjectMetadata is a project's publish metadata with a link to its thumbnail
+type ProjectMetadata struct {
+	models.ProjectMetadata
+	ThumbnailURL string `json:"thumbnail_url,omitempty"` // signed, for reviewers
+}
+
+// MetadataInput replaces a project's publish metadata. Empty fields get
+// YouTube's defaults.
+type MetadataInput struct {
+	Tags            []string
+	CategoryID      string
+	DefaultLanguage string
+	MadeForKids     bool
+	License         string
+	PlaylistIDs     []string
+}
+
+// Metadata returns the project's publish metadata, or the defaults if none
+// was filled in
+func (s *ProjectService) Metadata(ctx context.Context, projectID string) (*ProjectMetadata, error) {
+	m, err := loadMetadata(ctx, s.store, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	result := &ProjectMetadata{ProjectMetadata: *m}
+	if m.ThumbnailPath != "" {
+		// A missing thumbnail should not hide the rest
+		if url, err := s.videos.SignedURL(ctx, m.ThumbnailPath, thumbnailURLExpiry); err == nil {
+			result.ThumbnailURL = url
+		}
+	}
+	return result, nil
+}
+
+// UpdateMetadata replaces the project's publish metadata. It can change
+// until the project is approved, so the owner approves it with the video.
+func (s *ProjectService) UpdateMetadata(ctx context.Context, access *ProjectAccess, userID string, in MetadataInput) (*ProjectMetadata, error) {
+	if err := in.normalize(); err != nil {
+		return nil, err
+	}
+	err := s.editMetadata(ctx, access, userID, func(m *models.ProjectMetadata) {
+		m.Tags, m.CategoryID, m.DefaultLanguage = in.Tags, in.CategoryID, in.DefaultLanguage
+		m.MadeForKids, m.License, m.PlaylistIDs = in.MadeForKids, in.License, in.PlaylistIDs
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Metadata(ctx, access.ProjectID)
+}
+
+// SetThumbnail stores a custom thumbnail for the project, a JPEG or PNG of
+// at most 2 MB as YouTube requires
+func (s *ProjectService) SetThumbnail(ctx context.Context, access *ProjectAccess, userID string, r io.Reader, filename string, size int64, contentType string) (*ProjectMetadata, error) {
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, invalidf("thumbnail must be a JPEG or PNG image")
+	}
+	if size > maxThumbnailSize {
+		return nil, invalidf("thumbnail must be at most 2 MB")
+	}
+
+	key := fmt.Sprintf("projects/%s/thumbnails/%s%s", access.ProjectID, uuid.New().String(), filepath.Ext(filename))
+	if err := s.videos.Put(ctx, key, r, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+	var previous string
+	err := s.editMetadata(ctx, access, userID, func(m *models.ProjectMetadata) {
+		previous, m.ThumbnailPath = m.ThumbnailPath, key
+	})
+	if err != nil {
+		s.videos.Delete(ctx, key)
+		return nil, err
+	}
+	if previous != "" {
+		s.videos.Delete(ctx, previous)
+	}
+	return s.Metadata(ctx, access.ProjectID)
+}
+
+// editMetadata applies edit to the project's metadata while the project is
+// locked and still under review
+func (s *ProjectService) editMetadata(ctx context.Context, access *ProjectAccess, userID string, edit func(m *models.ProjectMetadata)) error {
+	if access.ReadOnly {
+		return ErrProjectReadOnly
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		p, err := st.Projects().GetForUpdate(ctx, access.ProjectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		switch ProjectStatus(p.Status) {
+		case StatusDraft, StatusSubmitted, StatusInReview, StatusChangesRequested:
+		default:
+			return conflict("Metadata cannot change once the project is " + p.Status)
+		}
+
+		m, err := loadMetadata(ctx, st, p.ID)
+		if err != nil {
+			return err
+		}
+		edit(m)
+		m.UpdatedBy = userID
+		m.UpdatedAt = time.Now()
+		return st.Projects().SaveMetadata(ctx, m)
+	})
+}
+
+// loadMetadata returns the stored metadata or the defaults
+func loadMetadata(ctx context.Context, st repository.Store, projectID string) (*models.ProjectMetadata, error) {
+	m, err := st.Projects().GetMetadata(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.ProjectMetadata{
+			ProjectID:   projectID,
+			Tags:        []string{},
+			CategoryID:  DefaultCategoryID,
+			License:     LicenseYouTube,
+			PlaylistIDs: []string{},
+		}, nil
+	}
+	return m, err
+}
+
+// normalize trims and de-duplicates the input, fills in defaults and checks
+// it against YouTube's rules
+func (in *MetadataInput) normalize() error {
+	tags, err := normalizeTags(in.Tags)
+	if err != nil {
+		return err
+	}
+	in.Tags = tags
+
+	if in.CategoryID == "" {
+		in.CategoryID = DefaultCategoryID
+	}
+	if !categoryIDPattern.MatchString(in.CategoryID) {
+		return invalidf("category_id must be a YouTube video category ID")
+	}
+	if in.DefaultLanguage != "" && !languagePattern.MatchString(in.DefaultLanguage) {
+		return invalidf("default_language must be a language code such as en or pt-BR")
+	}
+	switch in.License {
+	case "":
+		in.License = LicenseYouTube
+	case LicenseYouTube, LicenseCreativeCommon:
+	default:
+		return invalidf("license must be %s or %s", LicenseYouTube, LicenseCreativeCommon)
+	}
+
+	playlists := []string{}
+	for _, id := range in.PlaylistIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || containsFold(playlists, id) {
+			continue
+		}
+		if !playlistIDPattern.MatchString(id) {
+			return invalidf("%q is not a YouTube playlist ID", id)
+		}
+		playlists = append(playlists, id)
+	}
+	if len(playlists) > maxPlaylists {
+		return invalidf("a video can be added to at most %d playlists", maxPlaylists)
+	}
+	in.PlaylistIDs = playlists
+	return nil
+}
+
+// normalizeTags trims and de-duplicates tags and checks them against
+// YouTube's rules
+func normalizeTags(in []string) ([]string, error) {
+	tags, length := []string{}, 0
+	for _, tag := range in {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || containsFold(tags, tag) {
+			continue
+		}
+		if strings.ContainsAny(tag, "<>") {
+			return nil, invalidf("tags cannot contain < or >")
+		}
+		// YouTube counts the quotes around tags with spaces and the commas between tags
+		length += len([]rune(tag))
+		if strings.Contains(tag, " ") {
+			length += 2
+		}
+		if len(tags) > 0 {
+			length++
+		}
+		tags = append(tags, tag)
+	}
+	if length > maxTagsLength {
+		return nil, invalidf("tags may be at most %d characters together", maxTagsLength)
+	}
+	return tags, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Playlist is one of a channel's playlists a project can be added to
+type Playlist struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Playlists lists the playlists of the project's channel
+func (s *ProjectService) Playlists(ctx context.Context, projectID string) ([]Playlist, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	ytService, ch, err := s.channelYouTube(ctx, p.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists := []Playlist{}
+	err = ytService.Playlists.List([]string{"snippet"}).ChannelId(ch.YtChannelID).MaxResults(50).
+		Pages(ctx, func(page *youtube.PlaylistListResponse) error {
+			for _, item := range page.Items {
+				playlists = append(playlists, Playlist{ID: item.Id, Title: item.Snippet.Title})
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, upstream("Failed to list playlists", err)
+	}
+	return playlists, nil
+}
+
+// applyMetadata fills in the parts of a video about to be uploaded that
+// come from the project's metadata
+func applyMetadata(video *youtube.Video, m *models.ProjectMetadata) {
+	video.Snippet.Tags = m.Tags
+	video.Snippet.CategoryId = m.CategoryID
+	video.Snippet.DefaultLanguage = m.DefaultLanguage
+	video.Status.SelfDeclaredMadeForKids = m.MadeForKids
+	video.Status.License = m.License
+	// YouTube asks whether a video is for kids, so false has to be sent too
+	video.Status.ForceSendFields = append(video.Status.ForceSendFields, "SelfDeclaredMadeForKids")
+}
+
+// queueApplyMetadata queues setting the thumbnail and playlists of a
+// project whose video was just uploaded
+func queueApplyMetadata(ctx context.Context, st repository.Store, projectID string) error {
+	err := enqueueJob(ctx, st, JobApplyMetadata, projectJobKey(JobApplyMetadata, projectID), projectJob{ProjectID: projectID}, time.Now())
+	if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("failed to queue metadata: %w", err)
+	}
+	return nil
+}
+
+// runApplyMetadata sets the uploaded video's thumbnail and adds it to the
+// playlists it is missing from, so it can safely run again
+func (s *ProjectService) runApplyMetadata(ctx context.Context, job *models.Job) error {
+	var payload projectJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("bad payload: %w", err)
+	}
+	p, err := s.store.Projects().Get(ctx, payload.ProjectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m, err := loadMetadata(ctx, s.store, p.ID)
+	if err != nil {
+		return err
+	}
+	if p.YouTubeID == "" || (m.ThumbnailPath == "" && len(m.PlaylistIDs) == 0) {
+		return nil
+	}
+	ytService, _, err := s.channelYouTube(ctx, p.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	if m.ThumbnailPath != "" {
+		info, err := s.videos.Stat(ctx, m.ThumbnailPath)
+		if err != nil {
+			return fmt.Errorf("failed to read thumbnail: %w", err)
+		}
+		r, err := s.videos.Get(ctx, m.ThumbnailPath, 0)
+		if err != nil {
+			return fmt.Errorf("failed to read thumbnail: %w", err)
+		}
+		_, err = ytService.Thumbnails.Set(p.YouTubeID).Media(r, googleapi.ContentType(info.ContentType)).Context(ctx).Do()
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to set thumbnail: %w", err)
+		}
+	}
+
+	for _, playlistID := range m.PlaylistIDs {
+		existing, err := ytService.PlaylistItems.List([]string{"id"}).
+			PlaylistId(playlistID).VideoId(p.YouTubeID).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to check playlist %s: %w", playlistID, err)
+		}
+		if len(existing.Items) > 0 {
+			continue
+		}
+		item := &youtube.PlaylistItem{Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlistID,
+			ResourceId: &youtube.ResourceId{Kind: "youtube#video", VideoId: p.YouTubeID},
+		}}
+		if _, err := ytService.PlaylistItems.Insert([]string{"snippet"}, item).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to add to playlist %s: %w", playlistID, err)
+		}
+	}
+	return nil
+}
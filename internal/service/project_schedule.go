@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"google.golang.org/api/youtube/v3"
+)
+
+// Jobs behind scheduled publishing. Both are keyed by project so a schedule
+// can be moved or cancelled.
+const (
+	// JobUploadScheduled uploads a scheduled project as a private video that
+	// YouTube itself makes public at publish_at
+	JobUploadScheduled = "project.upload_scheduled"
+	// JobMarkPublished moves the project to published once publish_at passes
+	JobMarkPublished = "project.mark_published"
+	// JobSyncSchedule brings an uploaded video's schedule on YouTube in line
+	// with the project's
+	JobSyncSchedule = "project.sync_schedule"
+)
+
+// projectJob is the payload of the scheduling jobs
+type projectJob struct {
+	ProjectID string `json:"project_id"`
+}
+
+func projectJobKey(kind, projectID string) string {
+	return kind + ":" + projectID
+}
+
+// RegisterJobs makes q run the jobs behind scheduling and publishing
+func (s *ProjectService) RegisterJobs(q *JobQueue) {
+	q.Handle(JobUploadScheduled, s.runScheduledUpload)
+	q.Handle(JobMarkPublished, s.runMarkPublished)
+	q.Handle(JobSyncSchedule, s.runSyncSchedule)
+	q.Handle(JobApplyMetadata, s.runApplyMetadata)
+	q.Handle(JobReconcileVideos, s.runReconcileVideos)
+}
+
+// Schedule has an approved project go live at publishAt. The video is
+// uploaded in the background right away and kept private until then.
+// Scheduling a project that is already scheduled moves it to publishAt.
+func (s *ProjectService) Schedule(ctx context.Context, projectID, userID string, publishAt time.Time) (*models.Project, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, invalidf("publish_at must be in the future")
+	}
+	publishAt = publishAt.UTC().Truncate(time.Second)
+
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if p.Status == string(StatusScheduled) {
+		return s.reschedule(ctx, projectID, userID, publishAt)
+	}
+	if p.Status == string(StatusPublished) {
+		return nil, conflict("Project already published")
+	}
+
+	// A video uploaded before its schedule was cancelled only needs its
+	// schedule set on YouTube again
+	reason := "Scheduled for " + publishAt.Format(time.RFC3339)
+	err = s.Transition(ctx, projectID, userID, StatusScheduled, reason, func(st repository.Store, p *models.Project) error {
+		p.PublishAt = &publishAt
+		if err := queueScheduleJobs(ctx, st, p); err != nil {
+			return err
+		}
+		if p.YouTubeID != "" {
+			return queueScheduleSync(ctx, st, p.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.Status = string(StatusScheduled)
+	p.PublishAt = &publishAt
+	return p, nil
+}
+
+// reschedule moves a scheduled project to publishAt. If its video is
+// uploaded already, YouTube is updated in the background.
+func (s *ProjectService) reschedule(ctx context.Context, projectID, userID string, publishAt time.Time) (*models.Project, error) {
+	var p *models.Project
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		p, err = st.Projects().GetForUpdate(ctx, projectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		role, _, err := projectRole(ctx, st, p, userID)
+		if err != nil {
+			return err
+		}
+		if !RoleHas(role, PermProjectPublish) {
+			return ErrTransitionForbidden
+		}
+		if p.Status != string(StatusScheduled) {
+			return conflict("Project is no longer scheduled")
+		}
+
+		p.PublishAt = &publishAt
+		p.UpdatedAt = time.Now()
+		if err := st.Projects().Update(ctx, p); err != nil {
+			return err
+		}
+		if err := queueScheduleJobs(ctx, st, p); err != nil {
+			return err
+		}
+		reason := "Rescheduled for " + publishAt.Format(time.RFC3339)
+		if err := recordEvent(ctx, st, p.ID, userID, StatusScheduled, StatusScheduled, reason); err != nil {
+			return err
+		}
+		if p.YouTubeID != "" {
+			return queueScheduleSync(ctx, st, p.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CancelSchedule takes a scheduled project back to approved. A video that
+// was uploaded already stays on YouTube as a private video; its schedule is
+// cleared there in the background.
+func (s *ProjectService) CancelSchedule(ctx context.Context, projectID, userID string) error {
+	return s.Transition(ctx, projectID, userID, StatusApproved, "Schedule cancelled", func(st repository.Store, p *models.Project) error {
+		for _, kind := range []string{JobUploadScheduled, JobMarkPublished} {
+			key := projectJobKey(kind, p.ID)
+			job, err := st.Jobs().GetActiveByKey(ctx, key)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if job.Status == "running" {
+				return conflict("Project is being uploaded or published; try again shortly")
+			}
+			if err := st.Jobs().CancelByKey(ctx, key); err != nil {
+				return err
+			}
+		}
+		p.PublishAt = nil
+		if p.YouTubeID != "" {
+			return queueScheduleSync(ctx, st, p.ID)
+		}
+		return nil
+	})
+}
+
+// queueScheduleJobs makes sure the scheduled project's upload is queued and
+// that it is marked published at its publish_at
+func queueScheduleJobs(ctx context.Context, st repository.Store, p *models.Project) error {
+	payload := projectJob{ProjectID: p.ID}
+	if p.YouTubeID == "" {
+		// Also requeues an upload that ran out of attempts
+		err := enqueueJob(ctx, st, JobUploadScheduled, projectJobKey(JobUploadScheduled, p.ID), payload, time.Now())
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			return fmt.Errorf("failed to queue upload: %w", err)
+		}
+	}
+
+	key := projectJobKey(JobMarkPublished, p.ID)
+	err := st.Jobs().RescheduleByKey(ctx, key, *p.PublishAt)
+	if errors.Is(err, repository.ErrNotFound) {
+		err = enqueueJob(ctx, st, JobMarkPublished, key, payload, *p.PublishAt)
+	}
+	if errors.Is(err, repository.ErrDuplicate) {
+		return conflict("Project is being published; try again shortly")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to queue publishing: %w", err)
+	}
+	return nil
+}
+
+// queueScheduleSync has runSyncSchedule update the project's video on
+// YouTube. YouTube is never called while the project row is locked, and a
+// change the database did not commit never reaches it.
+func queueScheduleSync(ctx context.Context, st repository.Store, projectID string) error {
+	err := enqueueJob(ctx, st, JobSyncSchedule, projectJobKey(JobSyncSchedule, projectID), projectJob{ProjectID: projectID}, time.Now())
+	if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("failed to queue schedule update: %w", err)
+	}
+	return nil
+}
+
+// cancelScheduleSync drops a pending runSyncSchedule of the project, failing
+// with a conflict while one is running
+func cancelScheduleSync(ctx context.Context, st repository.Store, projectID string) error {
+	key := projectJobKey(JobSyncSchedule, projectID)
+	job, err := st.Jobs().GetActiveByKey(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status == "running" {
+		return conflict("The video's schedule is being updated on YouTube; try again shortly")
+	}
+	return st.Jobs().CancelByKey(ctx, key)
+}
+
+// runSyncSchedule makes YouTube publish the project's video at its
+// publish_at, or never once the schedule was cancelled
+func (s *ProjectService) runSyncSchedule(ctx context.Context, job *models.Job) error {
+	var payload projectJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("bad payload: %w", err)
+	}
+	p, err := s.store.Projects().Get(ctx, payload.ProjectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if p.YouTubeID == "" {
+		return nil
+	}
+	var publishAt *time.Time
+	switch p.Status {
+	case string(StatusScheduled):
+		publishAt = p.PublishAt
+	case string(StatusApproved):
+		// cancelled; the video stays private
+	default:
+		return nil
+	}
+	if err := s.setYouTubeStatus(ctx, p, "private", publishAt); err != nil {
+		return err
+	}
+
+	// A change made while we talked to YouTube could not queue another run,
+	// as this one was still active; fail so the queue runs us again
+	current, err := s.store.Projects().Get(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status != p.Status || !sameTime(current.PublishAt, p.PublishAt) {
+		return errors.New("schedule changed while updating YouTube")
+	}
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// setYouTubeStatus sets the privacy of the project's uploaded video and has
+// YouTube make it public at publishAt, or never when publishAt is nil
+func (s *ProjectService) setYouTubeStatus(ctx context.Context, p *models.Project, privacy string, publishAt *time.Time) error {
+	ytService, _, err := s.channelYouTube(ctx, p.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := loadMetadata(ctx, s.store, p.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	// Updating a part replaces all of it, so the metadata is sent again
+	video := &youtube.Video{Id: p.YouTubeID, Status: &youtube.VideoStatus{
+		PrivacyStatus:           privacy,
+		License:                 metadata.License,
+		SelfDeclaredMadeForKids: metadata.MadeForKids,
+		ForceSendFields:         []string{"SelfDeclaredMadeForKids"},
+	}}
+	if publishAt != nil {
+		video.Status.PublishAt = publishAt.Format(time.RFC3339)
+	} else {
+		video.Status.NullFields = []string{"PublishAt"}
+	}
+	if _, err := ytService.Videos.Update([]string{"status"}, video).Context(ctx).Do(); err != nil {
+		return upstream("Failed to update the video's status on YouTube", err)
+	}
+	return nil
+}
+
+// runScheduledUpload uploads a scheduled project. If publish_at passed while
+// the job waited, the video is published right away instead.
+func (s *ProjectService) runScheduledUpload(ctx context.Context, job *models.Job) error {
+	var payload projectJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("bad payload: %w", err)
+	}
+	p, err := s.store.Projects().Get(ctx, payload.ProjectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Cancelled or published meanwhile, or uploaded by an earlier attempt
+	if p.Status != string(StatusScheduled) || p.YouTubeID != "" || p.PublishAt == nil {
+		return nil
+	}
+
+	publishAt := *p.PublishAt
+	if !publishAt.After(time.Now().Add(time.Minute)) {
+		uploaded, err := s.uploadProject(ctx, p, &youtube.VideoStatus{PrivacyStatus: "public"})
+		if err != nil {
+			return err
+		}
+		return s.Transition(ctx, p.ID, "", StatusPublished, "Published to YouTube as scheduled", func(st repository.Store, p *models.Project) error {
+			now := time.Now()
+			p.YouTubeID = uploaded.Id
+			p.UploadSessionURL = ""
+			p.PublishedAt = &now
+			return queueApplyMetadata(ctx, st, p.ID)
+		})
+	}
+
+	status := &youtube.VideoStatus{PrivacyStatus: "private", PublishAt: publishAt.Format(time.RFC3339)}
+	uploaded, err := s.uploadProject(ctx, p, status)
+	if err != nil {
+		return err
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		current, err := st.Projects().GetForUpdate(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		current.YouTubeID = uploaded.Id
+		current.UploadSessionURL = ""
+		current.UpdatedAt = time.Now()
+		if err := st.Projects().Update(ctx, current); err != nil {
+			return err
+		}
+		if err := queueApplyMetadata(ctx, st, current.ID); err != nil {
+			return err
+		}
+		// Rescheduled or cancelled while the upload was running
+		if current.Status != string(StatusScheduled) || current.PublishAt == nil || !current.PublishAt.Equal(publishAt) {
+			return queueScheduleSync(ctx, st, current.ID)
+		}
+		return nil
+	})
+}
+
+// runMarkPublished records that YouTube made a scheduled project public
+func (s *ProjectService) runMarkPublished(ctx context.Context, job *models.Job) error {
+	var payload projectJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("bad payload: %w", err)
+	}
+	err := s.Transition(ctx, payload.ProjectID, "", StatusPublished, "Published to YouTube as scheduled", func(st repository.Store, p *models.Project) error {
+		if p.YouTubeID == "" {
+			return errors.New("video is not uploaded yet")
+		}
+		p.PublishedAt = p.PublishAt
+		return nil
+	})
+	// Cancelled, or published early because the upload ran late
+	if errors.Is(err, ErrProjectNotFound) || errors.Is(err, ErrInvalidTransition) {
+		return nil
+	}
+	return err
+}
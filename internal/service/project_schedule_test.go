@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+// uploadedProject is an approved project whose video a cancelled schedule
+// left on YouTube
+func uploadedProject(t *testing.T, st repository.Store) *models.Project {
+	t.Helper()
+	id := uuid.New().String()
+	p := &models.Project{
+		ID:        id,
+		Status:    string(StatusApproved),
+		OwnerID:   uuid.New().String(),
+		YouTubeID: "video-" + id,
+	}
+	if err := st.Projects().Create(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestScheduleUploadedVideo(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewProjectService(st, nil, nil, "")
+	p := uploadedProject(t, st)
+
+	publishAt := time.Now().Add(time.Hour)
+	if _, err := s.Schedule(ctx, p.ID, p.OwnerID, publishAt); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := st.Projects().Get(ctx, p.ID)
+	if got.Status != string(StatusScheduled) || got.YouTubeID != p.YouTubeID {
+		t.Errorf("project is %s with video %q, want scheduled with %s", got.Status, got.YouTubeID, p.YouTubeID)
+	}
+	for kind, want := range map[string]bool{
+		JobUploadScheduled: false, // the video is on YouTube already
+		JobMarkPublished:   true,
+		JobSyncSchedule:    true,
+	} {
+		_, err := st.Jobs().GetActiveByKey(ctx, projectJobKey(kind, p.ID))
+		if queued := err == nil; queued != want {
+			t.Errorf("%s queued = %v, want %v (err %v)", kind, queued, want, err)
+		}
+	}
+}
+
+func TestClaimPublishUploadedVideo(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewProjectService(st, nil, nil, "")
+
+	// A schedule update still to run would make the video private again
+	p := uploadedProject(t, st)
+	if err := queueScheduleSync(ctx, st, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.claimPublish(ctx, p.ID); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := st.Jobs().GetActiveByKey(ctx, projectJobKey(JobSyncSchedule, p.ID)); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("schedule sync still queued: %v", err)
+	}
+
+	// One that is running already must finish first
+	p = uploadedProject(t, st)
+	if err := queueScheduleSync(ctx, st, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Jobs().Claim(ctx, "worker", time.Now().Add(-jobLease)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.claimPublish(ctx, p.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("claim while syncing: err = %v, want conflict", err)
+	}
+}
@@ -0,0 +1,779 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+	"google.golang.org/api/youtube/v3"
+)
+
+// Relations a user can have to a project
+const (
+	AccessOwner         = "owner"          // projects.owner_id
+	AccessEditor        = "editor"         // projects.editor_id
+	AccessChannelEditor = "channel_editor" // assigned to the project's channel in editors_channels
+	AccessWorkspace     = "workspace"      // admin or viewer of the channel's workspace; read-only
+)
+
+// Where a project came from (projects.source)
+const (
+	ProjectSourceApp     = "app"     // created and reviewed here
+	ProjectSourceYouTube = "youtube" // imported from the channel's uploads; read-only
+)
+
+var (
+	// ErrProjectNotFound is returned when the project does not exist or the caller has no part in it
+	ErrProjectNotFound = &Error{Kind: ErrNotFound, Message: "Project not found"}
+	// ErrProjectReadOnly is returned when changing a project imported from YouTube
+	ErrProjectReadOnly = &Error{Kind: ErrForbidden, Message: "Imported projects are read-only"}
+)
+
+// ProjectAccess describes how a user is related to a project
+type ProjectAccess struct {
+	ProjectID string
+	OwnerID   string
+	EditorID  string
+	ChannelID string
+	Relation  string
+	Role      string // the user's role on the project, see projectRole
+	ReadOnly  bool   // imported from YouTube
+}
+
+// Can reports whether the user's role on the project grants p
+func (a *ProjectAccess) Can(p Permission) bool {
+	return RoleHas(a.Role, p)
+}
+
+// ProjectVersion is a numbered video upload of a project
+type ProjectVersion struct {
+	models.ProjectVersion
+	URL string `json:"url,omitempty"` // signed download link for reviewers
+}
+
+// ProjectDetails is a project with its versions and what the caller may do next
+type ProjectDetails struct {
+	models.Project
+	Versions           []ProjectVersion
+	Metadata           *ProjectMetadata
+	AllowedTransitions []ProjectStatus
+}
+
+// CreateProjectInput is what an editor provides to start a project
+type CreateProjectInput struct {
+	Title       string
+	Description string
+	ChannelID   string
+	VideoPath   string // object key returned by StoreUpload
+}
+
+// ProjectService holds the review workflow: creating projects, uploading
+// versions, approving, rejecting and publishing them.
+type ProjectService struct {
+	store         repository.Store
+	videos        Storage
+	tokens        *TokenManager
+	uploadBaseURL string
+}
+
+// NewProjectService creates a ProjectService. uploadBaseURL overrides
+// YouTube's resumable upload endpoint when set.
+func NewProjectService(store repository.Store, videos Storage, tokens *TokenManager, uploadBaseURL string) *ProjectService {
+	return &ProjectService{store: store, videos: videos, tokens: tokens, uploadBaseURL: uploadBaseURL}
+}
+
+// Access works out the user's relation and role to a project. Projects the
+// user has no role on are reported as ErrProjectNotFound so their existence
+// is not leaked.
+func (s *ProjectService) Access(ctx context.Context, projectID, userID string) (*ProjectAccess, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	role, assigned, err := projectRole(ctx, s.store, p, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrProjectNotFound
+	}
+
+	a := &ProjectAccess{
+		ProjectID: p.ID,
+		OwnerID:   p.OwnerID,
+		EditorID:  p.EditorID,
+		ChannelID: p.ChannelID,
+		Role:      role,
+		ReadOnly:  p.Source == ProjectSourceYouTube,
+	}
+	switch {
+	case userID == p.OwnerID:
+		a.Relation = AccessOwner
+	case userID == p.EditorID:
+		a.Relation = AccessEditor
+	case assigned:
+		a.Relation = AccessChannelEditor
+	default:
+		a.Relation = AccessWorkspace
+	}
+	return a, nil
+}
+
+// projectRole works out the user's role on a project from their role on its
+// channel, and whether they are assigned to the channel. Editors work on their
+// own projects and only see the channel's others; the project's editor keeps
+// working on it after being taken off the channel.
+func projectRole(ctx context.Context, st repository.Store, p *models.Project, userID string) (string, bool, error) {
+	if userID == p.OwnerID {
+		return RoleOwner, false, nil
+	}
+	ch, err := st.Channels().Get(ctx, p.ChannelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	role, err := channelRole(ctx, st, ch, userID)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = st.Channels().EditorRole(ctx, ch.ID, userID)
+	assigned := err == nil
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", false, err
+	}
+
+	switch {
+	case userID == p.EditorID && (role == "" || role == RoleEditor):
+		return RoleEditor, assigned, nil
+	case role == RoleEditor:
+		return RoleViewer, assigned, nil
+	}
+	return role, assigned, nil
+}
+
+// Create starts a project on a channel the editor is assigned to. The project
+// belongs to the channel's owner and its first version is the uploaded video.
+func (s *ProjectService) Create(ctx context.Context, editorID string, in CreateProjectInput) (*models.Project, error) {
+	ch, err := channelWithPermission(ctx, s.store, in.ChannelID, editorID, PermProjectCreate)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+		return nil, forbidden("You are not assigned to this channel as an editor; ask its owner for an invite")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch channel: %w", err)
+	}
+
+	// The video must be one this editor uploaded through StoreUpload
+	if !strings.HasPrefix(in.VideoPath, editorID+"_") {
+		return nil, invalidf("video_path must be a video you uploaded")
+	}
+	size, checksum, err := s.checksumStoredVideo(ctx, in.VideoPath)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, invalidf("Uploaded video not found")
+		}
+		return nil, fmt.Errorf("failed to read uploaded video: %w", err)
+	}
+
+	now := time.Now()
+	p := &models.Project{
+		ID:          uuid.New().String(),
+		Title:       in.Title,
+		Description: in.Description,
+		VideoPath:   in.VideoPath,
+		Status:      string(StatusSubmitted),
+		EditorID:    editorID,
+		OwnerID:     ch.OwnerID,
+		ChannelID:   ch.ID,
+		Source:      ProjectSourceApp,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := st.Projects().Create(ctx, p); err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		// The initial upload becomes version 1
+		if _, err := addVersion(ctx, st, p, editorID, in.VideoPath, size, checksum); err != nil {
+			return fmt.Errorf("failed to add version: %w", err)
+		}
+		return recordEvent(ctx, st, p.ID, editorID, "", StatusSubmitted, "Project created")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// List returns the projects the user works on in the workspace, newest
+// first. Workspace admins and viewers get every project of the workspace.
+func (s *ProjectService) List(ctx context.Context, userID string, workspace *models.WorkspaceMember) ([]models.Project, error) {
+	f, err := workspaceProjects(userID, workspace)
+	if err != nil || f == nil {
+		return []models.Project{}, err
+	}
+	return s.store.Projects().List(ctx, *f)
+}
+
+// recentLimit keeps the recent projects list small
+const recentLimit = 20
+
+// Recent returns the user's most recently updated projects in the workspace,
+// optionally only those of one channel or of one owner.
+func (s *ProjectService) Recent(ctx context.Context, userID string, workspace *models.WorkspaceMember, ownerID, channelID string) ([]repository.ProjectSummary, error) {
+	f, err := workspaceProjects(userID, workspace)
+	if err != nil || f == nil {
+		return []repository.ProjectSummary{}, err
+	}
+	f.ChannelID, f.Limit = channelID, recentLimit
+	if channelID == "" {
+		f.OwnerID = ownerID
+	}
+	return s.store.Projects().ListRecent(ctx, *f)
+}
+
+// workspaceProjects filters the workspace's projects down to those the user
+// may list; nil means none. Outside of whole-workspace access that follows
+// their role on each project's channel, not their account role.
+func workspaceProjects(userID string, workspace *models.WorkspaceMember) (*repository.ProjectFilter, error) {
+	if workspace == nil {
+		return nil, nil
+	}
+	f := &repository.ProjectFilter{WorkspaceID: workspace.WorkspaceID}
+	if !seesWholeWorkspace(workspace) {
+		f.MemberID = userID
+	}
+	return f, nil
+}
+
+// Details returns the project with its versions and the status changes the
+// caller may make. Only the project's own owner and editor can move it
+// between states.
+func (s *ProjectService) Details(ctx context.Context, access *ProjectAccess) (*ProjectDetails, error) {
+	p, err := s.store.Projects().Get(ctx, access.ProjectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	versions, err := s.Versions(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch versions: %w", err)
+	}
+	metadata, err := s.Metadata(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ProjectDetails{Project: *p, Versions: versions, Metadata: metadata}
+	if p.Source != ProjectSourceYouTube {
+		details.AllowedTransitions = AllowedTransitions(ProjectStatus(p.Status), access.Role)
+	}
+	return details, nil
+}
+
+// Versions lists every version of a project, oldest first, each with a
+// signed URL the reviewer can play.
+func (s *ProjectService) Versions(ctx context.Context, projectID string) ([]ProjectVersion, error) {
+	stored, err := s.store.Projects().ListVersions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ProjectVersion, len(stored))
+	for i, v := range stored {
+		versions[i] = ProjectVersion{ProjectVersion: v}
+		// A missing object should not hide the rest of the history
+		if url, err := s.videos.SignedURL(ctx, v.VideoPath, time.Hour); err == nil {
+			versions[i].URL = url
+		}
+	}
+	return versions, nil
+}
+
+// Approve approves the given version, or the latest one if versionID is
+// empty, and returns the approved version's ID.
+func (s *ProjectService) Approve(ctx context.Context, projectID, userID, versionID, reason string) (string, error) {
+	v, err := s.store.Projects().GetVersion(ctx, projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", notFound("Version not found")
+		}
+		return "", fmt.Errorf("failed to fetch version: %w", err)
+	}
+
+	err = s.Transition(ctx, projectID, userID, StatusApproved, reason, func(st repository.Store, p *models.Project) error {
+		p.ApprovedVersionID = v.ID
+		p.VideoPath = v.VideoPath
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.ID, nil
+}
+
+// Reject sends the project back to the editor with changes requested
+func (s *ProjectService) Reject(ctx context.Context, projectID, userID, reason string) error {
+	return s.Transition(ctx, projectID, userID, StatusChangesRequested, reason, func(st repository.Store, p *models.Project) error {
+		p.ApprovedVersionID = ""
+		return nil
+	})
+}
+
+// ChangeStatus moves a project to any state the caller is allowed to move it
+// to, e.g. submitting a draft or archiving a project.
+func (s *ProjectService) ChangeStatus(ctx context.Context, projectID, userID, status, reason string) (ProjectStatus, error) {
+	to, err := ParseProjectStatus(status)
+	if err != nil {
+		return "", err
+	}
+	// Approval and publishing carry extra data, so they have their own endpoints
+	if to == StatusApproved || to == StatusPublished || to == StatusScheduled {
+		return "", invalidf("Use the dedicated endpoint to move a project to %s", status)
+	}
+	// Resubmitting takes a new version, see UploadVersion
+	err = s.Transition(ctx, projectID, userID, to, reason, func(_ repository.Store, p *models.Project) error {
+		if p.Status == string(to) {
+			return conflict("Project is already " + p.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return to, nil
+}
+
+// History returns the status change log of a project, oldest first
+func (s *ProjectService) History(ctx context.Context, projectID string) ([]models.ProjectEvent, error) {
+	return s.store.Projects().ListEvents(ctx, projectID)
+}
+
+// Transition moves a project to a new status if the transition table allows
+// it for the actor's role on the project, lets apply change the project
+// in the same transaction and records the change in its history. An empty
+// actorID means the system itself is acting.
+func (s *ProjectService) Transition(ctx context.Context, projectID, actorID string, to ProjectStatus, reason string, apply func(st repository.Store, p *models.Project) error) error {
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		p, err := st.Projects().GetForUpdate(ctx, projectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		if p.Source == ProjectSourceYouTube {
+			return ErrProjectReadOnly
+		}
+
+		role := RoleSystem
+		if actorID != "" {
+			if role, _, err = projectRole(ctx, st, p, actorID); err != nil {
+				return err
+			}
+			if role == "" {
+				return ErrProjectNotFound
+			}
+		}
+
+		from, err := ParseProjectStatus(p.Status)
+		if err != nil {
+			return err
+		}
+		if err := CheckTransition(from, to, role); err != nil {
+			return err
+		}
+		// Only the upload itself may move a project that is being published
+		if publishing(p) && to != StatusPublished {
+			return conflict("Project is being published; try again shortly")
+		}
+
+		if apply != nil {
+			if err := apply(st, p); err != nil {
+				return err
+			}
+		}
+
+		p.Status = string(to)
+		p.UpdatedAt = time.Now()
+		if err := st.Projects().Update(ctx, p); err != nil {
+			return err
+		}
+		return recordEvent(ctx, st, p.ID, actorID, from, to, reason)
+	})
+}
+
+// StoreUpload stores a video uploaded by the user ahead of creating a
+// project, returning its object key and a signed URL to play it.
+func (s *ProjectService) StoreUpload(ctx context.Context, userID string, r io.Reader, filename string, size int64, contentType string) (string, string, error) {
+	key := fmt.Sprintf("%s_%d%s", userID, time.Now().UnixNano(), filepath.Ext(filename))
+	if err := s.videos.Put(ctx, key, r, size, contentType); err != nil {
+		return "", "", fmt.Errorf("failed to store video: %w", err)
+	}
+	url, err := s.videos.SignedURL(ctx, key, 24*time.Hour)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate signed URL: %w", err)
+	}
+	return key, url, nil
+}
+
+// UploadVersion stores a new revision of the video uploaded by the project's
+// editor. The project is resubmitted so the owner can review the new version.
+func (s *ProjectService) UploadVersion(ctx context.Context, projectID, userID string, r io.Reader, filename string, size int64, contentType string) (*models.ProjectVersion, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	switch ProjectStatus(p.Status) {
+	case StatusDraft, StatusSubmitted, StatusChangesRequested:
+	default:
+		return nil, conflict("Cannot upload a new version while the project is " + p.Status)
+	}
+
+	// Store under a unique key first; the version number is assigned afterwards
+	// so the project row is not locked for the whole upload.
+	key := fmt.Sprintf("projects/%s/%s%s", p.ID, uuid.New().String(), filepath.Ext(filename))
+	size, checksum, err := s.storeVideo(ctx, key, r, size, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store video: %w", err)
+	}
+
+	// The status is checked again under the lock: the project may have been
+	// approved while the video was uploading. A new version resubmits it.
+	var version *models.ProjectVersion
+	err = s.Transition(ctx, projectID, userID, StatusSubmitted, "New version uploaded", func(st repository.Store, p *models.Project) error {
+		var err error
+		version, err = addVersion(ctx, st, p, userID, key, size, checksum)
+		if err != nil {
+			return err
+		}
+		p.VideoPath = key
+		p.ApprovedVersionID = ""
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			err = conflict("Cannot upload a new version while the project is no longer open for changes")
+		}
+		if delErr := s.videos.Delete(context.WithoutCancel(ctx), key); delErr != nil {
+			log.Printf("failed to delete unused version %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// PublishInput says how a project should appear on YouTube
+type PublishInput struct {
+	Privacy string // "private", "unlisted", "public"; defaults to private
+}
+
+// publishLease is how long a Publish call holds its claim on the project.
+// It only matters when a server dies mid-upload; the claim is released as
+// soon as the upload ends either way.
+const publishLease = 2 * time.Hour
+
+// Publish uploads an approved project's video to its channel on YouTube. The
+// upload session is stored on the project, so publishing again after a
+// failure resumes from the last byte YouTube received. A video uploaded by a
+// schedule that was then cancelled only gets its privacy changed. A project
+// that is already published is returned along with a conflict error.
+func (s *ProjectService) Publish(ctx context.Context, projectID, userID string, in PublishInput) (*models.Project, error) {
+	if in.Privacy == "" {
+		in.Privacy = "private"
+	}
+	if in.Privacy != "private" && in.Privacy != "unlisted" && in.Privacy != "public" {
+		return nil, invalidf("privacy must be private, unlisted or public")
+	}
+
+	p, err := s.claimPublish(ctx, projectID)
+	if err != nil {
+		return p, err
+	}
+
+	videoID := p.YouTubeID
+	if videoID != "" {
+		err = s.setYouTubeStatus(ctx, p, in.Privacy, nil)
+	} else {
+		var uploaded *youtube.Video
+		if uploaded, err = s.uploadProject(ctx, p, &youtube.VideoStatus{PrivacyStatus: in.Privacy}); err == nil {
+			videoID = uploaded.Id
+		}
+	}
+	if err != nil {
+		s.releasePublish(ctx, p.ID)
+		return nil, err
+	}
+
+	err = s.Transition(ctx, p.ID, userID, StatusPublished, "Published to YouTube", func(st repository.Store, p *models.Project) error {
+		now := time.Now()
+		p.YouTubeID = videoID
+		p.UploadSessionURL = ""
+		p.PublishingUntil = nil
+		p.PublishedAt = &now
+		return queueApplyMetadata(ctx, st, p.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.YouTubeID = videoID
+	p.Status = string(StatusPublished)
+	p.PublishingUntil = nil
+	return p, nil
+}
+
+// claimPublish checks that the project can be published and marks it as
+// being published, so a concurrent call cannot upload it a second time
+func (s *ProjectService) claimPublish(ctx context.Context, projectID string) (*models.Project, error) {
+	var p *models.Project
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		p, err = st.Projects().GetForUpdate(ctx, projectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProjectNotFound
+			}
+			return fmt.Errorf("failed to fetch project: %w", err)
+		}
+		if p.Status == string(StatusPublished) {
+			return conflict("Project already published")
+		}
+		if p.Status == string(StatusScheduled) {
+			return conflict("Project is scheduled; cancel the schedule to publish it now")
+		}
+		if p.Status != string(StatusApproved) {
+			return conflict("Only approved projects can be published")
+		}
+		if publishing(p) {
+			return conflict("Project is being published; try again shortly")
+		}
+		// Publishing an uploaded video sets its privacy, which a schedule
+		// update still to run would undo
+		if p.YouTubeID != "" {
+			if err := cancelScheduleSync(ctx, st, p.ID); err != nil {
+				return err
+			}
+		}
+
+		until := time.Now().Add(publishLease)
+		p.PublishingUntil = &until
+		return st.Projects().Update(ctx, p)
+	})
+	if err != nil {
+		// The API reports the video of an already published project
+		if p != nil && p.Status == string(StatusPublished) && errors.Is(err, ErrConflict) {
+			return p, err
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// releasePublish drops the claim of a Publish call whose upload failed, so
+// publishing can be retried right away
+func (s *ProjectService) releasePublish(ctx context.Context, projectID string) {
+	// Also when the caller went away, which is a common reason to fail
+	ctx = context.WithoutCancel(ctx)
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		p, err := st.Projects().GetForUpdate(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		p.PublishingUntil = nil
+		return st.Projects().Update(ctx, p)
+	})
+	if err != nil {
+		log.Printf("project %s: failed to release publish claim: %v", projectID, err)
+	}
+}
+
+// publishing reports whether a Publish call is uploading the project
+func publishing(p *models.Project) bool {
+	return p.PublishingUntil != nil && p.PublishingUntil.After(time.Now())
+}
+
+// channelYouTube returns a YouTube client authorised as the account the
+// channel is connected through
+func (s *ProjectService) channelYouTube(ctx context.Context, channelID string) (*youtube.Service, *models.Channel, error) {
+	ch, err := s.connectedChannel(ctx, channelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ytService, err := s.tokens.YouTube(ctx, ch.YouTubeAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ytService, ch, nil
+}
+
+func (s *ProjectService) connectedChannel(ctx context.Context, channelID string) (*models.Channel, error) {
+	ch, err := s.store.Channels().Get(ctx, channelID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to fetch channel: %w", err)
+	}
+	if ch == nil || ch.YouTubeAccountID == "" {
+		return nil, conflict("Project channel is not connected to a YouTube account")
+	}
+	return ch, nil
+}
+
+// uploadProject uploads the project's approved version to its channel with
+// its metadata and the given status, using the channel owner's YouTube
+// credentials.
+func (s *ProjectService) uploadProject(ctx context.Context, p *models.Project, status *youtube.VideoStatus) (*youtube.Video, error) {
+	ch, err := s.connectedChannel(ctx, p.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := loadMetadata(ctx, s.store, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	// Publish the approved version, falling back to the project's video
+	videoPath := p.VideoPath
+	if p.ApprovedVersionID != "" {
+		v, err := s.store.Projects().GetVersion(ctx, p.ID, p.ApprovedVersionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch approved version: %w", err)
+		}
+		videoPath = v.VideoPath
+	}
+	info, err := s.videos.Stat(ctx, videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored video: %w", err)
+	}
+
+	uploader := NewResumableUploader(s.tokens.Client(ctx, ch.YouTubeAccountID), s.uploadBaseURL)
+	video := &youtube.Video{
+		Snippet: &youtube.VideoSnippet{
+			Title:       p.Title,
+			Description: p.Description,
+		},
+		Status: status,
+	}
+	applyMetadata(video, metadata)
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		return s.videos.Get(ctx, videoPath, offset)
+	}
+	uploaded, err := s.uploadVideo(ctx, uploader, p, video, open, info.Size, info.ContentType)
+	if err != nil {
+		return nil, upstream("Failed to upload to YouTube", err)
+	}
+	return uploaded, nil
+}
+
+// uploadVideo resumes the project's stored upload session, or starts a new
+// one when there is none or YouTube has expired it.
+func (s *ProjectService) uploadVideo(ctx context.Context, uploader *ResumableUploader, p *models.Project, video *youtube.Video, open RangeOpener, size int64, contentType string) (*youtube.Video, error) {
+	if p.UploadSessionURL != "" {
+		uploaded, err := uploader.Upload(ctx, p.UploadSessionURL, open, size)
+		if !errors.Is(err, ErrUploadSessionExpired) {
+			return uploaded, err
+		}
+	}
+
+	sessionURL, err := uploader.Start(ctx, video, size, contentType)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		current, err := st.Projects().GetForUpdate(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		current.UploadSessionURL = sessionURL
+		current.UpdatedAt = time.Now()
+		return st.Projects().Update(ctx, current)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save upload session: %w", err)
+	}
+
+	return uploader.Upload(ctx, sessionURL, open, size)
+}
+
+// addVersion records the next version of the project
+func addVersion(ctx context.Context, st repository.Store, p *models.Project, uploaderID, videoPath string, size int64, checksum string) (*models.ProjectVersion, error) {
+	v := &models.ProjectVersion{
+		ID:         uuid.New().String(),
+		ProjectID:  p.ID,
+		VideoPath:  videoPath,
+		UploaderID: uploaderID,
+		SizeBytes:  size,
+		Checksum:   checksum,
+		CreatedAt:  time.Now(),
+	}
+	if err := st.Projects().AddVersion(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// recordEvent appends to the project's status history
+func recordEvent(ctx context.Context, st repository.Store, projectID, actorID string, from, to ProjectStatus, reason string) error {
+	return st.Projects().AddEvent(ctx, &models.ProjectEvent{
+		ID:         uuid.New().String(),
+		ProjectID:  projectID,
+		ActorID:    actorID,
+		FromStatus: string(from),
+		ToStatus:   string(to),
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// storeVideo streams r into storage, returning the byte count and SHA-256
+func (s *ProjectService) storeVideo(ctx context.Context, key string, r io.Reader, size int64, contentType string) (int64, string, error) {
+	h := sha256.New()
+	cr := &countingReader{r: io.TeeReader(r, h)}
+	if err := s.videos.Put(ctx, key, cr, size, contentType); err != nil {
+		return 0, "", err
+	}
+	return cr.n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksumStoredVideo reads back an already stored object to size and hash it
+func (s *ProjectService) checksumStoredVideo(ctx context.Context, key string) (int64, string, error) {
+	r, err := s.videos.Get(ctx, key, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
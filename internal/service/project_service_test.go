@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+func TestClaimPublish(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewProjectService(st, nil, nil, "")
+	p := &models.Project{
+		ID:        uuid.New().String(),
+		Title:     "Launch",
+		Status:    string(StatusApproved),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := st.Projects().Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.claimPublish(ctx, p.ID); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if _, err := s.claimPublish(ctx, p.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second claim: err = %v, want conflict", err)
+	}
+	// Nothing else may move the project while it uploads
+	err := s.Transition(ctx, p.ID, "", StatusArchived, "", nil)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("archive while publishing: err = %v, want conflict", err)
+	}
+
+	s.releasePublish(ctx, p.ID)
+	if _, err := s.claimPublish(ctx, p.ID); err != nil {
+		t.Fatalf("claim after release: %v", err)
+	}
+
+	// The upload finishing may still publish it
+	err = s.Transition(ctx, p.ID, "", StatusPublished, "", func(_ repository.Store, p *models.Project) error {
+		p.YouTubeID = "video-1"
+		p.PublishingUntil = nil
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	got, err := s.claimPublish(ctx, p.ID)
+	if !errors.Is(err, ErrConflict) || got == nil || got.YouTubeID != "video-1" {
+		t.Fatalf("claim published project: got %v, %v; want it with a conflict", got, err)
+	}
+}
+
+func TestClaimPublishExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewProjectService(st, nil, nil, "")
+	expired := time.Now().Add(-time.Minute)
+	p := &models.Project{
+		ID:              uuid.New().String(),
+		Status:          string(StatusApproved),
+		PublishingUntil: &expired, // the server publishing it died
+	}
+	if err := st.Projects().Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.claimPublish(ctx, p.ID); err != nil {
+		t.Fatalf("claim after the lease expired: %v", err)
+	}
+}
+
+// newVersionFixture is a submitted project with its editor assigned to the channel
+func newVersionFixture(t *testing.T) (*ProjectService, *memory.Store, *models.Project) {
+	t.Helper()
+	ctx := context.Background()
+	st := memory.NewStore()
+	videos, err := NewLocalStorage(t.TempDir(), "http://api.test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := &models.Channel{ID: uuid.New().String(), OwnerID: uuid.New().String()}
+	if err := st.Channels().Create(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	p := &models.Project{
+		ID:        uuid.New().String(),
+		Status:    string(StatusSubmitted),
+		EditorID:  uuid.New().String(),
+		OwnerID:   ch.OwnerID,
+		ChannelID: ch.ID,
+		VideoPath: "projects/v1.mp4",
+	}
+	if err := st.Projects().Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Channels().AddEditor(ctx, ch.ID, p.EditorID); err != nil {
+		t.Fatal(err)
+	}
+	return NewProjectService(st, videos, nil, ""), st, p
+}
+
+// hookReader runs hook before the first read, i.e. while the upload is running
+type hookReader struct {
+	io.Reader
+	hook func()
+}
+
+func (r *hookReader) Read(b []byte) (int, error) {
+	if r.hook != nil {
+		r.hook()
+		r.hook = nil
+	}
+	return r.Reader.Read(b)
+}
+
+func TestUploadVersionResubmits(t *testing.T) {
+	ctx := context.Background()
+	s, st, p := newVersionFixture(t)
+
+	v, err := s.UploadVersion(ctx, p.ID, p.EditorID, strings.NewReader("v2"), "v2.mp4", 2, "video/mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := st.Projects().Get(ctx, p.ID)
+	if got.Status != string(StatusSubmitted) || got.VideoPath != v.VideoPath {
+		t.Errorf("project is %s with video %s, want submitted with %s", got.Status, got.VideoPath, v.VideoPath)
+	}
+	events, _ := st.Projects().ListEvents(ctx, p.ID)
+	if len(events) != 1 || events[0].FromStatus != string(StatusSubmitted) || events[0].ToStatus != string(StatusSubmitted) {
+		t.Errorf("events = %+v, want one resubmission", events)
+	}
+}
+
+func TestUploadVersionApprovedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	s, st, p := newVersionFixture(t)
+
+	// The owner approves v1 while v2 is uploading
+	approve := func() {
+		err := s.Transition(ctx, p.ID, p.OwnerID, StatusApproved, "", func(_ repository.Store, p *models.Project) error {
+			p.ApprovedVersionID = "v1"
+			return nil
+		})
+		if err != nil {
+			t.Errorf("approve: %v", err)
+		}
+	}
+	r := &hookReader{Reader: strings.NewReader("v2"), hook: approve}
+	_, err := s.UploadVersion(ctx, p.ID, p.EditorID, r, "v2.mp4", 2, "video/mp4")
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+
+	got, _ := st.Projects().Get(ctx, p.ID)
+	if got.Status != string(StatusApproved) || got.VideoPath != p.VideoPath || got.ApprovedVersionID != "v1" {
+		t.Errorf("project = %s %s approved %q, want approved v1 left alone", got.Status, got.VideoPath, got.ApprovedVersionID)
+	}
+	versions, _ := st.Projects().ListVersions(ctx, p.ID)
+	if len(versions) != 0 {
+		t.Errorf("stored %d versions, want none", len(versions))
+	}
+}
+
+func TestListFollowsChannelRole(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	s := NewProjectService(st, nil, nil, "")
+	workspaceID := uuid.New().String()
+	ch := &models.Channel{ID: uuid.New().String(), OwnerID: uuid.New().String(), WorkspaceID: workspaceID}
+	if err := st.Channels().Create(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	editor, other := uuid.New().String(), uuid.New().String()
+	for _, editorID := range []string{editor, other} {
+		p := &models.Project{ID: uuid.New().String(), OwnerID: ch.OwnerID, EditorID: editorID, ChannelID: ch.ID, CreatedAt: time.Now()}
+		if err := st.Projects().Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reviewers and managers usually have the editor account role
+	tests := []struct {
+		name, userID, channelRole string
+		want                      int
+	}{
+		{name: "owner", userID: ch.OwnerID, want: 2},
+		{name: "manager", userID: uuid.New().String(), channelRole: RoleManager, want: 2},
+		{name: "reviewer", userID: uuid.New().String(), channelRole: RoleReviewer, want: 2},
+		{name: "viewer", userID: uuid.New().String(), channelRole: RoleViewer, want: 2},
+		{name: "editor", userID: editor, channelRole: RoleEditor, want: 1},
+		{name: "unassigned", userID: uuid.New().String(), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.channelRole != "" {
+				if _, err := st.Channels().AddEditor(ctx, ch.ID, tt.userID); err != nil {
+					t.Fatal(err)
+				}
+				if err := st.Channels().SetEditorRole(ctx, ch.ID, tt.userID, tt.channelRole); err != nil {
+					t.Fatal(err)
+				}
+			}
+			member := &models.WorkspaceMember{WorkspaceID: workspaceID, UserID: tt.userID, Role: WorkspaceEditor}
+
+			got, err := s.List(ctx, tt.userID, member)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("listed %d projects, want %d", len(got), tt.want)
+			}
+			recent, err := s.Recent(ctx, tt.userID, member, "", ch.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recent) != tt.want {
+				t.Errorf("recent lists %d projects, want %d", len(recent), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+)
+
+// ProjectStatus is the lifecycle state stored in projects.status
+type ProjectStatus string
+
+const (
+	StatusDraft            ProjectStatus = "draft"
+	StatusSubmitted        ProjectStatus = "submitted"
+	StatusInReview         ProjectStatus = "in_review"
+	StatusChangesRequested ProjectStatus = "changes_requested"
+	StatusApproved         ProjectStatus = "approved"
+	StatusScheduled        ProjectStatus = "scheduled"
+	StatusPublished        ProjectStatus = "published"
+	StatusArchived         ProjectStatus = "archived"
+)
+
+var (
+	// ErrInvalidTransition means no rule moves a project between the two states
+	ErrInvalidTransition = &Error{Kind: ErrConflict, Message: "invalid status transition"}
+	// ErrTransitionForbidden means the rule exists but not for the caller's role
+	ErrTransitionForbidden = &Error{Kind: ErrForbidden, Message: "not allowed to make this status change"}
+)
+
+type transition struct {
+	from, to ProjectStatus
+}
+
+// projectTransitions lists every allowed move and the permission it needs
+var projectTransitions = map[transition]Permission{
+	{StatusDraft, StatusSubmitted}:            PermProjectUpload,
+	{StatusSubmitted, StatusSubmitted}:        PermProjectUpload, // a new version resubmits it
+	{StatusSubmitted, StatusDraft}:            PermProjectUpload,
+	{StatusSubmitted, StatusInReview}:         PermProjectApprove,
+	{StatusSubmitted, StatusApproved}:         PermProjectApprove,
+	{StatusSubmitted, StatusChangesRequested}: PermProjectApprove,
+	{StatusInReview, StatusApproved}:          PermProjectApprove,
+	{StatusInReview, StatusChangesRequested}:  PermProjectApprove,
+	{StatusChangesRequested, StatusSubmitted}: PermProjectUpload,
+	{StatusApproved, StatusChangesRequested}:  PermProjectApprove,
+	{StatusApproved, StatusScheduled}:         PermProjectPublish,
+	{StatusApproved, StatusPublished}:         PermProjectPublish,
+	{StatusScheduled, StatusApproved}:         PermProjectPublish,
+	{StatusScheduled, StatusPublished}:        PermProjectPublish,
+
+	{StatusDraft, StatusArchived}:            PermProjectPublish,
+	{StatusSubmitted, StatusArchived}:        PermProjectPublish,
+	{StatusInReview, StatusArchived}:         PermProjectPublish,
+	{StatusChangesRequested, StatusArchived}: PermProjectPublish,
+	{StatusApproved, StatusArchived}:         PermProjectPublish,
+	{StatusPublished, StatusArchived}:        PermProjectPublish,
+	{StatusArchived, StatusDraft}:            PermProjectPublish,
+}
+
+// ParseProjectStatus validates a status string
+func ParseProjectStatus(s string) (ProjectStatus, error) {
+	switch st := ProjectStatus(s); st {
+	case StatusDraft, StatusSubmitted, StatusInReview, StatusChangesRequested,
+		StatusApproved, StatusScheduled, StatusPublished, StatusArchived:
+		return st, nil
+	}
+	return "", invalidf("unknown project status %q", s)
+}
+
+// CheckTransition reports whether role may move a project from one state to another
+func CheckTransition(from, to ProjectStatus, role string) error {
+	perm, ok := projectTransitions[transition{from, to}]
+	if !ok {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	if !RoleHas(role, perm) {
+		return fmt.Errorf("%w: %s cannot move a project from %s to %s", ErrTransitionForbidden, role, from, to)
+	}
+	return nil
+}
+
+// AllowedTransitions returns the states role can move a project to from its current state
+func AllowedTransitions(from ProjectStatus, role string) []ProjectStatus {
+	var next []ProjectStatus
+	for _, to := range []ProjectStatus{
+		StatusDraft, StatusSubmitted, StatusInReview, StatusChangesRequested,
+		StatusApproved, StatusScheduled, StatusPublished, StatusArchived,
+	} {
+		if to != from && CheckTransition(from, to, role) == nil {
+			next = append(next, to)
+		}
+	}
+	return next
+}
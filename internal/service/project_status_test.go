@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to ProjectStatus
+		role     string
+		want     error
+	}{
+		{StatusDraft, StatusSubmitted, RoleEditor, nil},
+		{StatusDraft, StatusSubmitted, RoleOwner, ErrTransitionForbidden},
+		{StatusSubmitted, StatusSubmitted, RoleEditor, nil},
+		{StatusSubmitted, StatusApproved, RoleReviewer, nil},
+		{StatusSubmitted, StatusApproved, RoleEditor, ErrTransitionForbidden},
+		{StatusInReview, StatusChangesRequested, RoleManager, nil},
+		{StatusChangesRequested, StatusSubmitted, RoleEditor, nil},
+		{StatusApproved, StatusScheduled, RoleManager, nil},
+		{StatusApproved, StatusScheduled, RoleReviewer, ErrTransitionForbidden},
+		{StatusApproved, StatusPublished, RoleSystem, nil},
+		{StatusScheduled, StatusPublished, RoleSystem, nil},
+		{StatusScheduled, StatusArchived, RoleOwner, ErrInvalidTransition},
+		{StatusPublished, StatusArchived, RoleOwner, nil},
+		{StatusPublished, StatusDraft, RoleOwner, ErrInvalidTransition},
+		{StatusArchived, StatusDraft, RoleManager, nil},
+		{StatusArchived, StatusDraft, RoleViewer, ErrTransitionForbidden},
+		{StatusDraft, StatusPublished, RoleOwner, ErrInvalidTransition},
+		{StatusDraft, StatusDraft, RoleOwner, ErrInvalidTransition},
+		{StatusDraft, StatusSubmitted, "", ErrTransitionForbidden},
+	}
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.to, tt.role)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: %s to %s: err = %v, want %v", tt.role, tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		from ProjectStatus
+		role string
+		want []ProjectStatus
+	}{
+		// Resubmitting is not a choice the client offers
+		{StatusSubmitted, RoleEditor, []ProjectStatus{StatusDraft}},
+		{StatusSubmitted, RoleReviewer, []ProjectStatus{StatusInReview, StatusChangesRequested, StatusApproved}},
+		{StatusApproved, RoleManager, []ProjectStatus{StatusChangesRequested, StatusScheduled, StatusPublished, StatusArchived}},
+		{StatusScheduled, RoleManager, []ProjectStatus{StatusApproved, StatusPublished}},
+		{StatusPublished, RoleEditor, nil},
+		{StatusDraft, RoleViewer, nil},
+	}
+	for _, tt := range tests {
+		if got := AllowedTransitions(tt.from, tt.role); !slices.Equal(got, tt.want) {
+			t.Errorf("%s from %s: %v, want %v", tt.role, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseProjectStatus(t *testing.T) {
+	for _, s := range []string{"draft", "in_review", "archived"} {
+		if got, err := ParseProjectStatus(s); err != nil || string(got) != s {
+			t.Errorf("ParseProjectStatus(%q) = %q, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "Draft", "deleted"} {
+		if _, err := ParseProjectStatus(s); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ParseProjectStatus(%q): err = %v, want invalid input", s, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCSStorage stores objects in a Google Cloud Storage bucket. The client is
+// created once and shared by all requests.
+type GCSStorage struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+}
+
+// NewGCSStorage connects to bucket using the service-account key at credentialsFile.
+// An empty credentialsFile falls back to application default credentials.
+func NewGCSStorage(ctx context.Context, bucket, credentialsFile string) (*GCSStorage, error) {
+	if bucket == "" {
+		return nil, errors.New("GCS bucket not configured")
+	}
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &GCSStorage{client: client, bucket: client.Bucket(bucket)}, nil
+}
+
+func (s *GCSStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload to GCS: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close GCS writer: %w", err)
+	}
+	return nil
+}
+
+func (s *GCSStorage) Get(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	r, err := s.bucket.Object(key).NewRangeReader(ctx, offset, -1)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return r, err
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, key string) error {
+	err := s.bucket.Object(key).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func (s *GCSStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	attrs, err := s.bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return &ObjectInfo{
+		Key:         key,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		UpdatedAt:   attrs.Updated,
+	}, nil
+}
+
+// SignedURL signs with the credentials the client was created with
+func (s *GCSStorage) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	url, err := s.bucket.SignedURL(key, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  http.MethodGet,
+		Expires: time.Now().Add(expires),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signed URL: %w", err)
+	}
+	return url, nil
+}
+
+// Close releases the underlying GCS client
+func (s *GCSStorage) Close() error {
+	return s.client.Close()
+}
@@ -1 +1,61 @@
 package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/config"
+)
+
+// ErrObjectNotFound is returned by every backend when a key does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Storage is where uploaded videos live. Keys are slash separated paths.
+type Storage interface {
+	// Put stores size bytes from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get reads the object starting at offset (0 for the whole object)
+	Get(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited URL that downloads the object without auth
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+}
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+	StorageGCS   = "gcs"
+)
+
+// NewStorage builds the backend named by cfg.Backend
+func NewStorage(ctx context.Context, cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case StorageLocal:
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicURL, cfg.SigningSecret)
+	case StorageS3:
+		return NewS3Storage(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	case StorageGCS:
+		return NewGCSStorage(ctx, cfg.GCSBucket, cfg.GCSCredentialsFile)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
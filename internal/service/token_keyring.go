@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+)
+
+// TokenKeyring encrypts the OAuth tokens of youtube_accounts rows. Each row's
+// tokens are sealed with AES-GCM under a random data key of their own, which
+// is stored wrapped by one of the keyring's master keys together with that
+// key's ID. Rotating a master key therefore only rewraps data keys.
+type TokenKeyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewTokenKeyring creates a keyring from base64-encoded 32-byte master keys
+// by ID. New tokens are sealed under the key with ID current; the others are
+// only used to open rows that have not been rotated yet.
+func NewTokenKeyring(keys map[string]string, current string) (*TokenKeyring, error) {
+	k := &TokenKeyring{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, encoded := range keys {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("token key %q: %w", id, err)
+		}
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("token key %q must be 32 bytes, got %d", id, len(raw))
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, fmt.Errorf("token key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[current]; !ok {
+		return nil, fmt.Errorf("token key %q is not configured", current)
+	}
+	return k, nil
+}
+
+// CurrentKeyID is the ID of the master key new tokens are sealed under
+func (k *TokenKeyring) CurrentKeyID() string {
+	return k.current
+}
+
+// Seal encrypts the tokens into a under a fresh data key
+func (k *TokenKeyring) Seal(a *models.YouTubeAccount, accessToken, refreshToken string) error {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+	access, err := seal(aead, []byte(accessToken), "access_token")
+	if err != nil {
+		return err
+	}
+	refresh, err := seal(aead, []byte(refreshToken), "refresh_token")
+	if err != nil {
+		return err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, k.current)
+	if err != nil {
+		return err
+	}
+
+	a.AccessToken = base64.StdEncoding.EncodeToString(access)
+	a.RefreshToken = base64.StdEncoding.EncodeToString(refresh)
+	a.TokenKeyID = k.current
+	a.TokenDataKey = wrapped
+	return nil
+}
+
+// Open decrypts the tokens of a. Rows without a key ID predate encryption
+// and hold plaintext tokens until SealPlaintextTokens runs at startup.
+func (k *TokenKeyring) Open(a *models.YouTubeAccount) (accessToken, refreshToken string, err error) {
+	if a.TokenKeyID == "" {
+		return a.AccessToken, a.RefreshToken, nil
+	}
+	aead, err := k.dataKey(a)
+	if err != nil {
+		return "", "", err
+	}
+	access, err := openBase64(aead, a.AccessToken, "access_token")
+	if err != nil {
+		return "", "", fmt.Errorf("youtube account %s: access token: %w", a.ID, err)
+	}
+	refresh, err := openBase64(aead, a.RefreshToken, "refresh_token")
+	if err != nil {
+		return "", "", fmt.Errorf("youtube account %s: refresh token: %w", a.ID, err)
+	}
+	return access, refresh, nil
+}
+
+// Rewrap moves a under the current master key. It reports whether a changed.
+func (k *TokenKeyring) Rewrap(a *models.YouTubeAccount) (bool, error) {
+	switch a.TokenKeyID {
+	case k.current:
+		return false, nil
+	case "":
+		return true, k.Seal(a, a.AccessToken, a.RefreshToken)
+	}
+
+	dataKey, err := k.unwrap(a)
+	if err != nil {
+		return false, err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, k.current)
+	if err != nil {
+		return false, err
+	}
+	a.TokenKeyID = k.current
+	a.TokenDataKey = wrapped
+	return true, nil
+}
+
+// RotateTokenKeys seals every account's tokens under the keyring's current
+// key and returns how many rows changed. Each row is updated in its own
+// transaction, so an interrupted rotation can simply be run again.
+func RotateTokenKeys(ctx context.Context, store repository.Store, keys *TokenKeyring) (int, error) {
+	return resealAccounts(ctx, store, keys, keys.Rewrap)
+}
+
+// SealPlaintextTokens encrypts the tokens of accounts stored before tokens
+// were encrypted, leaving rows under any key alone. The server runs it at
+// startup and refuses to start if it fails, so plaintext tokens do not
+// outlive the upgrade.
+func SealPlaintextTokens(ctx context.Context, store repository.Store, keys *TokenKeyring) (int, error) {
+	return resealAccounts(ctx, store, keys, func(a *models.YouTubeAccount) (bool, error) {
+		if a.TokenKeyID != "" {
+			return false, nil
+		}
+		return true, keys.Seal(a, a.AccessToken, a.RefreshToken)
+	})
+}
+
+// resealAccounts runs reseal on every account not under the current key and
+// saves those it changed
+func resealAccounts(ctx context.Context, store repository.Store, keys *TokenKeyring, reseal func(*models.YouTubeAccount) (bool, error)) (int, error) {
+	ids, err := store.YouTubeAccounts().ListIDsNotUsingKey(ctx, keys.CurrentKeyID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	changed := 0
+	for _, id := range ids {
+		err := store.WithTx(ctx, func(st repository.Store) error {
+			a, err := st.YouTubeAccounts().GetForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			ok, err := reseal(a)
+			if err != nil || !ok {
+				return err
+			}
+			changed++
+			return st.YouTubeAccounts().UpdateToken(ctx, a)
+		})
+		// Disconnected while we were at it
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return changed, fmt.Errorf("youtube account %s: %w", id, err)
+		}
+	}
+	return changed, nil
+}
+
+func (k *TokenKeyring) unwrap(a *models.YouTubeAccount) ([]byte, error) {
+	master, ok := k.keys[a.TokenKeyID]
+	if !ok {
+		return nil, fmt.Errorf("youtube account %s is sealed under unknown token key %q", a.ID, a.TokenKeyID)
+	}
+	dataKey, err := open(master, a.TokenDataKey, a.TokenKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("youtube account %s: data key: %w", a.ID, err)
+	}
+	return dataKey, nil
+}
+
+func (k *TokenKeyring) dataKey(a *models.YouTubeAccount) (cipher.AEAD, error) {
+	dataKey, err := k.unwrap(a)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(dataKey)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the nonce followed by the ciphertext. label is bound as
+// additional data so a value cannot be moved to another column or key.
+func seal(aead cipher.AEAD, plaintext []byte, label string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(label)), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, label string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(label))
+}
+
+func openBase64(aead cipher.AEAD, encoded, label string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, label)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
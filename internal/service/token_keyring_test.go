@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+// keyringWith has master keys k1 and k2, each 32 copies of its last digit
+func keyringWith(t *testing.T, current string) *TokenKeyring {
+	t.Helper()
+	keys := map[string]string{}
+	for _, id := range []string{"k1", "k2"} {
+		keys[id] = base64.StdEncoding.EncodeToString([]byte(strings.Repeat(id[1:], 32)))
+	}
+	k, err := NewTokenKeyring(keys, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// flipByte changes one byte of a base64 string's decoded contents
+func flipByte(encoded string) string {
+	raw, _ := base64.StdEncoding.DecodeString(encoded)
+	raw[len(raw)-1] ^= 1
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestTokenKeyringOpen(t *testing.T) {
+	keys := keyringWith(t, "k1")
+	tests := []struct {
+		name    string
+		tamper  func(a *models.YouTubeAccount)
+		wantErr string // empty if the tokens open
+	}{
+		{name: "round trip", tamper: func(a *models.YouTubeAccount) {}},
+		{name: "plaintext row", tamper: func(a *models.YouTubeAccount) {
+			a.TokenKeyID, a.TokenDataKey, a.AccessToken, a.RefreshToken = "", nil, "access", "refresh"
+		}},
+		{name: "unknown key", tamper: func(a *models.YouTubeAccount) { a.TokenKeyID = "k9" }, wantErr: `unknown token key "k9"`},
+		{name: "other key", tamper: func(a *models.YouTubeAccount) { a.TokenKeyID = "k2" }, wantErr: "data key"},
+		{name: "tampered data key", tamper: func(a *models.YouTubeAccount) { a.TokenDataKey[len(a.TokenDataKey)-1] ^= 1 }, wantErr: "data key"},
+		{name: "tampered access token", tamper: func(a *models.YouTubeAccount) { a.AccessToken = flipByte(a.AccessToken) }, wantErr: "access token"},
+		{name: "tampered refresh token", tamper: func(a *models.YouTubeAccount) { a.RefreshToken = flipByte(a.RefreshToken) }, wantErr: "refresh token"},
+		{name: "swapped columns", tamper: func(a *models.YouTubeAccount) {
+			a.AccessToken, a.RefreshToken = a.RefreshToken, a.AccessToken
+		}, wantErr: "access token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &models.YouTubeAccount{ID: "account-1"}
+			if err := keys.Seal(a, "access", "refresh"); err != nil {
+				t.Fatal(err)
+			}
+			if a.AccessToken == "access" || a.RefreshToken == "refresh" || a.TokenKeyID != "k1" {
+				t.Fatalf("sealed account = %+v", a)
+			}
+			tt.tamper(a)
+
+			access, refresh, err := keys.Open(a)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one about %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if access != "access" || refresh != "refresh" {
+				t.Errorf("opened %q, %q", access, refresh)
+			}
+		})
+	}
+}
+
+func TestTokenKeyringRewrap(t *testing.T) {
+	old, current := keyringWith(t, "k1"), keyringWith(t, "k2")
+	tests := []struct {
+		name    string
+		seal    func(a *models.YouTubeAccount) error
+		changed bool
+	}{
+		{name: "old key", seal: func(a *models.YouTubeAccount) error { return old.Seal(a, "access", "refresh") }, changed: true},
+		{name: "current key", seal: func(a *models.YouTubeAccount) error { return current.Seal(a, "access", "refresh") }},
+		{name: "plaintext", seal: func(a *models.YouTubeAccount) error {
+			a.AccessToken, a.RefreshToken = "access", "refresh"
+			return nil
+		}, changed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &models.YouTubeAccount{ID: "account-1"}
+			if err := tt.seal(a); err != nil {
+				t.Fatal(err)
+			}
+			changed, err := current.Rewrap(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if a.TokenKeyID != "k2" {
+				t.Errorf("key = %q, want k2", a.TokenKeyID)
+			}
+			if access, refresh, err := current.Open(a); err != nil || access != "access" || refresh != "refresh" {
+				t.Errorf("Open = %q, %q, %v", access, refresh, err)
+			}
+			if changed, err := current.Rewrap(a); changed || err != nil {
+				t.Errorf("second Rewrap = %v, %v, want nothing to do", changed, err)
+			}
+		})
+	}
+}
+
+// storeAccounts saves an account sealed under each keyring, in plaintext for nil,
+// and returns their IDs
+func storeAccounts(t *testing.T, st *memory.Store, seals ...*TokenKeyring) []string {
+	t.Helper()
+	ids := make([]string, len(seals))
+	for i, keys := range seals {
+		a := &models.YouTubeAccount{UserID: uuid.New().String(), Email: "owner@example.com", AccessToken: "access", RefreshToken: "refresh"}
+		if keys != nil {
+			if err := keys.Seal(a, "access", "refresh"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := st.YouTubeAccounts().Upsert(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func TestResealAccounts(t *testing.T) {
+	old, current := keyringWith(t, "k1"), keyringWith(t, "k2")
+	tests := []struct {
+		name    string
+		reseal  func(context.Context, *memory.Store) (int, error)
+		changed int
+		keys    []string // key of the old, plaintext and current account afterwards
+	}{
+		{
+			name:    "rotate",
+			reseal:  func(ctx context.Context, st *memory.Store) (int, error) { return RotateTokenKeys(ctx, st, current) },
+			changed: 2,
+			keys:    []string{"k2", "k2", "k2"},
+		},
+		{
+			name:    "seal plaintext",
+			reseal:  func(ctx context.Context, st *memory.Store) (int, error) { return SealPlaintextTokens(ctx, st, current) },
+			changed: 1,
+			keys:    []string{"k1", "k2", "k2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			st := memory.NewStore()
+			ids := storeAccounts(t, st, old, nil, current)
+
+			changed, err := tt.reseal(ctx, st)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed %d accounts, want %d", changed, tt.changed)
+			}
+			for i, id := range ids {
+				a, err := st.YouTubeAccounts().Get(ctx, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if a.TokenKeyID != tt.keys[i] {
+					t.Errorf("account %d is under %q, want %q", i, a.TokenKeyID, tt.keys[i])
+				}
+				if access, refresh, err := current.Open(a); err != nil || access != "access" || refresh != "refresh" {
+					t.Errorf("account %d: Open = %q, %q, %v", i, access, refresh, err)
+				}
+			}
+
+			// Nothing is left to do the second time
+			if changed, err := tt.reseal(ctx, st); changed != 0 || err != nil {
+				t.Errorf("second run changed %d accounts, err %v", changed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// expiryLeeway refreshes tokens slightly before Google would reject them
+const expiryLeeway = time.Minute
+
+// refreshTimeout bounds a refresh, and so how long the account's row stays
+// locked while Google's token endpoint answers
+const refreshTimeout = 15 * time.Second
+
+// googleRevokeURL revokes a token, and with it the whole grant
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// revokeClient talks to googleRevokeURL; a hung call must not hold up a
+// disconnect
+var revokeClient = &http.Client{Timeout: 10 * time.Second}
+
+// TokenManager hands out token sources for youtube_accounts rows. Tokens are
+// sealed with its keyring whenever they are stored. Refreshed tokens are
+// written back to the database, and refreshes of the same account are
+// serialised both in-process and across servers (via a row lock).
+type TokenManager struct {
+	store  repository.Store
+	config *oauth2.Config
+	keys   *TokenKeyring
+
+	mu    sync.Mutex
+	locks map[string]*accountLock // only accounts being refreshed right now
+}
+
+// accountLock serialises refreshes of one account in this process
+type accountLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// NewTokenManager creates a TokenManager using cfg to talk to Google's token
+// endpoint and keys to encrypt the tokens it stores
+func NewTokenManager(store repository.Store, cfg *oauth2.Config, keys *TokenKeyring) *TokenManager {
+	return &TokenManager{
+		store:  store,
+		config: cfg,
+		keys:   keys,
+		locks:  make(map[string]*accountLock),
+	}
+}
+
+// Save stores the tokens the user just granted for the Google account email.
+// Google only sends a refresh token on first consent, so without one the
+// stored refresh token is kept.
+func (m *TokenManager) Save(ctx context.Context, userID, email string, token *oauth2.Token) (*models.YouTubeAccount, error) {
+	account := &models.YouTubeAccount{UserID: userID, Email: email, ExpiresAt: expiryPtr(token.Expiry)}
+	err := m.store.WithTx(ctx, func(st repository.Store) error {
+		refreshToken := token.RefreshToken
+		if refreshToken == "" {
+			existing, err := st.YouTubeAccounts().GetByEmail(ctx, userID, email)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			if existing != nil {
+				if _, refreshToken, err = m.keys.Open(existing); err != nil {
+					return err
+				}
+			}
+		}
+		if err := m.keys.Seal(account, token.AccessToken, refreshToken); err != nil {
+			return err
+		}
+		return st.YouTubeAccounts().Upsert(ctx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// TokenSource returns a cached, auto-refreshing token source for the account
+func (m *TokenManager) TokenSource(ctx context.Context, accountID string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &accountTokenSource{ctx: ctx, manager: m, accountID: accountID})
+}
+
+// Client returns an HTTP client authorised as the account
+func (m *TokenManager) Client(ctx context.Context, accountID string) *http.Client {
+	return oauth2.NewClient(ctx, m.TokenSource(ctx, accountID))
+}
+
+// YouTube returns a YouTube Data API client authorised as the account
+func (m *TokenManager) YouTube(ctx context.Context, accountID string) (*youtube.Service, error) {
+	svc, err := youtube.NewService(ctx, option.WithHTTPClient(m.Client(ctx, accountID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create YouTube service: %v", err)
+	}
+	return svc, nil
+}
+
+// lockAccount takes the account's refresh lock; the returned func releases
+// it and forgets the lock once nobody else is waiting for it
+func (m *TokenManager) lockAccount(accountID string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[accountID]
+	if !ok {
+		l = &accountLock{}
+		m.locks[accountID] = l
+	}
+	l.waiters++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if l.waiters--; l.waiters == 0 {
+			delete(m.locks, accountID)
+		}
+	}
+}
+
+// refresh returns the stored token if it is still valid, otherwise refreshes
+// it and persists the result.
+func (m *TokenManager) refresh(ctx context.Context, accountID string) (*oauth2.Token, error) {
+	// Most calls find a valid token; they need neither lock
+	if account, err := m.store.YouTubeAccounts().Get(ctx, accountID); err == nil {
+		if token, ok := m.validToken(account); ok {
+			return token, nil
+		}
+	}
+
+	unlock := m.lockAccount(accountID)
+	defer unlock()
+	refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	var token *oauth2.Token
+	err := m.store.WithTx(refreshCtx, func(st repository.Store) error {
+		// Lock the row so another server refreshing the same account waits for us
+		// and then sees the new token instead of spending the refresh token again.
+		account, err := st.YouTubeAccounts().GetForUpdate(refreshCtx, accountID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("youtube account %s not found", accountID)
+			}
+			return fmt.Errorf("failed to load token: %w", err)
+		}
+
+		// Someone else may have refreshed it while we waited for the lock
+		var ok bool
+		if token, ok = m.validToken(account); ok {
+			return nil
+		}
+		_, refreshToken, err := m.keys.Open(account)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt token: %w", err)
+		}
+		if refreshToken == "" {
+			return fmt.Errorf("youtube account %s has no refresh token", accountID)
+		}
+
+		fresh, err := m.config.TokenSource(refreshCtx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+		if err != nil {
+			return fmt.Errorf("failed to refresh token: %w", err)
+		}
+		if fresh.RefreshToken == "" {
+			fresh.RefreshToken = refreshToken
+		}
+
+		if err := m.keys.Seal(account, fresh.AccessToken, fresh.RefreshToken); err != nil {
+			return fmt.Errorf("failed to encrypt token: %w", err)
+		}
+		account.ExpiresAt = expiryPtr(fresh.Expiry)
+		if err := st.YouTubeAccounts().UpdateToken(refreshCtx, account); err != nil {
+			return fmt.Errorf("failed to save refreshed token: %w", err)
+		}
+		token = fresh
+		return nil
+	})
+	// Google revoked the grant or the owner removed our access; only a new
+	// consent brings the account back
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		if markErr := m.store.YouTubeAccounts().MarkReauthRequired(ctx, accountID); markErr != nil {
+			log.Printf("youtube account %s: failed to flag for reconnect: %v", accountID, markErr)
+		}
+		return nil, conflict("YouTube account access was revoked; reconnect the account")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// validToken returns the account's stored token if it is not about to
+// expire. Rows without expires_at predate expiry tracking, so they always
+// need a refresh.
+func (m *TokenManager) validToken(account *models.YouTubeAccount) (*oauth2.Token, bool) {
+	if account.ExpiresAt == nil || time.Until(*account.ExpiresAt) <= expiryLeeway {
+		return nil, false
+	}
+	accessToken, refreshToken, err := m.keys.Open(account)
+	if err != nil {
+		return nil, false
+	}
+	return &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		Expiry:       *account.ExpiresAt,
+	}, true
+}
+
+// Revoke withdraws the grant behind the account at Google. A token Google
+// no longer knows counts as revoked.
+func (m *TokenManager) Revoke(ctx context.Context, a *models.YouTubeAccount) error {
+	accessToken, refreshToken, err := m.keys.Open(a)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt token: %w", err)
+	}
+	// Revoking the refresh token also revokes the access tokens issued from it
+	token := refreshToken
+	if token == "" {
+		token = accessToken
+	}
+	if token == "" {
+		return nil
+	}
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleRevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := revokeClient.Do(req)
+	if err != nil {
+		return upstream("Failed to revoke access at Google", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return upstream("Failed to revoke access at Google", fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// accountTokenSource loads the account's token through its TokenManager
+type accountTokenSource struct {
+	ctx       context.Context
+	manager   *TokenManager
+	accountID string
+}
+
+func (s *accountTokenSource) Token() (*oauth2.Token, error) {
+	return s.manager.refresh(s.ctx, s.accountID)
+}
+
+// expiryPtr stores a zero expiry as unknown
+func expiryPtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+)
+
+func TestTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name        string
+		reply       func(w http.ResponseWriter, refreshToken string)
+		wantErr     error
+		wantRefresh string // refresh token stored afterwards
+	}{
+		{
+			name: "rotated refresh token",
+			reply: func(w http.ResponseWriter, refreshToken string) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "fresh", "refresh_token": "rotated", "token_type": "Bearer", "expires_in": 3600}`)
+			},
+			wantRefresh: "rotated",
+		},
+		{
+			name: "refresh token kept",
+			reply: func(w http.ResponseWriter, refreshToken string) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "fresh", "token_type": "Bearer", "expires_in": 3600}`)
+			},
+			wantRefresh: "original",
+		},
+		{name: "revoked grant", reply: revokedGrant, wantErr: ErrConflict, wantRefresh: "original"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			st := memory.NewStore()
+			keys := testKeyring(t)
+			_, cfg := newTokenEndpoint(t, tt.reply)
+			m := NewTokenManager(st, cfg, keys)
+			account := connectAccount(t, st, keys, "original")
+
+			token, err := m.TokenSource(ctx, account.ID).Token()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			saved, err := st.YouTubeAccounts().Get(ctx, account.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			access, refresh, err := keys.Open(saved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refresh != tt.wantRefresh {
+				t.Errorf("stored refresh token %q, want %q", refresh, tt.wantRefresh)
+			}
+			if tt.wantErr != nil {
+				if saved.ReauthRequiredAt == nil {
+					t.Error("account is not flagged for reconnect")
+				}
+				return
+			}
+
+			if token.AccessToken != "fresh" || access != "fresh" {
+				t.Errorf("access token %q, stored %q, want fresh", token.AccessToken, access)
+			}
+			if saved.ExpiresAt == nil || time.Until(*saved.ExpiresAt) < 59*time.Minute {
+				t.Errorf("stored expiry %v, want an hour from now", saved.ExpiresAt)
+			}
+			// The saved expiry lets the next caller skip the refresh
+			if _, ok := m.validToken(saved); !ok {
+				t.Error("the refreshed token is not valid")
+			}
+		})
+	}
+}
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewStore()
+	keys := testKeyring(t)
+	endpoint, cfg := newTokenEndpoint(t, func(w http.ResponseWriter, refreshToken string) {
+		// Slow enough for every caller to find the token expired
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "fresh", "refresh_token": "rotated", "token_type": "Bearer", "expires_in": 3600}`)
+	})
+	m := NewTokenManager(st, cfg, keys)
+	account := connectAccount(t, st, keys, "original")
+
+	const callers = 10
+	var wg sync.WaitGroup
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := m.TokenSource(ctx, account.ID).Token()
+			if err == nil {
+				tokens[i] = token.AccessToken
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i := range callers {
+		if errs[i] != nil || tokens[i] != "fresh" {
+			t.Errorf("caller %d: token %q, err %v", i, tokens[i], errs[i])
+		}
+	}
+	// A second refresh would spend the refresh token Google just rotated
+	if got := endpoint.calls(); len(got) != 1 {
+		t.Errorf("refreshed %d times with %q, want once", len(got), got)
+	}
+	if len(m.locks) != 0 {
+		t.Errorf("%d account locks left behind", len(m.locks))
+	}
+}
@@ -0,0 +1,531 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"google.golang.org/api/youtube/v3"
+)
+
+// JobReconcileVideos compares every published video with its stored copy
+const JobReconcileVideos = "videos.reconcile"
+
+// maxVideosPerCall is how many IDs Videos.List accepts at once
+const maxVideosPerCall = 50
+
+// YouTube limits on a video's snippet
+const (
+	maxTitleLength       = 100
+	maxDescriptionLength = 5000
+)
+
+// VideoFields are the parts of a published video edited on both sides
+type VideoFields struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// VideoConflict is a change made on YouTube that clashes with a change made
+// on the dashboard since the two last agreed. Neither side is overwritten
+// until the owner picks one.
+type VideoConflict struct {
+	Fields     []string    `json:"fields"` // title, description and/or tags
+	YouTube    VideoFields `json:"youtube"`
+	Dashboard  VideoFields `json:"dashboard"`
+	DetectedAt time.Time   `json:"detected_at"`
+}
+
+// VideoSync is a published project's dashboard values next to the copy of
+// its video last agreed with YouTube
+type VideoSync struct {
+	Dashboard VideoFields          `json:"dashboard"`
+	YouTube   *models.YouTubeVideo `json:"youtube,omitempty"` // nil until first synced
+	Conflict  *VideoConflict       `json:"conflict,omitempty"`
+}
+
+// VideoEdit changes a published video; nil fields stay as they are
+type VideoEdit struct {
+	Title       *string
+	Description *string
+	Tags        *[]string
+	// Force overwrites changes made on YouTube instead of reporting a conflict
+	Force bool
+}
+
+// RunVideoReconcile queues a reconcile of every published video now and
+// then every interval until ctx is done. The job's key keeps servers doing
+// this at the same time from queueing it twice.
+func (s *ProjectService) RunVideoReconcile(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := enqueueJob(ctx, s.store, JobReconcileVideos, JobReconcileVideos, struct{}{}, time.Now())
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			log.Printf("video reconcile: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// VideoSync returns the project's sync state with YouTube
+func (s *ProjectService) VideoSync(ctx context.Context, projectID string) (*VideoSync, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	m, err := loadMetadata(ctx, s.store, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	video, err := s.store.YouTubeVideos().Get(ctx, p.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to fetch video: %w", err)
+	}
+	return newVideoSync(p, m, video), nil
+}
+
+// ReconcileVideos compares every published video with its stored copy,
+// taking over changes made on YouTube and recording conflicts
+func (s *ProjectService) ReconcileVideos(ctx context.Context) error {
+	projects, err := s.store.Projects().ListPublished(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list published projects: %w", err)
+	}
+
+	byChannel := make(map[string][]models.Project)
+	for _, p := range projects {
+		byChannel[p.ChannelID] = append(byChannel[p.ChannelID], p)
+	}
+	for channelID, ps := range byChannel {
+		ytService, _, err := s.channelYouTube(ctx, channelID)
+		if err != nil {
+			log.Printf("video reconcile for channel %s: %v", channelID, err)
+			continue
+		}
+		for start := 0; start < len(ps); start += maxVideosPerCall {
+			batch := ps[start:min(start+maxVideosPerCall, len(ps))]
+			ids := make([]string, len(batch))
+			for i, p := range batch {
+				ids[i] = p.YouTubeID
+			}
+			resp, err := ytService.Videos.List([]string{"snippet", "status"}).Id(ids...).Context(ctx).Do()
+			if err != nil {
+				log.Printf("video reconcile for channel %s: %v", channelID, err)
+				continue
+			}
+			for _, item := range resp.Items {
+				i := slices.IndexFunc(batch, func(p models.Project) bool { return p.YouTubeID == item.Id })
+				if i < 0 {
+					continue
+				}
+				if err := s.reconcileVideo(ctx, batch[i].ID, item); err != nil {
+					log.Printf("video reconcile for project %s: %v", batch[i].ID, err)
+				}
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// reconcileVideo merges the video as YouTube has it into the project
+func (s *ProjectService) reconcileVideo(ctx context.Context, projectID string, remote *youtube.Video) error {
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		p, m, stored, err := lockVideo(ctx, st, projectID)
+		if err != nil {
+			return err
+		}
+		if p.YouTubeID != remote.Id {
+			return nil
+		}
+
+		local, theirs := localVideoFields(p, m), remoteVideoFields(remote)
+		// Never synced: YouTube is where the video lives, so it wins
+		base := local
+		if stored != nil {
+			base = storedVideoFields(stored)
+		}
+		merged, conflicts := mergeVideoFields(base, local, theirs)
+		if len(conflicts) > 0 {
+			return recordVideoConflict(ctx, st, stored, conflicts, local, theirs)
+		}
+
+		if err := saveLocalVideoFields(ctx, st, p, m, merged); err != nil {
+			return err
+		}
+		snapshot := newVideoSnapshot(p.ID, remote)
+		if !merged.equal(theirs) {
+			// Dashboard changes YouTube does not have yet stay pending
+			snapshot.Title, snapshot.Description, snapshot.Tags = base.Title, base.Description, base.Tags
+		}
+		return st.YouTubeVideos().Save(ctx, snapshot)
+	})
+}
+
+// PushVideo applies the edit to the project and its video on YouTube.
+// Fields changed on YouTube since the last sync are kept when the edit
+// leaves them alone; when both sides changed a field the conflict is
+// recorded and returned unless edit.Force is set.
+func (s *ProjectService) PushVideo(ctx context.Context, access *ProjectAccess, edit VideoEdit) (*VideoSync, error) {
+	if access.ReadOnly {
+		return nil, ErrProjectReadOnly
+	}
+	p, err := s.store.Projects().Get(ctx, access.ProjectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if p.YouTubeID == "" {
+		return nil, conflict("Project is not on YouTube yet")
+	}
+	if edit.Tags != nil {
+		tags, err := normalizeTags(*edit.Tags)
+		if err != nil {
+			return nil, err
+		}
+		edit.Tags = &tags
+	}
+	if edit.Title != nil {
+		title := strings.TrimSpace(*edit.Title)
+		if title == "" || len([]rune(title)) > maxTitleLength || strings.ContainsAny(title, "<>") {
+			return nil, invalidf("title must be 1 to %d characters without < or >", maxTitleLength)
+		}
+		edit.Title = &title
+	}
+	if edit.Description != nil && (len(*edit.Description) > maxDescriptionLength || strings.ContainsAny(*edit.Description, "<>")) {
+		return nil, invalidf("description must be at most %d bytes without < or >", maxDescriptionLength)
+	}
+
+	ytService, _, err := s.channelYouTube(ctx, p.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := fetchVideo(ctx, ytService, p.YouTubeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The merge is worked out under the lock, but YouTube is only called once
+	// it is released; the result is saved if nothing changed meanwhile
+	var result *VideoSync
+	var clash []string
+	var local, merged VideoFields
+	var base *models.YouTubeVideo
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		p, m, stored, err := lockVideo(ctx, st, access.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		var theirs VideoFields
+		local, theirs, base = localVideoFields(p, m), remoteVideoFields(remote), stored
+		edited := local
+		if edit.Title != nil {
+			edited.Title = *edit.Title
+		}
+		if edit.Description != nil {
+			edited.Description = *edit.Description
+		}
+		if edit.Tags != nil {
+			edited.Tags = *edit.Tags
+		}
+
+		// Never synced: the dashboard has nothing YouTube could clash with
+		baseFields := theirs
+		if stored != nil {
+			baseFields = storedVideoFields(stored)
+		}
+		var conflicts []string
+		merged, conflicts = mergeVideoFields(baseFields, edited, theirs)
+		if len(conflicts) > 0 && !edit.Force {
+			clash = conflicts
+			if err := recordVideoConflict(ctx, st, stored, conflicts, edited, theirs); err != nil {
+				return err
+			}
+			stored, err = st.YouTubeVideos().Get(ctx, p.ID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			result = newVideoSync(p, m, stored)
+			return nil
+		}
+		if edit.Force {
+			merged = edited
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(clash) > 0 {
+		return result, conflict("Changed on YouTube too: " + strings.Join(clash, ", "))
+	}
+
+	// Only the snippet is sent; it replaces the whole snippet on YouTube
+	snippet := &youtube.VideoSnippet{
+		Title:           merged.Title,
+		Description:     merged.Description,
+		Tags:            merged.Tags,
+		CategoryId:      remote.Snippet.CategoryId,
+		DefaultLanguage: remote.Snippet.DefaultLanguage,
+	}
+	updated, err := ytService.Videos.Update([]string{"snippet"}, &youtube.Video{Id: p.YouTubeID, Snippet: snippet}).Context(ctx).Do()
+	if err != nil {
+		return nil, upstream("Failed to update the video on YouTube", err)
+	}
+	updated.Status = remote.Status
+
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		p, m, stored, err := lockVideo(ctx, st, access.ProjectID)
+		if err != nil {
+			return err
+		}
+		// Leave a concurrent edit or sync alone; the next reconcile merges
+		// what YouTube now has
+		if !localVideoFields(p, m).equal(local) || !sameVideoSnapshot(stored, base) {
+			return conflict("The video changed while updating YouTube; reload and try again")
+		}
+		if err := saveLocalVideoFields(ctx, st, p, m, merged); err != nil {
+			return err
+		}
+		snapshot := newVideoSnapshot(p.ID, updated)
+		if err := st.YouTubeVideos().Save(ctx, snapshot); err != nil {
+			return err
+		}
+		result = newVideoSync(p, m, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sameVideoSnapshot reports whether the stored copy of a video is still the
+// one read earlier
+func sameVideoSnapshot(a, b *models.YouTubeVideo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ETag == b.ETag && a.SyncedAt.Equal(b.SyncedAt) && bytes.Equal(a.Conflict, b.Conflict)
+}
+
+// PullVideo takes over the video as YouTube has it, resolving a conflict in
+// YouTube's favour
+func (s *ProjectService) PullVideo(ctx context.Context, projectID string) (*VideoSync, error) {
+	p, err := s.store.Projects().Get(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if p.YouTubeID == "" {
+		return nil, conflict("Project is not on YouTube yet")
+	}
+	ytService, _, err := s.channelYouTube(ctx, p.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := fetchVideo(ctx, ytService, p.YouTubeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *VideoSync
+	err = s.store.WithTx(ctx, func(st repository.Store) error {
+		p, m, _, err := lockVideo(ctx, st, projectID)
+		if err != nil {
+			return err
+		}
+		if err := saveLocalVideoFields(ctx, st, p, m, remoteVideoFields(remote)); err != nil {
+			return err
+		}
+		snapshot := newVideoSnapshot(p.ID, remote)
+		if err := st.YouTubeVideos().Save(ctx, snapshot); err != nil {
+			return err
+		}
+		result = newVideoSync(p, m, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ProjectService) runReconcileVideos(ctx context.Context, job *models.Job) error {
+	return s.ReconcileVideos(ctx)
+}
+
+// fetchVideo returns the video's snippet and status as YouTube has them
+func fetchVideo(ctx context.Context, ytService *youtube.Service, videoID string) (*youtube.Video, error) {
+	resp, err := ytService.Videos.List([]string{"snippet", "status"}).Id(videoID).Context(ctx).Do()
+	if err != nil {
+		return nil, upstream("Failed to fetch the video from YouTube", err)
+	}
+	if len(resp.Items) == 0 || resp.Items[0].Snippet == nil {
+		return nil, conflict("Video no longer exists on YouTube")
+	}
+	return resp.Items[0], nil
+}
+
+// lockVideo loads the project, locked, with its metadata and stored video
+// copy, which is nil if it was never synced
+func lockVideo(ctx context.Context, st repository.Store, projectID string) (*models.Project, *models.ProjectMetadata, *models.YouTubeVideo, error) {
+	p, err := st.Projects().GetForUpdate(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil, ErrProjectNotFound
+		}
+		return nil, nil, nil, err
+	}
+	m, err := loadMetadata(ctx, st, p.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stored, err := st.YouTubeVideos().Get(ctx, p.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return p, m, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return p, m, stored, nil
+}
+
+// saveLocalVideoFields writes the fields back to the project and its metadata
+func saveLocalVideoFields(ctx context.Context, st repository.Store, p *models.Project, m *models.ProjectMetadata, f VideoFields) error {
+	if p.Title != f.Title || p.Description != f.Description {
+		p.Title, p.Description = f.Title, f.Description
+		p.UpdatedAt = time.Now()
+		if err := st.Projects().Update(ctx, p); err != nil {
+			return err
+		}
+	}
+	if !slices.Equal(m.Tags, f.Tags) {
+		m.Tags = f.Tags
+		m.UpdatedAt = time.Now()
+		if err := st.Projects().SaveMetadata(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordVideoConflict stores the conflict on the video's copy, keeping the
+// values both sides last agreed on
+func recordVideoConflict(ctx context.Context, st repository.Store, stored *models.YouTubeVideo, fields []string, dashboard, theirs VideoFields) error {
+	if stored == nil {
+		return nil
+	}
+	data, err := json.Marshal(VideoConflict{Fields: fields, YouTube: theirs, Dashboard: dashboard, DetectedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	stored.Conflict = data
+	stored.SyncedAt = time.Now()
+	return st.YouTubeVideos().Save(ctx, stored)
+}
+
+// mergeVideoFields is a three-way merge per field: a field only one side
+// changed since base takes that side's value, and fields both sides changed
+// differently are conflicts that keep local's value
+func mergeVideoFields(base, local, remote VideoFields) (VideoFields, []string) {
+	var merged VideoFields
+	var conflicts []string
+	merged.Title = mergeField("title", base.Title, local.Title, remote.Title, &conflicts)
+	merged.Description = mergeField("description", base.Description, local.Description, remote.Description, &conflicts)
+	merged.Tags = mergeTags(base.Tags, local.Tags, remote.Tags, &conflicts)
+	return merged, conflicts
+}
+
+func mergeField(name, base, local, remote string, conflicts *[]string) string {
+	switch {
+	case remote == base:
+		return local
+	case local == base || local == remote:
+		return remote
+	}
+	*conflicts = append(*conflicts, name)
+	return local
+}
+
+func mergeTags(base, local, remote []string, conflicts *[]string) []string {
+	switch {
+	case slices.Equal(remote, base):
+		return local
+	case slices.Equal(local, base) || slices.Equal(local, remote):
+		return remote
+	}
+	*conflicts = append(*conflicts, "tags")
+	return local
+}
+
+func (f VideoFields) equal(other VideoFields) bool {
+	return f.Title == other.Title && f.Description == other.Description && slices.Equal(f.Tags, other.Tags)
+}
+
+func localVideoFields(p *models.Project, m *models.ProjectMetadata) VideoFields {
+	return VideoFields{Title: p.Title, Description: p.Description, Tags: m.Tags}
+}
+
+func storedVideoFields(v *models.YouTubeVideo) VideoFields {
+	return VideoFields{Title: v.Title, Description: v.Description, Tags: v.Tags}
+}
+
+func remoteVideoFields(v *youtube.Video) VideoFields {
+	tags := v.Snippet.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return VideoFields{Title: v.Snippet.Title, Description: v.Snippet.Description, Tags: tags}
+}
+
+// newVideoSnapshot copies the video as YouTube returned it
+func newVideoSnapshot(projectID string, v *youtube.Video) *models.YouTubeVideo {
+	f := remoteVideoFields(v)
+	snapshot := &models.YouTubeVideo{
+		ProjectID:   projectID,
+		YouTubeID:   v.Id,
+		Title:       f.Title,
+		Description: f.Description,
+		Tags:        f.Tags,
+		CategoryID:  v.Snippet.CategoryId,
+		ETag:        v.Etag,
+		SyncedAt:    time.Now(),
+	}
+	if v.Status != nil {
+		snapshot.PrivacyStatus = v.Status.PrivacyStatus
+	}
+	return snapshot
+}
+
+func newVideoSync(p *models.Project, m *models.ProjectMetadata, v *models.YouTubeVideo) *VideoSync {
+	sync := &VideoSync{Dashboard: localVideoFields(p, m), YouTube: v}
+	if v != nil && len(v.Conflict) > 0 {
+		var c VideoConflict
+		if err := json.Unmarshal(v.Conflict, &c); err == nil {
+			sync.Conflict = &c
+		}
+	}
+	return sync
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Roles a member can have in a workspace
+const (
+	WorkspaceAdmin  = "admin"  // manages members; sees every channel and project
+	WorkspaceOwner  = "owner"  // brings their channels into the workspace
+	WorkspaceEditor = "editor" // works on the channels they are assigned to
+	WorkspaceViewer = "viewer" // sees every channel and project, read-only
+)
+
+// ErrWorkspaceNotFound is returned for workspaces the caller is not a member of
+var ErrWorkspaceNotFound = &Error{Kind: ErrNotFound, Message: "Workspace not found"}
+
+// Workspace is one of the caller's workspaces and their role in it
+type Workspace struct {
+	models.Workspace
+	Role string `json:"role"`
+}
+
+// WorkspaceMember is a member as listed to the rest of the workspace
+type WorkspaceMember struct {
+	UserID   string    `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// WorkspaceService manages workspaces and their members
+type WorkspaceService struct {
+	store repository.Store
+}
+
+// NewWorkspaceService creates a WorkspaceService on top of store
+func NewWorkspaceService(store repository.Store) *WorkspaceService {
+	return &WorkspaceService{store: store}
+}
+
+// Create starts a workspace administered by the user
+func (s *WorkspaceService) Create(ctx context.Context, userID, name string) (*Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalidf("name is required")
+	}
+	var w *models.Workspace
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		var err error
+		w, err = createWorkspace(ctx, st, userID, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Workspace: *w, Role: WorkspaceAdmin}, nil
+}
+
+// List returns the user's workspaces, the one they joined first first
+func (s *WorkspaceService) List(ctx context.Context, userID string) ([]Workspace, error) {
+	memberships, err := s.store.Workspaces().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspaces: %w", err)
+	}
+	workspaces := make([]Workspace, len(memberships))
+	for i, m := range memberships {
+		workspaces[i] = Workspace{Workspace: m.Workspace, Role: m.Role}
+	}
+	return workspaces, nil
+}
+
+// Active returns the user's membership of the workspace they are working in:
+// workspaceID, or the first one they joined when it is empty. Users that
+// belong to no workspace get nil.
+func (s *WorkspaceService) Active(ctx context.Context, userID, workspaceID string) (*models.WorkspaceMember, error) {
+	if workspaceID == "" {
+		memberships, err := s.store.Workspaces().ListByUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workspaces: %w", err)
+		}
+		if len(memberships) == 0 {
+			return nil, nil
+		}
+		workspaceID = memberships[0].ID
+	}
+	return workspaceMember(ctx, s.store, workspaceID, userID)
+}
+
+// Members lists the workspace's members to any of them
+func (s *WorkspaceService) Members(ctx context.Context, workspaceID, userID string) ([]WorkspaceMember, error) {
+	if _, err := workspaceMember(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	stored, err := s.store.Workspaces().ListMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	members := make([]WorkspaceMember, len(stored))
+	for i, m := range stored {
+		members[i] = newWorkspaceMember(m)
+	}
+	return members, nil
+}
+
+// AddMember lets an admin add an existing user, by email, to the workspace.
+// Editors who do not have an account yet are invited to channels instead.
+func (s *WorkspaceService) AddMember(ctx context.Context, workspaceID, actorID, email, role string) (*WorkspaceMember, error) {
+	if !validWorkspaceRole(role) {
+		return nil, invalidf("role must be admin, owner, editor or viewer")
+	}
+	var member *WorkspaceMember
+	err := s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := requireWorkspaceAdmin(ctx, st, workspaceID, actorID); err != nil {
+			return err
+		}
+		user, err := st.Users().GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("No user with this email; invite them to a channel instead")
+		}
+		if err != nil {
+			return err
+		}
+
+		m := &models.WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, Role: role, CreatedAt: time.Now()}
+		added, err := st.Workspaces().AddMember(ctx, m)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return conflict("User is already a member of this workspace")
+		}
+		member = &WorkspaceMember{UserID: user.ID, Name: user.Name, Email: user.Email, Role: role, JoinedAt: m.CreatedAt}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// SetRole lets an admin change a member's role. The workspace always keeps
+// at least one admin.
+func (s *WorkspaceService) SetRole(ctx context.Context, workspaceID, actorID, userID, role string) error {
+	if !validWorkspaceRole(role) {
+		return invalidf("role must be admin, owner, editor or viewer")
+	}
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		if err := requireWorkspaceAdmin(ctx, st, workspaceID, actorID); err != nil {
+			return err
+		}
+		m, err := st.Workspaces().GetMember(ctx, workspaceID, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Member not found")
+		}
+		if err != nil {
+			return err
+		}
+		if m.Role == WorkspaceAdmin && role != WorkspaceAdmin {
+			if err := keepAnAdmin(ctx, st, workspaceID); err != nil {
+				return err
+			}
+		}
+		return st.Workspaces().SetMemberRole(ctx, workspaceID, userID, role)
+	})
+}
+
+// RemoveMember lets an admin remove a member, or a member leave. They are
+// also taken off the workspace's channels they were assigned to.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID, actorID, userID string) error {
+	return s.store.WithTx(ctx, func(st repository.Store) error {
+		if actorID != userID {
+			if err := requireWorkspaceAdmin(ctx, st, workspaceID, actorID); err != nil {
+				return err
+			}
+		}
+		m, err := st.Workspaces().GetMember(ctx, workspaceID, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			if actorID == userID {
+				return ErrWorkspaceNotFound
+			}
+			return notFound("Member not found")
+		}
+		if err != nil {
+			return err
+		}
+		if m.Role == WorkspaceAdmin {
+			if err := keepAnAdmin(ctx, st, workspaceID); err != nil {
+				return err
+			}
+		}
+		if err := st.Workspaces().RemoveMember(ctx, workspaceID, userID); err != nil {
+			return err
+		}
+
+		channels, err := st.Channels().ListByWorkspace(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+		for _, ch := range channels {
+			err := st.Channels().RemoveEditor(ctx, ch.ID, userID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := recordMembershipEvent(ctx, st, actorID, "editor_removed", "", userID, ch.ID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createWorkspace creates a workspace inside st's transaction with userID as
+// its admin
+func createWorkspace(ctx context.Context, st repository.Store, userID, name string) (*models.Workspace, error) {
+	now := time.Now()
+	w := &models.Workspace{ID: uuid.New().String(), Name: name, CreatedBy: userID, CreatedAt: now}
+	if err := st.Workspaces().Create(ctx, w); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	_, err := st.Workspaces().AddMember(ctx, &models.WorkspaceMember{
+		WorkspaceID: w.ID,
+		UserID:      userID,
+		Role:        WorkspaceAdmin,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add workspace admin: %w", err)
+	}
+	return w, nil
+}
+
+// workspaceMember returns the user's membership, reporting workspaces they
+// are not in as not found
+func workspaceMember(ctx context.Context, st repository.Store, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	m, err := st.Workspaces().GetMember(ctx, workspaceID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace membership: %w", err)
+	}
+	return m, nil
+}
+
+func requireWorkspaceAdmin(ctx context.Context, st repository.Store, workspaceID, userID string) error {
+	m, err := workspaceMember(ctx, st, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if m.Role != WorkspaceAdmin {
+		return forbidden("Only workspace admins can manage members")
+	}
+	return nil
+}
+
+// keepAnAdmin refuses to take away the workspace's last admin
+func keepAnAdmin(ctx context.Context, st repository.Store, workspaceID string) error {
+	members, err := st.Workspaces().ListMembers(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	admins := 0
+	for _, m := range members {
+		if m.Role == WorkspaceAdmin {
+			admins++
+		}
+	}
+	if admins <= 1 {
+		return conflict("A workspace needs at least one admin")
+	}
+	return nil
+}
+
+// seesWholeWorkspace reports whether the role sees every channel and project
+// of the workspace rather than only those the user works on
+func seesWholeWorkspace(m *models.WorkspaceMember) bool {
+	return m != nil && (m.Role == WorkspaceAdmin || m.Role == WorkspaceViewer)
+}
+
+func validWorkspaceRole(role string) bool {
+	switch role {
+	case WorkspaceAdmin, WorkspaceOwner, WorkspaceEditor, WorkspaceViewer:
+		return true
+	}
+	return false
+}
+
+func newWorkspaceMember(m repository.WorkspaceMemberDetails) WorkspaceMember {
+	return WorkspaceMember{UserID: m.UserID, Name: m.Name, Email: m.Email, Role: m.Role, JoinedAt: m.CreatedAt}
+}
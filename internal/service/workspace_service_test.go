@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhishek-sengar/ytmanager/internal/models"
+	"github.com/abhishek-sengar/ytmanager/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+func TestWorkspaceKeepsAnAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		secondAdmin bool // whether bob is an admin too
+		act         func(s *WorkspaceService, workspaceID, alice, bob string) error
+		want        error
+	}{
+		{name: "last admin demoted", act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.SetRole(context.Background(), ws, alice, alice, WorkspaceOwner)
+		}, want: ErrConflict},
+		{name: "last admin leaves", act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.RemoveMember(context.Background(), ws, alice, alice)
+		}, want: ErrConflict},
+		{name: "last admin kept as admin", act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.SetRole(context.Background(), ws, alice, alice, WorkspaceAdmin)
+		}},
+		{name: "one of two admins demoted", secondAdmin: true, act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.SetRole(context.Background(), ws, bob, alice, WorkspaceViewer)
+		}},
+		{name: "one of two admins removed", secondAdmin: true, act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.RemoveMember(context.Background(), ws, bob, alice)
+		}},
+		{name: "non-admin demotes the admin", act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.SetRole(context.Background(), ws, bob, alice, WorkspaceEditor)
+		}, want: ErrForbidden},
+		{name: "non-admin removes the admin", act: func(s *WorkspaceService, ws, alice, bob string) error {
+			return s.RemoveMember(context.Background(), ws, bob, alice)
+		}, want: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			st := memory.NewStore()
+			s := NewWorkspaceService(st)
+			var ids []string
+			for _, name := range []string{"alice", "bob"} {
+				u := &models.User{ID: uuid.New().String(), Name: name, Email: name + "@example.com", Role: RoleOwner, CreatedAt: time.Now()}
+				if err := st.Users().Create(ctx, u); err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, u.ID)
+			}
+			alice, bob := ids[0], ids[1]
+			ws, err := s.Create(ctx, alice, "Studio")
+			if err != nil {
+				t.Fatal(err)
+			}
+			role := WorkspaceEditor
+			if tt.secondAdmin {
+				role = WorkspaceAdmin
+			}
+			if _, err := s.AddMember(ctx, ws.ID, alice, "bob@example.com", role); err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.act(s, ws.ID, alice, bob)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+
+			admins := 0
+			members, err := s.Members(ctx, ws.ID, bob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range members {
+				if m.Role == WorkspaceAdmin {
+					admins++
+				}
+			}
+			if admins == 0 {
+				t.Error("the workspace has no admin left")
+			}
+		})
+	}
+}
@@ -1 +1,228 @@
 package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// DefaultYouTubeUploadBase is the upload endpoint used by videos.insert
+const DefaultYouTubeUploadBase = "https://www.googleapis.com/upload/youtube/v3"
+
+// DefaultUploadChunkSize is the size of each PUT. YouTube requires chunks to be
+// a multiple of 256 KiB except for the last one.
+const DefaultUploadChunkSize = 32 * 256 * 1024
+
+// ErrUploadSessionExpired is returned when YouTube no longer knows the session
+// URI, so the upload has to be started again from scratch.
+var ErrUploadSessionExpired = errors.New("youtube upload session expired")
+
+// RangeOpener opens the video for reading starting at the given byte offset
+type RangeOpener func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// ResumableUploader pushes a video to YouTube using the resumable upload protocol.
+// The session URI returned by Start can be stored and passed to Upload again
+// after a failure to continue from the last byte YouTube acknowledged.
+type ResumableUploader struct {
+	Client     *http.Client
+	BaseURL    string
+	ChunkSize  int64
+	MaxRetries int
+}
+
+// NewResumableUploader creates an uploader. client must already carry the
+// channel's OAuth credentials. An empty baseURL uses the real YouTube endpoint.
+func NewResumableUploader(client *http.Client, baseURL string) *ResumableUploader {
+	if baseURL == "" {
+		baseURL = DefaultYouTubeUploadBase
+	}
+	return &ResumableUploader{
+		Client:     client,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		ChunkSize:  DefaultUploadChunkSize,
+		MaxRetries: 3,
+	}
+}
+
+// Start opens a new upload session for video and returns its session URI
+func (u *ResumableUploader) Start(ctx context.Context, video *youtube.Video, size int64, contentType string) (string, error) {
+	body, err := json.Marshal(video)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode video metadata: %w", err)
+	}
+
+	endpoint := u.BaseURL + "/videos?uploadType=resumable&part=snippet,status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	if contentType != "" {
+		req.Header.Set("X-Upload-Content-Type", contentType)
+	}
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to start upload session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to start upload session: %w", err)
+	}
+
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		return "", errors.New("upload session response has no Location header")
+	}
+	return sessionURL, nil
+}
+
+// Offset asks YouTube how many bytes of the session it has received. If the
+// upload is already complete the resulting video is returned instead.
+func (u *ResumableUploader) Offset(ctx context.Context, sessionURL string, size int64) (int64, *youtube.Video, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query upload status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return u.handleResponse(resp)
+}
+
+// Upload sends the remaining bytes of the session in chunks. Transient failures
+// are retried from the last acknowledged offset up to MaxRetries times.
+func (u *ResumableUploader) Upload(ctx context.Context, sessionURL string, open RangeOpener, size int64) (*youtube.Video, error) {
+	var lastErr error
+	for attempt := 0; attempt <= u.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(1<<(attempt-1)) * time.Second):
+			}
+		}
+
+		video, err := u.uploadFrom(ctx, sessionURL, open, size)
+		if err == nil {
+			return video, nil
+		}
+		if !isRetryable(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("upload failed after %d retries: %w", u.MaxRetries, lastErr)
+}
+
+func (u *ResumableUploader) uploadFrom(ctx context.Context, sessionURL string, open RangeOpener, size int64) (*youtube.Video, error) {
+	offset, video, err := u.Offset(ctx, sessionURL, size)
+	if err != nil || video != nil {
+		return video, err
+	}
+
+	r, err := open(ctx, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open video: %w", err)
+	}
+	defer func() { r.Close() }()
+
+	buf := make([]byte, u.ChunkSize)
+	for offset < size {
+		n := min(u.ChunkSize, size-offset)
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			return nil, fmt.Errorf("failed to read video: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, bytes.NewReader(buf[:n]))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = n
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+
+		resp, err := u.Client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload chunk: %w", err)
+		}
+		next, video, err := u.handleResponse(resp)
+		resp.Body.Close()
+		if err != nil || video != nil {
+			return video, err
+		}
+
+		// YouTube may keep fewer bytes than we sent; reopen at its offset
+		if next != offset+n {
+			reopened, err := open(ctx, next)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open video: %w", err)
+			}
+			r.Close()
+			r = reopened
+		}
+		offset = next
+	}
+
+	return nil, errors.New("upload finished without a video resource")
+}
+
+// handleResponse interprets a reply on the session URI: 308 means more bytes
+// are expected, 200/201 carries the finished video.
+func (u *ResumableUploader) handleResponse(resp *http.Response) (int64, *youtube.Video, error) {
+	switch {
+	case resp.StatusCode == http.StatusPermanentRedirect:
+		return parseRangeHeader(resp.Header.Get("Range")), nil, nil
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		var video youtube.Video
+		if err := json.NewDecoder(resp.Body).Decode(&video); err != nil {
+			return 0, nil, fmt.Errorf("failed to decode uploaded video: %w", err)
+		}
+		return 0, &video, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return 0, nil, ErrUploadSessionExpired
+	default:
+		return 0, nil, googleapi.CheckResponse(resp)
+	}
+}
+
+// parseRangeHeader turns "bytes=0-1234" into the next offset (1235)
+func parseRangeHeader(h string) int64 {
+	_, last, ok := strings.Cut(strings.TrimPrefix(h, "bytes="), "-")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n + 1
+}
+
+func isRetryable(err error) bool {
+	if errors.Is(err, ErrUploadSessionExpired) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500 || apiErr.Code == http.StatusTooManyRequests
+	}
+	// Network and read errors are worth another try
+	return true
+}
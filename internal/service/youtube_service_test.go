@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// fakeYouTube is a resumable upload endpoint keeping one session in memory
+type fakeYouTube struct {
+	t        *testing.T
+	size     int64
+	finish   int    // status of the final chunk, 200 or 201
+	dropNext int64  // bytes to drop from the next chunk, like a short 308
+	received []byte // bytes YouTube acknowledged so far
+
+	mu      sync.Mutex
+	started int
+	puts    int
+}
+
+func newFakeYouTube(t *testing.T, size int64) (*fakeYouTube, *httptest.Server) {
+	f := &fakeYouTube{t: t, size: size, finish: http.StatusCreated}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeYouTube) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/videos":
+		if r.URL.Query().Get("uploadType") != "resumable" {
+			f.t.Errorf("uploadType = %q, want resumable", r.URL.Query().Get("uploadType"))
+		}
+		if got := r.Header.Get("X-Upload-Content-Length"); got != fmt.Sprint(f.size) {
+			f.t.Errorf("X-Upload-Content-Length = %q, want %d", got, f.size)
+		}
+		f.started++
+		w.Header().Set("Location", "http://"+r.Host+"/session")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut && r.URL.Path == "/session":
+		f.put(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeYouTube) put(w http.ResponseWriter, r *http.Request) {
+	f.puts++
+	contentRange := r.Header.Get("Content-Range")
+	if contentRange == fmt.Sprintf("bytes */%d", f.size) {
+		f.reply(w)
+		return
+	}
+
+	var first, last, total int64
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &first, &last, &total); err != nil {
+		f.t.Errorf("bad Content-Range %q", contentRange)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if first != int64(len(f.received)) {
+		f.t.Errorf("chunk starts at %d, want %d", first, len(f.received))
+	}
+	body, _ := io.ReadAll(r.Body)
+	if int64(len(body)) != last-first+1 {
+		f.t.Errorf("chunk has %d bytes, Content-Range says %d", len(body), last-first+1)
+	}
+	keep := int64(len(body)) - f.dropNext
+	f.dropNext = 0
+	f.received = append(f.received, body[:keep]...)
+	f.reply(w)
+}
+
+// reply is a 308 with the acknowledged range, or the video once complete
+func (f *fakeYouTube) reply(w http.ResponseWriter) {
+	if int64(len(f.received)) == f.size {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.finish)
+		fmt.Fprint(w, `{"id": "video-1"}`)
+		return
+	}
+	if len(f.received) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(f.received)-1))
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+// opener serves video from memory and records the offsets it was opened at
+type opener struct {
+	video   []byte
+	offsets []int64
+	fail    func(call int) error
+}
+
+func (o *opener) open(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	o.offsets = append(o.offsets, offset)
+	if o.fail != nil {
+		if err := o.fail(len(o.offsets)); err != nil {
+			return nil, err
+		}
+	}
+	return io.NopCloser(bytes.NewReader(o.video[offset:])), nil
+}
+
+func testUploader(srv *httptest.Server) *ResumableUploader {
+	u := NewResumableUploader(srv.Client(), srv.URL)
+	u.ChunkSize = 10
+	u.MaxRetries = 0
+	return u
+}
+
+func TestResumableUploaderReopensAfterShortAck(t *testing.T) {
+	video := []byte(strings.Repeat("0123456789", 3) + "abcde")
+	f, srv := newFakeYouTube(t, int64(len(video)))
+	f.dropNext = 3 // YouTube keeps 7 of the first 10 bytes
+	u := testUploader(srv)
+	o := &opener{video: video}
+
+	sessionURL, err := u.Start(context.Background(), &youtube.Video{}, int64(len(video)), "video/mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := u.Upload(context.Background(), sessionURL, o.open, int64(len(video)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != "video-1" {
+		t.Errorf("video ID = %q, want video-1", got.Id)
+	}
+	if !bytes.Equal(f.received, video) {
+		t.Errorf("YouTube received %q, want %q", f.received, video)
+	}
+	if want := []int64{0, 7}; fmt.Sprint(o.offsets) != fmt.Sprint(want) {
+		t.Errorf("opened at %v, want %v", o.offsets, want)
+	}
+}
+
+func TestResumableUploaderResumesSession(t *testing.T) {
+	video := []byte(strings.Repeat("x", 25))
+	f, srv := newFakeYouTube(t, int64(len(video)))
+	f.finish = http.StatusOK
+	f.received = append(f.received, video[:12]...) // sent before a failure
+	u := testUploader(srv)
+	o := &opener{video: video}
+
+	got, err := u.Upload(context.Background(), srv.URL+"/session", o.open, int64(len(video)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != "video-1" {
+		t.Errorf("video ID = %q, want video-1", got.Id)
+	}
+	if want := []int64{12}; fmt.Sprint(o.offsets) != fmt.Sprint(want) {
+		t.Errorf("opened at %v, want %v", o.offsets, want)
+	}
+	if f.started != 0 {
+		t.Errorf("started %d new sessions, want 0", f.started)
+	}
+}
+
+func TestResumableUploaderFinishedSession(t *testing.T) {
+	video := []byte("done")
+	for _, status := range []int{http.StatusOK, http.StatusCreated} {
+		f, srv := newFakeYouTube(t, int64(len(video)))
+		f.finish = status
+		f.received = append(f.received, video...)
+		u := testUploader(srv)
+		o := &opener{video: video}
+
+		got, err := u.Upload(context.Background(), srv.URL+"/session", o.open, int64(len(video)))
+		if err != nil {
+			t.Fatalf("status %d: %v", status, err)
+		}
+		if got.Id != "video-1" {
+			t.Errorf("status %d: video ID = %q, want video-1", status, got.Id)
+		}
+		if len(o.offsets) != 0 {
+			t.Errorf("status %d: opened the video at %v, want not at all", status, o.offsets)
+		}
+		if f.puts != 1 {
+			t.Errorf("status %d: %d PUTs, want only the status query", status, f.puts)
+		}
+	}
+}
+
+func TestResumableUploaderReopenFailure(t *testing.T) {
+	video := []byte(strings.Repeat("y", 20))
+	f, srv := newFakeYouTube(t, int64(len(video)))
+	f.dropNext = 4
+	u := testUploader(srv)
+	errReopen := errors.New("storage unavailable")
+	o := &opener{video: video, fail: func(call int) error {
+		if call > 1 {
+			return errReopen
+		}
+		return nil
+	}}
+
+	_, err := u.Upload(context.Background(), srv.URL+"/session", o.open, int64(len(video)))
+	if !errors.Is(err, errReopen) {
+		t.Fatalf("err = %v, want %v", err, errReopen)
+	}
+}
+
+func TestResumableUploaderExpiredSession(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	u := testUploader(srv)
+	o := &opener{video: []byte("z")}
+
+	_, err := u.Upload(context.Background(), srv.URL+"/session", o.open, 1)
+	if !errors.Is(err, ErrUploadSessionExpired) {
+		t.Fatalf("err = %v, want ErrUploadSessionExpired", err)
+	}
+}